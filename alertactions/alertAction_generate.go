@@ -16,6 +16,25 @@ import (
 	"github.com/prigio/splunk-go-sdk/utils"
 )
 
+// UIFormat defines the style of the HTML UI generated for the alert action parameters
+type UIFormat int
+
+const (
+	// UIFormatClassic uses the <splunk-*> custom HTML elements, such as <splunk-text-input name="action.X.param.Y">, compatible with Splunk 8.x
+	UIFormatClassic UIFormat = iota
+	// UIFormatModern uses standard HTML <input> elements with data-param-name attributes, compatible with Splunk 9.x
+	UIFormatModern
+)
+
+// SetUIFormat configures the style of the HTML generated by --get-ui-html. The default is UIFormatClassic.
+func (aa *AlertAction) SetUIFormat(format UIFormat) error {
+	if format != UIFormatClassic && format != UIFormatModern {
+		return utils.NewErrInvalidParam("setUIFormat", nil, "'format' must be one of UIFormatClassic, UIFormatModern")
+	}
+	aa.uiFormat = format
+	return nil
+}
+
 func (aa *AlertAction) generateRuntimeConfig(filename string) string {
 	buf := new(strings.Builder)
 	var conf []byte
@@ -67,16 +86,20 @@ Documentation for this file is at:
 	- https://docs.splunk.com/Documentation/SplunkCloud/9.0.2305/AdvancedDev/CustomVizFormatterApiRef
 -->
 <form>
-<splunk-control-group label="Instructions">
-	<span class="help-block">
+`, aa.StanzaName)
+	instructions := `	<span class="help-block">
 		Values can contain tokens such as <code>$name$</code> and <code>$result.fieldname$</code>.<br/>
 		Read more <a target="_blank" href="https://docs.splunk.com/Documentation/Splunk/9.1.0/Alert/EmailNotificationTokens">here</a>.
 	</span>
-</splunk-control-group>
-`, aa.StanzaName)
+`
+	if aa.uiFormat == UIFormatModern {
+		fmt.Fprintf(buf, "<div class=\"control-group\">\n%s</div>\n", instructions)
+	} else {
+		fmt.Fprintf(buf, "<splunk-control-group label=\"Instructions\">\n%s</splunk-control-group>\n", instructions)
+	}
 
 	for _, par := range aa.params {
-		fmt.Fprintln(buf, par.getUIHTML(aa.StanzaName))
+		fmt.Fprintln(buf, par.getUIHTML(aa.StanzaName, aa.uiFormat))
 	}
 
 	fmt.Fprintln(buf, "</form>")
@@ -129,6 +152,9 @@ is_custom = 1
 # Change the command line arguments passed to the script when it is invoked. 
 # Provide additional arguments to the 'alert.execute.cmd'.
 #  Environment variables are substituted.
+# To verify a configuration without performing the actual alerting, e.g. while testing, the alert action supports dry-run mode:
+# the run-time configuration is parsed and validated, but the alerting function is not executed.
+# 			alert.execute.cmd.arg.2 = --dry-run
 
 # This alert action only supports JSON. Do not change this!
 payload_format = json
@@ -418,3 +444,71 @@ func (aa *AlertAction) generateAdHocConfigConfs() string {
 	}
 	return buf.String()
 }
+
+// GenerateOpenAPISpec returns an OpenAPI 3.0 JSON document describing the alert action as a POST endpoint,
+// whose JSON request body contains the parameters registered for the alert action.
+// Global parameters are not included, as they are configured by administrators within a configuration file.
+func (aa *AlertAction) GenerateOpenAPISpec() ([]byte, error) {
+	properties := make(map[string]interface{}, len(aa.params))
+	required := make([]string, 0)
+	for _, p := range aa.params {
+		prop := map[string]interface{}{
+			"type":  "string",
+			"title": p.Title,
+		}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		if p.defaultValue != "" && !p.sensitive {
+			prop["default"] = p.defaultValue
+		}
+		if choices := p.GetChoices(); len(choices) > 0 {
+			prop["enum"] = choices
+		}
+		if p.sensitive {
+			prop["format"] = "password"
+			prop["writeOnly"] = true
+		}
+		properties[p.Name] = prop
+		if p.required {
+			required = append(required, p.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       aa.Label,
+			"description": aa.Description,
+			"version":     "1.0.0",
+		},
+		"paths": map[string]interface{}{
+			"/" + aa.StanzaName: map[string]interface{}{
+				"post": map[string]interface{}{
+					"summary":     fmt.Sprintf("Execute alert action '%s'", aa.StanzaName),
+					"operationId": aa.StanzaName,
+					"requestBody": map[string]interface{}{
+						"required": true,
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": schema,
+							},
+						},
+					},
+					"responses": map[string]interface{}{
+						"200":     map[string]interface{}{"description": "Execution succeeded"},
+						"default": map[string]interface{}{"description": "Execution failed"},
+					},
+				},
+			},
+		},
+	}
+	return json.MarshalIndent(spec, "", "  ")
+}
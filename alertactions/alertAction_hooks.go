@@ -0,0 +1,106 @@
+package alertactions
+
+/*
+This file contains utility methods for the AlertAction struct to execute middleware hooks before and after the alerting function
+*/
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// panicError is returned by callAlertFunc when the alerting function panics
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("alerting function panicked: %v", e.value)
+}
+
+// UsePreHook registers f to be executed before the alerting function, e.g. to inject a request id or to write audit logs.
+// Pre-hooks are executed in registration order. If one of them fails, the following ones and the alerting function are skipped,
+// and the error is provided to the post-hooks.
+func (aa *AlertAction) UsePreHook(f AlertingFunc) {
+	if f != nil {
+		aa.preHooks = append(aa.preHooks, f)
+	}
+}
+
+// UsePostHook registers f to be executed after the alerting function, even if it failed.
+// Post-hooks are executed in reverse order of registration. The error of the execution is available with GetLastError:
+// the error returned by the hook replaces it, so that a hook can suppress it by returning nil.
+// Hooks not willing to modify the outcome of the execution should return aa.GetLastError().
+func (aa *AlertAction) UsePostHook(f AlertingFunc) {
+	if f != nil {
+		aa.postHooks = append(aa.postHooks, f)
+	}
+}
+
+// GetLastError returns the error of the execution of the alerting function, as possibly replaced by the post-hooks executed so far.
+func (aa *AlertAction) GetLastError() error {
+	return aa.lastError
+}
+
+// TimingHook returns a post-hook logging how long the execution took as duration_ms, from the first pre-hook on.
+func TimingHook() AlertingFunc {
+	return func(aa *AlertAction) error {
+		status := "succeeded"
+		if aa.GetLastError() != nil {
+			status = "failed"
+		}
+		aa.Log("INFO", "Alerting function status=%s duration_ms=%d", status, time.Since(aa.executionStart).Milliseconds())
+		return aa.GetLastError()
+	}
+}
+
+// PanicRecoveryHook returns a post-hook converting a panic of the alerting function into an error.
+// Without it, the panic is raised again once all the post-hooks have been executed.
+func PanicRecoveryHook() AlertingFunc {
+	return func(aa *AlertAction) error {
+		var pe *panicError
+		if !errors.As(aa.GetLastError(), &pe) {
+			return aa.GetLastError()
+		}
+		aa.Log("ERROR", "Recovered from panic of alerting function: %v\n%s", pe.value, pe.stack)
+		return fmt.Errorf("alerting function panicked: %v", pe.value)
+	}
+}
+
+// runAlertFuncWithHooks executes the pre-hooks, the alerting function and the post-hooks.
+// A panic of the alerting function not converted into an error by the post-hooks, e.g. by PanicRecoveryHook, is raised again.
+func (aa *AlertAction) runAlertFuncWithHooks(ctx context.Context) error {
+	aa.executionStart = time.Now()
+	aa.lastError = nil
+	for i, h := range aa.preHooks {
+		if err := h(aa); err != nil {
+			aa.lastError = fmt.Errorf("pre-hook %d failed: %w", i+1, err)
+			break
+		}
+	}
+	if aa.lastError == nil {
+		aa.lastError = aa.runAlertFuncWithTimeout(ctx)
+	}
+	for i := len(aa.postHooks) - 1; i >= 0; i-- {
+		aa.lastError = aa.postHooks[i](aa)
+	}
+	var pe *panicError
+	if errors.As(aa.lastError, &pe) {
+		panic(pe.value)
+	}
+	return aa.lastError
+}
+
+// callAlertFunc executes the alerting function, converting its panic into a panicError.
+// This allows the post-hooks to be executed: the panic is raised again by runAlertFuncWithHooks if not handled.
+func callAlertFunc(ctx context.Context, aa *AlertAction, execute AlertingFuncWithContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r, stack: debug.Stack()}
+		}
+	}()
+	return execute(aa, ctx)
+}
@@ -0,0 +1,121 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHooksOrder(t *testing.T) {
+	aa, _ := New("test-hooks", "Test hooks", "", "")
+	aa.stderr = new(strings.Builder)
+
+	var calls []string
+	record := func(name string) AlertingFunc {
+		return func(aa *AlertAction) error {
+			calls = append(calls, name)
+			return aa.GetLastError()
+		}
+	}
+	aa.UsePreHook(record("pre1"))
+	aa.UsePreHook(record("pre2"))
+	aa.UsePostHook(record("post1"))
+	aa.UsePostHook(record("post2"))
+	aa.RegisterAlertFunc(func(aa *AlertAction) error {
+		calls = append(calls, "execute")
+		return nil
+	})
+	if err := aa.runAlertFuncWithHooks(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if strings.Join(calls, ",") != "pre1,pre2,execute,post2,post1" {
+		t.Errorf("hooks executed in wrong order: %v", calls)
+	}
+
+	// a failing pre-hook skips the execution, post-hooks still run
+	calls = nil
+	preErr := errors.New("missing request id")
+	aa.UsePreHook(func(aa *AlertAction) error { return preErr })
+	aa.UsePreHook(record("pre4"))
+	if err := aa.runAlertFuncWithHooks(context.Background()); !errors.Is(err, preErr) {
+		t.Errorf("error of the pre-hook was not returned: %v", err)
+	}
+	if strings.Join(calls, ",") != "pre1,pre2,post2,post1" {
+		t.Errorf("wrong hooks executed after a pre-hook failure: %v", calls)
+	}
+}
+
+func TestPostHookErrors(t *testing.T) {
+	aa, _ := New("test-hooks", "Test hooks", "", "")
+	aa.stderr = new(strings.Builder)
+	execErr := errors.New("remote API unavailable")
+	aa.RegisterAlertFunc(func(aa *AlertAction) error { return execErr })
+
+	var seen error
+	aa.UsePostHook(func(aa *AlertAction) error {
+		seen = aa.GetLastError()
+		return aa.GetLastError()
+	})
+	if err := aa.runAlertFuncWithHooks(context.Background()); !errors.Is(err, execErr) || !errors.Is(seen, execErr) {
+		t.Errorf("execution error was not propagated. returned=%v seen by hook=%v", err, seen)
+	}
+
+	// the last registered post-hook is executed first, and suppresses the error
+	aa.UsePostHook(func(aa *AlertAction) error { return nil })
+	if err := aa.runAlertFuncWithHooks(context.Background()); err != nil || seen != nil {
+		t.Errorf("post-hook did not suppress the error. returned=%v seen by hook=%v", err, seen)
+	}
+
+	// a post-hook replaces the error, as seen by the post-hooks executed afterwards
+	replaced := errors.New("replaced")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+	aa.postHooks = aa.postHooks[:1]
+	aa.UsePostHook(TimingHook())
+	aa.UsePostHook(func(aa *AlertAction) error { return replaced })
+	if err := aa.runAlertFuncWithHooks(context.Background()); err != replaced || aa.GetLastError() != replaced || seen != replaced {
+		t.Errorf("post-hook did not replace the error. returned=%v seen by hook=%v", err, seen)
+	}
+	if !strings.Contains(stderr.String(), "status=failed duration_ms=") {
+		t.Errorf("TimingHook did not log the duration: %s", stderr.String())
+	}
+}
+
+func TestPanicRecoveryHook(t *testing.T) {
+	aa, _ := New("test-hooks", "Test hooks", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+	aa.RegisterAlertFunc(func(aa *AlertAction) error {
+		var m map[string]string
+		m["boom"] = "nil map"
+		return nil
+	})
+
+	// without the recovery hook, the panic is raised again after the post-hooks
+	var postHookRun bool
+	aa.UsePostHook(func(aa *AlertAction) error {
+		postHookRun = true
+		return aa.GetLastError()
+	})
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("panic of the alerting function was swallowed without PanicRecoveryHook")
+			}
+		}()
+		aa.runAlertFuncWithHooks(context.Background())
+	}()
+	if !postHookRun {
+		t.Error("post-hooks were not executed before raising the panic again")
+	}
+
+	aa.UsePostHook(PanicRecoveryHook())
+	err := aa.runAlertFuncWithHooks(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "alerting function panicked: assignment to entry in nil map") {
+		t.Errorf("panic was not converted into an error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Recovered from panic") {
+		t.Errorf("recovered panic was not logged: %s", stderr.String())
+	}
+}
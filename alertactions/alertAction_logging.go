@@ -4,12 +4,45 @@ package alertactions
 This file contains utility methods for the AlertAction struct to deal with logging
 */
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 )
 
+// logLine represents a single log line emitted when JSON output is enabled. See [AlertAction.EnableJSONOutput]
+type logLine struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	RunID     string `json:"run_id"`
+	Message   string `json:"message"`
+}
+
+// jsonLogLine returns a JSON-formatted log line, terminated by a newline.
+// Argument 'message' can use formatting markers as fmt.Sprintf. Aditional arguments 'a' will be provided to fmt.Sprintf
+func (aa *AlertAction) jsonLogLine(t time.Time, level string, message string, a ...interface{}) string {
+	l := logLine{
+		Timestamp: t.Format("2006-01-02T15:04:05.000-0700"),
+		Level:     level,
+		RunID:     aa.runID,
+		Message:   fmt.Sprintf(message, a...),
+	}
+	j, err := json.Marshal(l)
+	if err != nil {
+		// this cannot really happen, as the struct only contains strings
+		return fmt.Sprintf(`{"level":"ERROR","run_id":"%s","message":"cannot marshal log line to JSON"}`, aa.runID) + "\n"
+	}
+	return string(j) + "\n"
+}
+
+// useJSONOutput returns true if logs must be written as JSON lines.
+// When running interactively on a terminal, the text format is always preserved.
+func (aa *AlertAction) useJSONOutput() bool {
+	return aa.jsonOutput && !isAtTerminal
+}
+
 // getLoggingSourcetype returns a string indicating the sourcetype used for the administrative logging within index=_internal
 func (aa *AlertAction) getLoggingSourcetype() string {
 	return "alertaction:" + aa.StanzaName
@@ -27,10 +60,22 @@ func (aa *AlertAction) registerLogger() error {
 	}
 
 	// initialize a logger to perform internal logging
-	aa.splunkdlogger = aa.splunkd.NewLogger("runId:"+aa.runID, 0, "_internal", "", fmt.Sprintf("Alert [%s] %s", aa.GetApp(), aa.GetSearchName()), aa.getLoggingSourcetype())
+	l, err := aa.splunkd.NewLogger("runId:"+aa.runID, 0, "_internal", "", fmt.Sprintf("Alert [%s] %s", aa.GetApp(), aa.GetSearchName()), aa.getLoggingSourcetype())
+	if err != nil {
+		return fmt.Errorf("alert action setLogger: %w", err)
+	}
+	aa.splunkdlogger = l
 	return nil
 }
 
+// getStderr returns the writer where plain text logs are written: the stderr provided to Run, or os.Stderr.
+func (aa *AlertAction) getStderr() io.Writer {
+	if aa.stderr == nil {
+		return os.Stderr
+	}
+	return aa.stderr
+}
+
 // Log writes a log so that it can be read by Splunk.
 // Argument 'message' can use formatting markers as fmt.Sprintf. Aditional arguments 'a' will be provided to fmt.Sprintf
 func (aa *AlertAction) Log(level string, message string, a ...interface{}) {
@@ -47,8 +92,23 @@ func (aa *AlertAction) Log(level string, message string, a ...interface{}) {
 		level = "INFO"
 	}
 
+	t := time.Now().Round(time.Millisecond)
+	// logs can be written concurrently, e.g. by the alerting function and the timeout warning, while stderr need not be thread-safe
+	aa.logMu.Lock()
+	defer aa.logMu.Unlock()
+	if aa.useJSONOutput() {
+		line := aa.jsonLogLine(t, level, message, a...)
+		if aa.splunkdlogger != nil {
+			// the prefix of the logger would make the line invalid JSON: the run id is part of the line anyway
+			io.WriteString(aa.splunkdlogger.Writer(), line)
+		} else {
+			fmt.Fprint(aa.getStderr(), line)
+		}
+		return
+	}
+
 	message = fmt.Sprintf("%s [%s] %s - %s\n",
-		time.Now().Round(time.Millisecond).Format("2006-01-02T15:04:05.000-0700"),
+		t.Format("2006-01-02T15:04:05.000-0700"),
 		aa.StanzaName,
 		level,
 		message)
@@ -57,7 +117,7 @@ func (aa *AlertAction) Log(level string, message string, a ...interface{}) {
 	if !isAtTerminal && aa.splunkdlogger != nil {
 		aa.splunkdlogger.Printf(message, a...)
 	} else {
-		fmt.Fprintf(os.Stderr, message, a...)
+		fmt.Fprintf(aa.getStderr(), message, a...)
 	}
 }
 
@@ -74,7 +134,11 @@ func (aa *AlertAction) RegisterEndUserLogger(index, messagePrefix string) error
 	sourcetype := "alertaction:" + aa.StanzaName
 	// initialize a logger to perform logging visible by the end user
 	aa.Log("INFO", "Will be logging results of execution for the end-user as index=\"%s\" sourcetype=\"%s\"", index, sourcetype)
-	aa.endUserLogger = aa.splunkd.NewLogger(messagePrefix, 0, index, "", fmt.Sprintf("Alert [%s] %s", aa.GetApp(), aa.GetSearchName()), sourcetype)
+	l, err := aa.splunkd.NewLogger(messagePrefix, 0, index, "", fmt.Sprintf("Alert [%s] %s", aa.GetApp(), aa.GetSearchName()), sourcetype)
+	if err != nil {
+		return fmt.Errorf("alert action setEndUserLogger: %w", err)
+	}
+	aa.endUserLogger = l
 	return nil
 }
 
@@ -88,8 +152,16 @@ func (aa *AlertAction) LogForEndUser(level string, message string, a ...interfac
 		return
 	}
 	level = strings.ToUpper(level)
+	t := time.Now().Round(time.Millisecond)
+	if aa.useJSONOutput() {
+		// the prefix of the logger is moved within the message, so that the line is valid JSON
+		aa.logMu.Lock()
+		defer aa.logMu.Unlock()
+		io.WriteString(aa.endUserLogger.Writer(), aa.jsonLogLine(t, level, aa.endUserLogger.Prefix()+message, a...))
+		return
+	}
 	message = fmt.Sprintf("%s %s - %s\n",
-		time.Now().Round(time.Millisecond).Format("2006-01-02T15:04:05.000-0700"),
+		t.Format("2006-01-02T15:04:05.000-0700"),
 		level,
 		message)
 
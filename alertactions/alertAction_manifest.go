@@ -0,0 +1,142 @@
+package alertactions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// appManifestSchemaVersion is the version of the app.manifest schema generated by AppManifestConfig.Generate
+// See: https://dev.splunk.com/enterprise/reference/packagingtoolkit/pkgtoolkitappmanifest
+const appManifestSchemaVersion = "1.1.0"
+
+// AppManifestConfig defines the settings of the app.manifest file which cannot be derived from an alert action or a modular input.
+// app.manifest is required by the app inspection process when packaging an app for Splunkbase.
+type AppManifestConfig struct {
+	// LicenseName is the name of the license, e.g. "Apache License 2.0"
+	LicenseName string
+	// LicenseType is the type of the license, e.g. "Apache-2.0". Defaults to "Proprietary"
+	LicenseType string
+	// LicenseURI is the location of the text of the license, e.g. "./LICENSE.txt"
+	LicenseURI string
+	// SplunkVersion constrains the versions of Splunk Enterprise the app is compatible with, e.g. ">=9.0"
+	SplunkVersion string
+	// SupportedDeployments lists the deployment types the app can be installed on, e.g. "_standalone", "_distributed", "_search_head_clustering"
+	SupportedDeployments []string
+	// TargetWorkloads lists the roles of the splunk instances the app must be installed on, e.g. "_search_heads", "_forwarders"
+	TargetWorkloads []string
+	// Dependencies maps the apps required by the app, as "<group>/<name>", to the range of their acceptable versions, e.g. ">=1.2.0"
+	Dependencies map[string]string
+	// Tasks lists the apps, as "<group>/<name>", which must be installed before the app
+	Tasks []string
+	// ACSPermissions lists the capabilities the app needs within Splunk Cloud when installed through the Admin Config Service (ACS)
+	ACSPermissions []string
+}
+
+type appManifest struct {
+	SchemaVersion        string                       `json:"schemaVersion"`
+	Info                 appManifestInfo              `json:"info"`
+	Dependencies         map[string]map[string]string `json:"dependencies"`
+	Tasks                []string                     `json:"tasks"`
+	InputGroups          map[string]interface{}       `json:"inputGroups"`
+	IncompatibleApps     map[string]interface{}       `json:"incompatibleApps"`
+	PlatformRequirements map[string]interface{}       `json:"platformRequirements"`
+	SupportedDeployments []string                     `json:"supportedDeployments"`
+	TargetWorkloads      []string                     `json:"targetWorkloads"`
+	ACSPermissions       []string                     `json:"acsPermissions,omitempty"`
+}
+
+type appManifestInfo struct {
+	Title string `json:"title"`
+	Id    struct {
+		Group   *string `json:"group"`
+		Name    string  `json:"name"`
+		Version string  `json:"version"`
+	} `json:"id"`
+	Author                  []appManifestAuthor `json:"author"`
+	ReleaseDate             *string             `json:"releaseDate"`
+	Description             string              `json:"description"`
+	Classification          interface{}         `json:"classification"`
+	CommonInformationModels interface{}         `json:"commonInformationModels"`
+	License                 struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Text string `json:"text"`
+		URI  string `json:"uri"`
+	} `json:"license"`
+	PrivacyPolicy interface{} `json:"privacyPolicy"`
+	ReleaseNotes  interface{} `json:"releaseNotes"`
+}
+
+type appManifestAuthor struct {
+	Name    string  `json:"name"`
+	Email   *string `json:"email"`
+	Company *string `json:"company"`
+}
+
+// Generate returns the JSON-formatted app.manifest for the app appName, at the provided version.
+// It is used by AlertAction.GenerateAppManifest and ModularInput.GenerateAppManifest, which derive title and parameters from their own definition.
+// paramTitles are listed at the end of the description. defaultWorkloads is used if no TargetWorkloads have been configured.
+func (cfg AppManifestConfig) Generate(appName, version, author, title, description string, paramTitles []string, defaultWorkloads ...string) string {
+	m := appManifest{
+		SchemaVersion:        appManifestSchemaVersion,
+		Tasks:                cfg.Tasks,
+		SupportedDeployments: cfg.SupportedDeployments,
+		TargetWorkloads:      cfg.TargetWorkloads,
+		ACSPermissions:       cfg.ACSPermissions,
+	}
+	m.Info.Title = title
+	m.Info.Id.Name = appName
+	m.Info.Id.Version = version
+	m.Info.Author = []appManifestAuthor{{Name: author}}
+	m.Info.Description = description
+	if len(paramTitles) > 0 {
+		m.Info.Description = strings.TrimSpace(fmt.Sprintf("%s Configurable parameters: %s.", description, strings.Join(paramTitles, ", ")))
+	}
+	m.Info.License.Name = cfg.LicenseName
+	m.Info.License.Type = cfg.LicenseType
+	if m.Info.License.Type == "" {
+		m.Info.License.Type = "Proprietary"
+	}
+	if m.Info.License.Name == "" {
+		m.Info.License.Name = m.Info.License.Type
+	}
+	m.Info.License.URI = cfg.LicenseURI
+	if len(cfg.Dependencies) > 0 {
+		m.Dependencies = make(map[string]map[string]string, len(cfg.Dependencies))
+		for app, versions := range cfg.Dependencies {
+			m.Dependencies[app] = map[string]string{"version": versions}
+		}
+	}
+	if cfg.SplunkVersion != "" {
+		m.PlatformRequirements = map[string]interface{}{"splunk": map[string]string{"Enterprise": cfg.SplunkVersion}}
+	}
+	if len(m.SupportedDeployments) == 0 {
+		m.SupportedDeployments = []string{"_standalone", "_distributed"}
+	}
+	if len(m.TargetWorkloads) == 0 {
+		m.TargetWorkloads = defaultWorkloads
+	}
+	// marshalling cannot fail, as the manifest only contains strings, slices and maps
+	out, _ := json.MarshalIndent(m, "", "  ")
+	return string(out)
+}
+
+// SetAppManifestConfig configures the settings used by GenerateAppManifest
+func (aa *AlertAction) SetAppManifestConfig(cfg AppManifestConfig) {
+	aa.appManifestConfig = cfg
+}
+
+// GenerateAppManifest returns the JSON-formatted app.manifest of the app appName containing the alert action, as required for Splunkbase packaging.
+// If description is empty, the description of the alert action is used. The titles of the registered parameters are appended to it.
+// Further settings such as the license can be configured with SetAppManifestConfig.
+func (aa *AlertAction) GenerateAppManifest(appName, version, author, description string) string {
+	if description == "" {
+		description = aa.Description
+	}
+	titles := make([]string, 0, len(aa.params))
+	for _, p := range aa.params {
+		titles = append(titles, p.Title)
+	}
+	return aa.appManifestConfig.Generate(appName, version, author, aa.Label, description, titles, "_search_heads")
+}
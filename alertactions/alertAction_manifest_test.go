@@ -0,0 +1,73 @@
+package alertactions
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAppManifest(t *testing.T) {
+	aa, _ := New("test-manifest", "Test manifest", "Creates tickets.", "")
+	p, _ := NewParamBuilder("ticket_id").Title("Ticket ID").Build()
+	if err := aa.RegisterParam(p); err != nil {
+		t.Fatal(err)
+	}
+	aa.SetAppManifestConfig(AppManifestConfig{
+		LicenseName:    "Apache License 2.0",
+		LicenseType:    "Apache-2.0",
+		SplunkVersion:  ">=9.0",
+		Dependencies:   map[string]string{"splunk/Splunk_SA_CIM": ">=5.0.0"},
+		ACSPermissions: []string{"edit_tokens_own"},
+	})
+
+	var manifest struct {
+		SchemaVersion string `json:"schemaVersion"`
+		Info          struct {
+			Title string `json:"title"`
+			Id    struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"id"`
+			Author []struct {
+				Name string `json:"name"`
+			} `json:"author"`
+			Description string `json:"description"`
+			License     struct {
+				Type string `json:"type"`
+			} `json:"license"`
+		} `json:"info"`
+		Dependencies         map[string]map[string]string `json:"dependencies"`
+		Tasks                []string                     `json:"tasks"`
+		PlatformRequirements map[string]map[string]string `json:"platformRequirements"`
+		TargetWorkloads      []string                     `json:"targetWorkloads"`
+		ACSPermissions       []string                     `json:"acsPermissions"`
+	}
+	generated := aa.GenerateAppManifest("TA-tickets", "1.2.3", "Jane Doe", "")
+	if err := json.Unmarshal([]byte(generated), &manifest); err != nil {
+		t.Fatalf("invalid JSON generated: %s\n%s", err.Error(), generated)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"schemaVersion", manifest.SchemaVersion},
+		{"info.title", manifest.Info.Title},
+		{"info.id.name", manifest.Info.Id.Name},
+		{"info.id.version", manifest.Info.Id.Version},
+		{"info.license.type", manifest.Info.License.Type},
+		{"info.description", manifest.Info.Description},
+	} {
+		if field.value == "" {
+			t.Errorf("required field '%s' is empty", field.name)
+		}
+	}
+	if len(manifest.Info.Author) != 1 || manifest.Info.Author[0].Name != "Jane Doe" {
+		t.Errorf("wrong author: %v", manifest.Info.Author)
+	}
+	if !strings.Contains(manifest.Info.Description, "Creates tickets.") || !strings.Contains(manifest.Info.Description, "Ticket ID") {
+		t.Errorf("description not generated from the registered parameters: %s", manifest.Info.Description)
+	}
+	if !strings.Contains(generated, `"tasks": null`) || manifest.Dependencies["splunk/Splunk_SA_CIM"]["version"] != ">=5.0.0" {
+		t.Errorf("wrong dependencies and tasks: %s", generated)
+	}
+	if manifest.PlatformRequirements["splunk"]["Enterprise"] != ">=9.0" || len(manifest.TargetWorkloads) != 1 || len(manifest.ACSPermissions) != 1 {
+		t.Errorf("wrong compatibility constraints: %s", generated)
+	}
+}
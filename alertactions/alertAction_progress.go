@@ -0,0 +1,121 @@
+package alertactions
+
+/*
+This file contains utility methods for the AlertAction struct to report the progress of long-running executions
+*/
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// Progress logs an INFO message reporting that processed items out of total have been processed, such as
+//
+//	progress processed=42/1000 (4.2%) elapsed_s=12.0 throughput=3.50/s remaining_s=273.7 message="submitted ticket #42"
+//
+// Elapsed time, throughput and estimated remaining time are computed from the start of the execution.
+// If total <= 0, only the number of processed items is reported. message can be empty.
+func (aa *AlertAction) Progress(processed, total int, message string) {
+	aa.logProgress(processed, total, message, aa.executionStart)
+}
+
+// logProgress logs the progress of the execution, computing elapsed time, throughput and remaining time since start, if not zero
+func (aa *AlertAction) logProgress(processed, total int, message string, start time.Time) {
+	line := fmt.Sprintf("progress processed=%d", processed)
+	if total > 0 {
+		line = fmt.Sprintf("%s/%d (%.1f%%)", line, total, float64(processed)*100/float64(total))
+	}
+	if !start.IsZero() {
+		elapsed := time.Since(start).Seconds()
+		line = fmt.Sprintf("%s elapsed_s=%.1f", line, elapsed)
+		if elapsed > 0 {
+			line = fmt.Sprintf("%s throughput=%.2f/s", line, float64(processed)/elapsed)
+		}
+		if total > 0 && processed > 0 && processed <= total {
+			line = fmt.Sprintf("%s remaining_s=%.1f", line, elapsed*float64(total-processed)/float64(processed))
+		}
+	}
+	if message != "" {
+		line = fmt.Sprintf(`%s message="%s"`, line, message)
+	}
+	// line is not used as format, as message can contain '%' characters
+	aa.Log("INFO", "%s", line)
+}
+
+// ProgressTracker counts the items processed by an alerting function and reports the progress through AlertAction.Log.
+// Its methods can be used concurrently. Use AlertAction.NewProgressTracker to instantiate it.
+type ProgressTracker struct {
+	aa        *AlertAction
+	total     int
+	processed int64
+	start     time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewProgressTracker returns a tracker of the processing of total items, e.g. the number of search results.
+// Elapsed time, throughput and estimated remaining time are computed from the creation of the tracker.
+func (aa *AlertAction) NewProgressTracker(total int) *ProgressTracker {
+	return &ProgressTracker{aa: aa, total: total, start: time.Now()}
+}
+
+// Increment adds n to the number of processed items
+func (pt *ProgressTracker) Increment(n int) {
+	atomic.AddInt64(&pt.processed, int64(n))
+}
+
+// Processed returns the number of processed items
+func (pt *ProgressTracker) Processed() int {
+	return int(atomic.LoadInt64(&pt.processed))
+}
+
+// Report logs the current progress, along with message. See AlertAction.Progress
+func (pt *ProgressTracker) Report(message string) {
+	pt.aa.logProgress(pt.Processed(), pt.total, message, pt.start)
+}
+
+// StartAutoReport reports the progress every interval, until Stop is called.
+// Calling it again replaces the previous interval.
+func (pt *ProgressTracker) StartAutoReport(interval time.Duration) error {
+	if interval <= 0 {
+		return utils.NewErrInvalidParam("progressTracker.StartAutoReport", nil, "'interval' must be greater than 0, provided: %s", interval)
+	}
+	pt.Stop()
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	stop, done := make(chan struct{}), make(chan struct{})
+	pt.stop, pt.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				pt.Report("")
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the automatic reporting started with StartAutoReport and waits for it to terminate.
+// It can be called multiple times, and also if no automatic reporting was started.
+func (pt *ProgressTracker) Stop() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	if pt.stop == nil {
+		return
+	}
+	close(pt.stop)
+	<-pt.done
+	pt.stop, pt.done = nil, nil
+}
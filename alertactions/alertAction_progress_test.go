@@ -0,0 +1,73 @@
+package alertactions
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	aa, _ := New("test-progress", "Test progress", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+
+	aa.Progress(42, 1000, "submitted ticket #42")
+	if !strings.Contains(stderr.String(), `INFO - progress processed=42/1000 (4.2%) message="submitted ticket #42"`) {
+		t.Errorf("wrong progress logged: %s", stderr.String())
+	}
+	stderr.Reset()
+	aa.Progress(7, 0, "")
+	if !strings.Contains(stderr.String(), "progress processed=7\n") {
+		t.Errorf("wrong progress logged without total: %s", stderr.String())
+	}
+}
+
+func TestProgressTracker(t *testing.T) {
+	aa, _ := New("test-progress", "Test progress", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+
+	pt := aa.NewProgressTracker(200)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				pt.Increment(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if pt.Processed() != 50 {
+		t.Fatalf("concurrent increments were lost. expected=50 got=%d", pt.Processed())
+	}
+	pt.Report("halfway through the first batch")
+	logs := stderr.String()
+	for _, expected := range []string{"progress processed=50/200 (25.0%)", "elapsed_s=", "throughput=", "remaining_s=", `message="halfway through the first batch"`} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("progress report does not contain '%s': %s", expected, logs)
+		}
+	}
+
+	if err := pt.StartAutoReport(0); err == nil {
+		t.Error("StartAutoReport accepted an interval of 0")
+	}
+	stderr.Reset()
+	if err := pt.StartAutoReport(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	pt.Increment(150)
+	time.Sleep(50 * time.Millisecond)
+	pt.Stop()
+	pt.Stop()
+	logs = stderr.String()
+	if !strings.Contains(logs, "progress processed=200/200 (100.0%)") {
+		t.Errorf("progress was not reported automatically: %s", logs)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if stderr.String() != logs {
+		t.Error("progress was reported after Stop")
+	}
+}
@@ -0,0 +1,95 @@
+package alertactions
+
+/*
+This file contains utility methods for the AlertAction struct to read the search results without loading them all in memory
+*/
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStopIteration can be returned by the function provided to IterateResults to stop the iteration without an error
+var ErrStopIteration = errors.New("stop iteration")
+
+// IterateResults reads the results file one row at a time, invoking fn for each row.
+// Rows are provided as maps having the fields of the CSV header as keys.
+// The iteration stops at the first error returned by fn, which is returned by IterateResults,
+// unless it is ErrStopIteration: in that case, IterateResults returns nil.
+func (aa *AlertAction) IterateResults(fn func(row map[string]string) error) error {
+	if fn == nil {
+		return fmt.Errorf("iterateResults: 'fn' cannot be nil")
+	}
+	f, err := aa.GetResultsFile()
+	if err != nil {
+		return fmt.Errorf("iterateResults: %w", err)
+	}
+	defer f.Close()
+	gzReader, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("iterateResults: %w", err)
+	}
+	defer gzReader.Close()
+	r := csv.NewReader(gzReader)
+	// the header is re-used for all rows, and the record slice can be re-used as the values are copied into the maps
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("iterateResults: cannot read header of results file: %w", err)
+	}
+	header = append([]string(nil), header...)
+
+	for rowNum := 1; ; rowNum++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("iterateResults: cannot read row %d of results file: %w", rowNum, err)
+		}
+		row := make(map[string]string, len(header))
+		for i, field := range header {
+			if i < len(record) {
+				row[field] = record[i]
+			}
+		}
+		if err := fn(row); errors.Is(err, ErrStopIteration) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// ResultsChannel reads the results file in a separate goroutine, sending each row on the returned channel,
+// which can hold up to bufSize rows. The rows channel is closed once all rows have been sent, or the reading failed.
+// At most one error is sent on the error channel, which is closed afterwards.
+// Cancelling ctx stops the reading, and ctx.Err() is sent on the error channel.
+func (aa *AlertAction) ResultsChannel(ctx context.Context, bufSize int) (<-chan map[string]string, <-chan error) {
+	if bufSize < 0 {
+		bufSize = 0
+	}
+	rows := make(chan map[string]string, bufSize)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(rows)
+		err := aa.IterateResults(func(row map[string]string) error {
+			select {
+			case rows <- row:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return rows, errs
+}
@@ -0,0 +1,92 @@
+package alertactions
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeTestResultsFile writes a gzip-compressed CSV results file with numRows rows and returns its path
+func writeTestResultsFile(t *testing.T, numRows int) string {
+	path := filepath.Join(t.TempDir(), "results.csv.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	fmt.Fprintln(gz, `host,count,_raw`)
+	for i := 0; i < numRows; i++ {
+		fmt.Fprintf(gz, "web%02d,%d,\"line one\nline, two\"\n", i, i)
+	}
+	gz.Close()
+	f.Close()
+	return path
+}
+
+func TestIterateResults(t *testing.T) {
+	aa, _ := New("test-iterate", "Test iterate", "", "")
+	aa.runtimeConfig = &alertConfig{ResultsFile: writeTestResultsFile(t, 100)}
+
+	cnt := 0
+	err := aa.IterateResults(func(row map[string]string) error {
+		if row["host"] != fmt.Sprintf("web%02d", cnt) || row["count"] != strconv.Itoa(cnt) || row["_raw"] != "line one\nline, two" {
+			t.Errorf("IterateResults provided a wrong row %d: %v", cnt, row)
+		}
+		cnt++
+		return nil
+	})
+	if err != nil || cnt != 100 {
+		t.Errorf("IterateResults did not provide all rows. expected=100 got=%d err=%v", cnt, err)
+	}
+
+	cnt = 0
+	err = aa.IterateResults(func(row map[string]string) error {
+		cnt++
+		if cnt == 10 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || cnt != 10 {
+		t.Errorf("IterateResults did not stop cleanly on ErrStopIteration. rows=%d err=%v", cnt, err)
+	}
+
+	fnErr := errors.New("processing failed")
+	if err = aa.IterateResults(func(row map[string]string) error { return fnErr }); !errors.Is(err, fnErr) {
+		t.Errorf("IterateResults did not return the error of fn. got=%v", err)
+	}
+
+	aa.runtimeConfig.ResultsFile = filepath.Join(t.TempDir(), "missing.csv.gz")
+	if err = aa.IterateResults(func(row map[string]string) error { return nil }); err == nil {
+		t.Error("IterateResults did not fail on a missing results file")
+	}
+}
+
+func TestResultsChannel(t *testing.T) {
+	aa, _ := New("test-channel", "Test channel", "", "")
+	aa.runtimeConfig = &alertConfig{ResultsFile: writeTestResultsFile(t, 50)}
+
+	rows, errs := aa.ResultsChannel(context.Background(), 5)
+	cnt := 0
+	for range rows {
+		cnt++
+	}
+	if err := <-errs; err != nil || cnt != 50 {
+		t.Errorf("ResultsChannel did not provide all rows. expected=50 got=%d err=%v", cnt, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, errs = aa.ResultsChannel(ctx, 0)
+	<-rows
+	cancel()
+	for range rows {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("ResultsChannel did not report the cancellation. got=%v", err)
+	}
+}
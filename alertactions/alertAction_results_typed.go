@@ -0,0 +1,181 @@
+package alertactions
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// splunkTimeFormats are the formats, besides epoch timestamps, accepted for time.Time fields by ParseResults
+var splunkTimeFormats = []string{time.RFC3339Nano, "2006-01-02T15:04:05.000-07:00", "2006-01-02 15:04:05.000 MST", "2006-01-02 15:04:05"}
+
+// resultField describes how a column of the results file is stored into a field of a struct
+type resultField struct {
+	column   string
+	index    int
+	required bool
+}
+
+// ParseResults reads the results file of the alert action and appends one T per row to dest.
+// T must be a struct: its exported fields are populated from the columns named by their `splunk:"fieldname"` tag
+// or, without tag, by their lowercase name. Fields tagged `splunk:"-"` are ignored.
+//
+//	type Row struct {
+//		Time  time.Time `splunk:"_time"`
+//		Host  string    `splunk:"host,required"`
+//		Count int
+//		Ratio float64   `splunk:"ratio"`
+//		Alert bool      `splunk:"is_alert"`
+//	}
+//
+// Columns missing within the results are ignored, unless the field is tagged as 'required': an error is returned then, if the results contain any row.
+// Empty values leave fields at their zero value. Supported types are string, int, uint and float of any size, bool and time.Time,
+// which is parsed from epoch timestamps, as splunk provides _time, or from RFC3339 timestamps.
+// This is a function instead of a method of AlertAction, as Go does not allow methods to have type parameters.
+func ParseResults[T any](aa *AlertAction, dest *[]T) error {
+	if aa == nil || dest == nil {
+		return fmt.Errorf("parseResults: 'aa' and 'dest' cannot be nil")
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("parseResults: type %s is not a struct", t)
+	}
+	fields, err := resultFields(t)
+	if err != nil {
+		return fmt.Errorf("parseResults: %w", err)
+	}
+	rowNum := 0
+	err = aa.IterateResults(func(row map[string]string) error {
+		rowNum++
+		var v T
+		rv := reflect.ValueOf(&v).Elem()
+		for _, f := range fields {
+			value, found := row[f.column]
+			if !found {
+				if f.required {
+					return fmt.Errorf("required column '%s' not found within results", f.column)
+				}
+				continue
+			}
+			if err := setResultField(rv.Field(f.index), value); err != nil {
+				return fmt.Errorf("row %d, column '%s': %w", rowNum, f.column, err)
+			}
+		}
+		*dest = append(*dest, v)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("parseResults: %w", err)
+	}
+	return nil
+}
+
+// resultFields returns the fields of struct t to be populated by ParseResults
+func resultFields(t reflect.Type) ([]resultField, error) {
+	fields := make([]resultField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("splunk")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		if !isSupportedResultType(sf.Type) {
+			return nil, fmt.Errorf("field %s has unsupported type %s", sf.Name, sf.Type)
+		}
+		fields = append(fields, resultField{column: name, index: i, required: opts == "required"})
+	}
+	return fields, nil
+}
+
+// isSupportedResultType returns true if ParseResults can convert values into type t
+func isSupportedResultType(t reflect.Type) bool {
+	if t == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// setResultField converts value into the type of field and stores it. Empty values are not converted.
+func setResultField(field reflect.Value, value string) error {
+	if field.Kind() == reflect.String {
+		field.SetString(value)
+		return nil
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		ts, err := parseSplunkTime(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(ts))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean '%s'", value)
+		}
+		field.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number '%s'", value)
+		}
+		field.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer '%s'", value)
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer '%s'", value)
+		}
+		field.SetUint(u)
+	}
+	return nil
+}
+
+// parseSplunkTime parses epoch timestamps with optional sub-second precision, such as 1700000000.123, or timestamps having one of splunkTimeFormats
+func parseSplunkTime(value string) (time.Time, error) {
+	if sec, frac, found := strings.Cut(value, "."); !found || frac != "" {
+		if s, err := strconv.ParseInt(sec, 10, 64); err == nil {
+			var nsec int64
+			if found {
+				// sub-second digits are right-padded to nanoseconds
+				frac = (frac + "000000000")[:9]
+				if nsec, err = strconv.ParseInt(frac, 10, 64); err != nil {
+					return time.Time{}, fmt.Errorf("invalid timestamp '%s'", value)
+				}
+			}
+			return time.Unix(s, nsec), nil
+		}
+	}
+	for _, layout := range splunkTimeFormats {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s'", value)
+}
@@ -0,0 +1,71 @@
+package alertactions
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(`_time,host,count,ratio,is_alert,_raw
+1700000000.250,web01,42,0.5,1,"line one
+line two"
+2023-11-14T22:13:20.000+00:00,web02,,1e3,false,
+`))
+	gz.Close()
+	f.Close()
+	aa, _ := New("test-typed", "Test typed", "", "")
+	aa.runtimeConfig = &alertConfig{ResultsFile: path}
+
+	type row struct {
+		Time    time.Time `splunk:"_time"`
+		Host    string    `splunk:"host,required"`
+		Count   int
+		Ratio   float64 `splunk:"ratio"`
+		IsAlert bool    `splunk:"is_alert"`
+		Raw     string  `splunk:"_raw"`
+		Missing string  `splunk:"not_in_results"`
+		Ignored int     `splunk:"-"`
+	}
+	rows := []row{}
+	if err := ParseResults(aa, &rows); err != nil {
+		t.Fatalf("ParseResults returned an error: %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("wrong number of rows. expected=2 got=%d", len(rows))
+	}
+	first := rows[0]
+	if !first.Time.Equal(time.Unix(1700000000, 250000000)) || first.Host != "web01" || first.Count != 42 || first.Ratio != 0.5 || !first.IsAlert || first.Raw != "line one\nline two" {
+		t.Errorf("wrong row parsed: %+v", first)
+	}
+	second := rows[1]
+	if !second.Time.Equal(time.Unix(1700000000, 0)) || second.Host != "web02" || second.Count != 0 || second.Ratio != 1000 || second.IsAlert || second.Missing != "" {
+		t.Errorf("wrong row parsed: %+v", second)
+	}
+
+	type withRequired struct {
+		Host  string `splunk:"host"`
+		Owner string `splunk:"owner,required"`
+	}
+	if err := ParseResults(aa, &[]withRequired{}); err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Errorf("ParseResults did not fail for a missing required column. err=%v", err)
+	}
+	type wrongType struct {
+		Host int `splunk:"host"`
+	}
+	if err := ParseResults(aa, &[]wrongType{}); err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Errorf("ParseResults did not fail for a value which cannot be converted. err=%v", err)
+	}
+	if err := ParseResults(aa, &[]string{}); err == nil {
+		t.Error("ParseResults accepted a type which is not a struct")
+	}
+}
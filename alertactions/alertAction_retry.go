@@ -0,0 +1,92 @@
+package alertactions
+
+/*
+This file contains utility methods for the AlertAction struct to retry alerting functions failing because of transient errors
+*/
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryableError is implemented by errors signaling a transient failure, such as a downstream API replying with HTTP 429 or 503.
+// Alerting functions registered with RegisterAlertFuncWithRetry are only executed again when failing with a RetryableError.
+type RetryableError interface {
+	error
+	IsRetryable() bool
+}
+
+// retryableError is the RetryableError returned by RetryableErrorf
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryableError) IsRetryable() bool {
+	return true
+}
+
+// RetryableErrorf formats an error as fmt.Errorf does, marking it as retryable.
+func RetryableErrorf(format string, a ...any) error {
+	return &retryableError{err: fmt.Errorf(format, a...)}
+}
+
+// isRetryable reports whether err, or any error it wraps, is a RetryableError requesting a retry
+func isRetryable(err error) bool {
+	var re RetryableError
+	return errors.As(err, &re) && re.IsRetryable()
+}
+
+// RegisterAlertFuncWithRetry configures the alerting function f, which is executed up to maxAttempts times
+// as long as it fails with a RetryableError. Any other error aborts the execution immediately.
+// Between two attempts, the alert action waits baseDelay * 2^(attempt-1), plus a random jitter of up to baseDelay.
+// No further attempt is made once the execution is cancelled, e.g. by SIGTERM or by the timeout configured with WithTimeout.
+func (aa *AlertAction) RegisterAlertFuncWithRetry(f AlertingFunc, maxAttempts int, baseDelay time.Duration) {
+	if f == nil {
+		aa.RegisterAlertFunc(nil)
+		return
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if baseDelay < 0 {
+		baseDelay = 0
+	}
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, ctx context.Context) error {
+		var err error
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			aa.Log("INFO", "Executing alerting function attempt=%d/%d", attempt, maxAttempts)
+			if err = f(aa); err == nil || !isRetryable(err) {
+				return err
+			}
+			if attempt == maxAttempts {
+				break
+			}
+			if ctx.Err() != nil {
+				return fmt.Errorf("alerting function interrupted after %d attempts: %w. %w", attempt, ctx.Err(), err)
+			}
+			delay := baseDelay << (attempt - 1)
+			if baseDelay > 0 {
+				delay += time.Duration(rand.Int63n(int64(baseDelay)))
+			}
+			aa.Log("WARN", "attempt=%d/%d failed: %s, retrying in %s", attempt, maxAttempts, err.Error(), delay)
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("alerting function interrupted after %d attempts: %w. %w", attempt, ctx.Err(), err)
+			case <-timer.C:
+			}
+		}
+		return fmt.Errorf("alerting function failed after %d attempts: %w", maxAttempts, err)
+	})
+}
@@ -0,0 +1,94 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterAlertFuncWithRetry(t *testing.T) {
+	aa, _ := New("test-retry", "Test retry", "", "")
+
+	// transient errors are retried until the function succeeds
+	calls := 0
+	aa.RegisterAlertFuncWithRetry(func(aa *AlertAction) error {
+		calls++
+		if calls < 3 {
+			return RetryableErrorf("HTTP %d", 503)
+		}
+		return nil
+	}, 5, time.Millisecond)
+	if err := aa.execute(aa, context.Background()); err != nil || calls != 3 {
+		t.Errorf("retried alerting function did not succeed on the third call. calls=%d err=%v", calls, err)
+	}
+
+	// attempts are limited to maxAttempts
+	calls = 0
+	aa.RegisterAlertFuncWithRetry(func(aa *AlertAction) error {
+		calls++
+		return RetryableErrorf("HTTP %d", 429)
+	}, 4, time.Millisecond)
+	err := aa.execute(aa, context.Background())
+	var re RetryableError
+	if err == nil || calls != 4 || !errors.As(err, &re) {
+		t.Errorf("retried alerting function was not called exactly maxAttempts times. calls=%d err=%v", calls, err)
+	}
+
+	// other errors abort immediately
+	calls = 0
+	fatal := errors.New("invalid configuration")
+	aa.RegisterAlertFuncWithRetry(func(aa *AlertAction) error {
+		calls++
+		return fatal
+	}, 4, time.Millisecond)
+	if err := aa.execute(aa, context.Background()); !errors.Is(err, fatal) || calls != 1 {
+		t.Errorf("non-retryable error was retried. calls=%d err=%v", calls, err)
+	}
+}
+
+func TestRegisterAlertFuncWithRetryCancelled(t *testing.T) {
+	aa, _ := New("test-retry", "Test retry", "", "")
+	aa.stderr = new(strings.Builder)
+
+	// the backoff is interrupted by the cancellation of the context
+	calls := 0
+	aa.RegisterAlertFuncWithRetry(func(aa *AlertAction) error {
+		calls++
+		return RetryableErrorf("HTTP %d", 503)
+	}, 5, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := aa.execute(aa, ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !isRetryable(err) || calls != 1 {
+		t.Errorf("cancellation did not interrupt the retries. calls=%d err=%v", calls, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("the backoff was not interrupted by the cancellation: %s", time.Since(start))
+	}
+
+	// no attempt is made after the context has been cancelled
+	calls = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	aa.RegisterAlertFuncWithRetry(func(aa *AlertAction) error {
+		calls++
+		cancel()
+		return RetryableErrorf("HTTP %d", 429)
+	}, 5, 0)
+	if err := aa.execute(aa, ctx); !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("alerting function was retried after the cancellation. calls=%d err=%v", calls, err)
+	}
+}
+
+func TestRetryableErrorf(t *testing.T) {
+	cause := errors.New("connection reset")
+	err := RetryableErrorf("posting results: %w", cause)
+	if !isRetryable(err) || !errors.Is(err, cause) || err.Error() != "posting results: connection reset" {
+		t.Errorf("RetryableErrorf returned a wrong error: %v", err)
+	}
+	if isRetryable(errors.New("plain")) {
+		t.Error("a plain error was considered retryable")
+	}
+}
@@ -0,0 +1,108 @@
+package alertactions
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/splunkd"
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+const (
+	// defaultSearchMaxCount is the maximum number of results retained by the search jobs of RunSearch, unless configured with SetSearchMaxCount.
+	// Without it, splunkd would retain at most 10000 results.
+	defaultSearchMaxCount = 1000000
+	// searchJobCancelTimeout limits the time spent cancelling a search job once its results have been retrieved
+	searchJobCancelTimeout = 10 * time.Second
+)
+
+// SetSearchMaxCount configures the maximum number of results retained by the search jobs executed by RunSearch, 1000000 by default.
+func (aa *AlertAction) SetSearchMaxCount(n int) error {
+	if n < 1 {
+		return utils.NewErrInvalidParam("setSearchMaxCount", nil, "'n' must be at least 1")
+	}
+	aa.searchMaxCount = n
+	return nil
+}
+
+// RunSearch executes search within the namespace of the alert action, waits for its completion and returns all of its results.
+// earliestTime and latestTime restrict the time range of the search, e.g. "-24h" and "now". If empty, splunk's defaults apply.
+// The job is executed in 'fast' mode, as field discovery of verbose mode is not needed to enrich results. Results are retrieved page by page.
+// The job is cancelled once the results have been retrieved, or if ctx is cancelled before it completes.
+// At most 1000000 results are retained by the job: use SetSearchMaxCount to change this limit.
+func (aa *AlertAction) RunSearch(ctx context.Context, search string, earliestTime, latestTime string) ([]map[string]interface{}, error) {
+	var results []map[string]interface{}
+	err := aa.runSearchJob(ctx, search, earliestTime, latestTime, func(job *splunkd.SearchJob) (err error) {
+		results, err = job.Results(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("runSearch: %w", err)
+	}
+	return results, nil
+}
+
+// RunSearchCount returns the number of results of search within the namespace of the alert action, as computed by splunk with '| stats count'.
+// See RunSearch for the meaning of the parameters.
+func (aa *AlertAction) RunSearchCount(ctx context.Context, search string, earliestTime, latestTime string) (int64, error) {
+	var count int64
+	err := aa.runSearchJob(ctx, strings.TrimSpace(search)+" | stats count", earliestTime, latestTime, func(job *splunkd.SearchJob) error {
+		results, err := job.Results(&url.Values{"count": {"1"}})
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return fmt.Errorf("no count returned for search job '%s'", job.GetSid())
+		}
+		if count, err = strconv.ParseInt(fmt.Sprint(results[0]["count"]), 10, 64); err != nil {
+			return fmt.Errorf("invalid count returned for search job '%s': %w", job.GetSid(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("runSearchCount: %w", err)
+	}
+	return count, nil
+}
+
+// runSearchJob starts a search job, waits for its completion, executes fn on it and cancels it
+func (aa *AlertAction) runSearchJob(ctx context.Context, search, earliestTime, latestTime string, fn func(job *splunkd.SearchJob) error) error {
+	ss, err := aa.GetSplunkService()
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("adhoc_search_level", "fast")
+	maxCount := aa.searchMaxCount
+	if maxCount <= 0 {
+		maxCount = defaultSearchMaxCount
+	}
+	params.Set("max_count", strconv.Itoa(maxCount))
+	if earliestTime != "" {
+		params.Set("earliest_time", earliestTime)
+	}
+	if latestTime != "" {
+		params.Set("latest_time", latestTime)
+	}
+	job, err := ss.NewSearchJob(search, &params)
+	if err != nil {
+		return err
+	}
+	aa.Log("DEBUG", `Search job started. sid="%s" search="%s"`, job.GetSid(), search)
+	defer func() {
+		// the context of the client might have been cancelled along with ctx, e.g. by the timeout configured with WithTimeout
+		cancelCtx, cancel := context.WithTimeout(context.Background(), searchJobCancelTimeout)
+		defer cancel()
+		if err := job.CancelContext(cancelCtx); err != nil {
+			aa.Log("WARN", "Cannot cancel search job. %s", err.Error())
+		}
+	}()
+	if err := job.Wait(ctx); err != nil {
+		return err
+	}
+	return fn(job)
+}
@@ -0,0 +1,135 @@
+package alertactions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/splunkd"
+)
+
+// fakeSearchServer emulates the search job endpoints of splunkd. Jobs complete at the second status poll
+type fakeSearchServer struct {
+	mu         sync.Mutex
+	rows       int
+	polls      int
+	searches   []url.Values
+	cancelled  bool
+	maxPageLen int
+}
+
+func (fs *fakeSearchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	const jobsPath = "/servicesNS/admin/search/search/jobs"
+	switch {
+	case r.URL.Path == "/services/authentication/current-context":
+		fmt.Fprint(w, `{"entry":[{"name":"context","content":{"username":"admin"}}]}`)
+	case r.URL.Path == jobsPath && r.Method == "POST":
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		fs.searches = append(fs.searches, form)
+		fmt.Fprint(w, `{"sid":"1700000000.1"}`)
+	case r.URL.Path == jobsPath+"/1700000000.1":
+		fs.polls++
+		fmt.Fprintf(w, `{"entry":[{"name":"1700000000.1","content":{"sid":"1700000000.1","isDone":%t}}]}`, fs.polls%2 == 0)
+	case r.URL.Path == jobsPath+"/1700000000.1/results":
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+		if count > fs.maxPageLen {
+			fs.maxPageLen = count
+		}
+		results := make([]map[string]string, 0)
+		if strings.HasSuffix(fs.searches[len(fs.searches)-1].Get("search"), "| stats count") {
+			results = append(results, map[string]string{"count": strconv.Itoa(fs.rows)})
+		} else {
+			for i := offset; i < fs.rows && i < offset+count; i++ {
+				results = append(results, map[string]string{"host": fmt.Sprintf("host%d", i)})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	case r.URL.Path == jobsPath+"/1700000000.1/control":
+		fs.cancelled = true
+		fmt.Fprint(w, `{"messages":[]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRunSearch(t *testing.T) {
+	fs := &fakeSearchServer{rows: 2500}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	ss, err := splunkd.New(srv.URL, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ss.LoginWithSessionKey("abc"); err != nil {
+		t.Fatal(err)
+	}
+	ss.SetNamespace("admin", "search", splunkd.SplunkSharingGlobal)
+	aa, _ := New("test-search", "Test search", "", "")
+	aa.stderr = new(strings.Builder)
+	aa.splunkd = ss
+
+	results, err := aa.RunSearch(context.Background(), "index=main sourcetype=access", "-24h", "now")
+	if err != nil {
+		t.Fatalf("RunSearch returned an error: %s", err.Error())
+	}
+	if len(results) != 2500 || results[2499]["host"] != "host2499" {
+		t.Errorf("RunSearch did not retrieve all the results. got=%d", len(results))
+	}
+	if fs.maxPageLen >= 2500 {
+		t.Errorf("results were not paginated. page length=%d", fs.maxPageLen)
+	}
+	if fs.polls < 2 || !fs.cancelled {
+		t.Errorf("job was not polled until done and then cancelled. polls=%d cancelled=%t", fs.polls, fs.cancelled)
+	}
+	job := fs.searches[0]
+	if job.Get("search") != "search index=main sourcetype=access" || job.Get("earliest_time") != "-24h" || job.Get("latest_time") != "now" || job.Get("adhoc_search_level") != "fast" || job.Get("max_count") != "1000000" {
+		t.Errorf("wrong search job created: %v", job)
+	}
+
+	count, err := aa.RunSearchCount(context.Background(), "index=main", "", "")
+	if err != nil || count != 2500 {
+		t.Errorf("RunSearchCount returned wrong results. count=%d err=%v", count, err)
+	}
+	if _, found := fs.searches[1]["earliest_time"]; found {
+		t.Errorf("empty earliest time was provided to splunkd: %v", fs.searches[1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fs.cancelled = false
+	if _, err := aa.RunSearch(ctx, "index=main", "", ""); err == nil || !fs.cancelled {
+		t.Errorf("RunSearch did not cancel the job when the context got cancelled. err=%v", err)
+	}
+
+	// the context of the client expires along with the one of the search, as with WithTimeout: the job must still be cancelled
+	if err := aa.SetSearchMaxCount(50); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ss.SetContext(ctx)
+	fs.polls = 0
+	fs.cancelled = false
+	if _, err := aa.RunSearch(ctx, "index=main", "", ""); err == nil || !fs.cancelled {
+		t.Errorf("RunSearch did not cancel the job after the context of the client expired. err=%v", err)
+	}
+	if got := fs.searches[len(fs.searches)-1].Get("max_count"); got != "50" {
+		t.Errorf("SetSearchMaxCount was not applied. max_count=%s", got)
+	}
+	if err := aa.SetSearchMaxCount(0); err == nil {
+		t.Error("SetSearchMaxCount accepted 0")
+	}
+}
@@ -0,0 +1,83 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// RegisterShutdownFunc registers a cleanup function executed when the execution of the alert action ends,
+// whether it succeeded, failed or was interrupted by SIGTERM or SIGINT.
+// Functions are executed in reverse order of registration, same as deferred calls.
+func (aa *AlertAction) RegisterShutdownFunc(f func()) {
+	if f == nil {
+		return
+	}
+	aa.shutdownFuncs = append(aa.shutdownFuncs, f)
+}
+
+// SetShutdownTimeout configures how long Run waits for the alerting function to return once SIGTERM or SIGINT has been received.
+// When the timeout expires, Run executes the shutdown functions and returns an error without waiting any longer.
+// If d is 0 (the default), Run waits until the alerting function returns.
+func (aa *AlertAction) SetShutdownTimeout(d time.Duration) error {
+	if d < 0 {
+		return utils.NewErrInvalidParam("setShutdownTimeout", nil, "'d' cannot be negative")
+	}
+	aa.shutdownTimeout = d
+	return nil
+}
+
+// alertFuncGracePeriod is how long runAlertFunc still waits for the alerting function to return once the maximum execution time is exceeded,
+// when no shutdown timeout has been configured.
+const alertFuncGracePeriod = 250 * time.Millisecond
+
+// runAlertFunc executes the registered alerting function and waits for it to return.
+// If ctx is cancelled and a shutdown timeout has been configured, the function is given that much time to return before an error is returned.
+// If the deadline of ctx is exceeded and no shutdown timeout has been configured, the function is given alertFuncGracePeriod to return.
+// When runAlertFunc returns before the alerting function, the latter is abandoned: it keeps running in its own goroutine until
+// the process exits, and must not rely on aa anymore. Alerting functions should therefore always respect the cancellation of ctx.
+func (aa *AlertAction) runAlertFunc(ctx context.Context) error {
+	// the function is read here, so that the goroutine does not access aa once abandoned
+	execute := aa.execute
+	done := make(chan error, 1)
+	go func() { done <- callAlertFunc(ctx, aa, execute) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	wait := aa.shutdownTimeout
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && wait <= 0 {
+		// the maximum execution time has been reached: do not wait indefinitely for a function ignoring ctx
+		wait = alertFuncGracePeriod
+	}
+	if wait <= 0 {
+		return <-done
+	}
+	aa.Log("WARN", "Execution cancelled, waiting up to timeout_s=%.03f for the alerting function to return", wait.Seconds())
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(wait):
+		aa.Log("WARN", "Alerting function did not return within timeout_s=%.03f, abandoning it", wait.Seconds())
+		return fmt.Errorf("alerting function did not return within %s: %w", wait, ctx.Err())
+	}
+}
+
+// runShutdownFuncs executes the registered shutdown functions in reverse order of registration.
+// A panicking function is logged and does not prevent the execution of the others.
+func (aa *AlertAction) runShutdownFuncs() {
+	for i := len(aa.shutdownFuncs) - 1; i >= 0; i-- {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					aa.Log("ERROR", "Shutdown function panicked: %v", r)
+				}
+			}()
+			aa.shutdownFuncs[i]()
+		}()
+	}
+}
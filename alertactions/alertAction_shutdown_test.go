@@ -0,0 +1,76 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownFuncs(t *testing.T) {
+	aa, _ := New("test-shutdown", "Test shutdown", "", "")
+	aa.stderr = new(strings.Builder)
+
+	var calls []string
+	aa.RegisterShutdownFunc(func() { calls = append(calls, "first") })
+	aa.RegisterShutdownFunc(func() { panic("cleanup failed") })
+	aa.RegisterShutdownFunc(func() { calls = append(calls, "last") })
+
+	// the alerting function is interrupted while waiting on a slow operation
+	started := make(chan struct{})
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, ctx context.Context) error {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if err := aa.runAlertFunc(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("runAlertFunc did not return the cancellation error: %v", err)
+	}
+	aa.runShutdownFuncs()
+	if strings.Join(calls, ",") != "last,first" {
+		t.Errorf("shutdown functions were not executed in LIFO order: %v", calls)
+	}
+
+	// an alerting function ignoring the context is abandoned after the shutdown timeout
+	block := make(chan struct{})
+	defer close(block)
+	aa.RegisterAlertFunc(func(aa *AlertAction) error {
+		<-block
+		return nil
+	})
+	if err := aa.SetShutdownTimeout(-time.Second); err == nil {
+		t.Error("SetShutdownTimeout accepted a negative timeout")
+	}
+	if err := aa.SetShutdownTimeout(20 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := aa.runAlertFunc(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("runAlertFunc did not fail after the shutdown timeout: %v", err)
+	}
+
+	// once the deadline is exceeded, a function which returns shortly afterwards is waited for, even without shutdown timeout
+	aa.SetShutdownTimeout(0)
+	late := errors.New("late")
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(alertFuncGracePeriod / 5)
+		return late
+	})
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := aa.runAlertFunc(ctx); !errors.Is(err, late) {
+		t.Errorf("runAlertFunc did not wait for the alerting function within the grace period: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package alertactions
+
+/*
+This file makes AlertAction usable as a log/slog Handler, so that alerting functions can use the standard structured logging API:
+
+	slog.New(aa).Info("event processed", "count", n)
+*/
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// make sure AlertAction satisfies the slog.Handler interface
+var _ slog.Handler = (*AlertAction)(nil)
+
+// slogHandler is the slog.Handler returned by WithAttrs and WithGroup: it keeps track of the pre-configured
+// attributes and groups, and routes the records to the Log method of the underlying AlertAction
+type slogHandler struct {
+	aa *AlertAction
+	// attributes pre-formatted as key=value pairs
+	attrs []string
+	// group prefix for the keys of attributes added from now on, such as "group1.group2."
+	groupPrefix string
+}
+
+// Enabled reports whether records with the given level are logged. DEBUG records are only logged if debug mode is enabled
+func (aa *AlertAction) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || aa.debug
+}
+
+// Handle writes the slog record through [AlertAction.Log], appending its attributes as key=value pairs to the message
+func (aa *AlertAction) Handle(ctx context.Context, r slog.Record) error {
+	return (&slogHandler{aa: aa}).Handle(ctx, r)
+}
+
+// WithAttrs returns a slog.Handler which includes the provided attributes within every message
+func (aa *AlertAction) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return (&slogHandler{aa: aa}).WithAttrs(attrs)
+}
+
+// WithGroup returns a slog.Handler which qualifies the keys of subsequent attributes with the name of the group
+func (aa *AlertAction) WithGroup(name string) slog.Handler {
+	return (&slogHandler{aa: aa}).WithGroup(name)
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.aa.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := new(strings.Builder)
+	buf.WriteString(r.Message)
+	for _, a := range h.attrs {
+		buf.WriteString(" ")
+		buf.WriteString(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		for _, s := range formatSlogAttr(h.groupPrefix, a) {
+			buf.WriteString(" ")
+			buf.WriteString(s)
+		}
+		return true
+	})
+	// the message is passed as argument, since it might contain formatting markers
+	h.aa.Log(slogLevelToString(r.Level), "%s", buf.String())
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := &slogHandler{aa: h.aa, groupPrefix: h.groupPrefix}
+	nh.attrs = append(nh.attrs, h.attrs...)
+	for _, a := range attrs {
+		nh.attrs = append(nh.attrs, formatSlogAttr(h.groupPrefix, a)...)
+	}
+	return nh
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{aa: h.aa, attrs: h.attrs, groupPrefix: h.groupPrefix + name + "."}
+}
+
+// slogLevelToString maps slog levels to the levels supported by [AlertAction.Log]
+func slogLevelToString(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARN"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// formatSlogAttr returns the key=value representation of attribute a. Groups are flattened using dotted keys.
+// Values containing spaces, quotes or equal signs are quoted.
+func formatSlogAttr(prefix string, a slog.Attr) []string {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return nil
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		res := make([]string, 0)
+		for _, ga := range a.Value.Group() {
+			res = append(res, formatSlogAttr(prefix, ga)...)
+		}
+		return res
+	}
+	val := a.Value.String()
+	if val == "" || strings.ContainsAny(val, " \t\n\"=") {
+		val = fmt.Sprintf("%q", val)
+	}
+	return []string{prefix + a.Key + "=" + val}
+}
@@ -0,0 +1,37 @@
+package alertactions
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	aa, _ := New("test-slog", "Test slog", "", "")
+
+	if aa.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("DEBUG records enabled without debug mode")
+	}
+	if !aa.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("WARN records not enabled")
+	}
+	if lvl := slogLevelToString(slog.LevelWarn + 1); lvl != "WARN" {
+		t.Errorf("wrong level mapping. expected=WARN got=%s", lvl)
+	}
+
+	r, w, _ := os.Pipe()
+	origStderr := os.Stderr
+	os.Stderr = w
+	slog.New(aa).With("search", "my search").WithGroup("res").Info("event processed", "count", 3, "pct", "50%")
+	os.Stderr = origStderr
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	expected := `INFO - event processed search="my search" res.count=3 res.pct=50%`
+	if !strings.Contains(string(out), expected) {
+		t.Errorf("slog record not logged as expected.\nexpected to contain: %s\ngot: %s", expected, out)
+	}
+}
@@ -0,0 +1,63 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrExecutionTimeout is returned by Run when the alerting function does not complete within the time configured with WithTimeout
+var ErrExecutionTimeout = errors.New("execution timeout exceeded")
+
+// WithTimeout limits the execution time of the alerting function to d, without relying on splunk's 'maxtime' setting.
+// The context provided to the alerting function is cancelled once d has elapsed, and Run returns an error wrapping ErrExecutionTimeout.
+// A WARN message is logged when 80% of d has elapsed. Initialization and validation of the parameters are not subject to the timeout.
+// If d <= 0, no limit is applied.
+func (aa *AlertAction) WithTimeout(d time.Duration) *AlertAction {
+	if d < 0 {
+		d = 0
+	}
+	aa.maxExecutionTime = d
+	return aa
+}
+
+// SetMaxExecutionTime is equivalent to WithTimeout
+func (aa *AlertAction) SetMaxExecutionTime(d time.Duration) {
+	aa.WithTimeout(d)
+}
+
+// runAlertFuncWithTimeout executes the alerting function applying the maximum execution time, if configured.
+// The context of the splunkd client is limited as well, so that in-flight requests are aborted.
+func (aa *AlertAction) runAlertFuncWithTimeout(ctx context.Context) error {
+	d := aa.maxExecutionTime
+	if d <= 0 {
+		return aa.runAlertFunc(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	if aa.splunkd != nil {
+		// the post-hooks, shutdown functions and final logs must still be able to reach splunkd once the timeout expired
+		prevCtx := aa.splunkd.GetContext()
+		aa.splunkd.SetContext(ctx)
+		defer aa.splunkd.SetContext(prevCtx)
+	}
+	start := time.Now()
+	warned := make(chan struct{})
+	warning := time.AfterFunc(d*8/10, func() {
+		defer close(warned)
+		aa.Log("WARN", "Execution is about to time out. elapsed_s=%.03f timeout_s=%.03f", time.Since(start).Seconds(), d.Seconds())
+	})
+	defer func() {
+		// if the warning is already being logged, wait for it, so that nothing is logged from the timer after returning
+		if !warning.Stop() {
+			<-warned
+		}
+	}()
+
+	err := aa.runAlertFunc(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("alerting function did not complete within %s: %w. %w", d, ErrExecutionTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package alertactions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/splunkd"
+)
+
+func TestWithTimeout(t *testing.T) {
+	aa, _ := New("test-timeout", "Test timeout", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+
+	// a slow alerting function ignoring the context is abandoned when the timeout expires
+	block := make(chan struct{})
+	defer close(block)
+	aa.RegisterAlertFunc(func(aa *AlertAction) error {
+		<-block
+		return nil
+	})
+	timeout := 100 * time.Millisecond
+	aa.WithTimeout(timeout)
+	start := time.Now()
+	err := aa.runAlertFuncWithTimeout(context.Background())
+	elapsed := time.Since(start)
+	if !errors.Is(err, ErrExecutionTimeout) {
+		t.Fatalf("expected ErrExecutionTimeout, got: %v", err)
+	}
+	if elapsed < timeout || elapsed > timeout+time.Second {
+		t.Errorf("timeout error returned after %s instead of %s", elapsed, timeout)
+	}
+	if !strings.Contains(stderr.String(), "WARN") || !strings.Contains(stderr.String(), "Execution is about to time out") {
+		t.Errorf("no warning logged before the timeout: %s", stderr.String())
+	}
+
+	// a function respecting the context returns its own error, wrapped within ErrExecutionTimeout
+	aa.SetMaxExecutionTime(50 * time.Millisecond)
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := aa.runAlertFuncWithTimeout(context.Background()); !errors.Is(err, ErrExecutionTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrExecutionTimeout wrapping the error of the function, got: %v", err)
+	}
+
+	// fast functions are not affected
+	stderr.Reset()
+	aa.WithTimeout(time.Second).RegisterAlertFunc(func(aa *AlertAction) error { return nil })
+	if err := aa.runAlertFuncWithTimeout(context.Background()); err != nil {
+		t.Errorf("fast alerting function failed: %v", err)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected logs for a fast alerting function: %s", stderr.String())
+	}
+
+	// no limit
+	aa.WithTimeout(-time.Second)
+	if aa.maxExecutionTime != 0 {
+		t.Errorf("negative timeout was not converted into no limit: %s", aa.maxExecutionTime)
+	}
+}
+
+func TestWithTimeoutRestoresContext(t *testing.T) {
+	aa, _ := New("test-timeout", "Test timeout", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ss, err := splunkd.New(srv.URL, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type ctxKey struct{}
+	parent := context.WithValue(context.Background(), ctxKey{}, "parent")
+	ss.SetContext(parent)
+	aa.splunkd = ss
+
+	// the alerting function logs while the timeout warning is being logged
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, ctx context.Context) error {
+		for ctx.Err() == nil {
+			aa.Log("INFO", "still working")
+			time.Sleep(time.Millisecond)
+		}
+		if aa.splunkd.GetContext().Err() == nil {
+			t.Error("the context of the splunkd client was not limited by the timeout")
+		}
+		return ctx.Err()
+	})
+	aa.WithTimeout(30 * time.Millisecond)
+	if err := aa.runAlertFuncWithTimeout(parent); !errors.Is(err, ErrExecutionTimeout) {
+		t.Fatalf("expected ErrExecutionTimeout, got: %v", err)
+	}
+	if ctx := ss.GetContext(); ctx != parent || ctx.Err() != nil {
+		t.Errorf("the context of the splunkd client was not restored after the timeout. err=%v", ctx.Err())
+	}
+	if !strings.Contains(stderr.String(), "Execution is about to time out") || !strings.Contains(stderr.String(), "still working") {
+		t.Errorf("missing logs: %s", stderr.String())
+	}
+}
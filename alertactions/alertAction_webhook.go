@@ -0,0 +1,77 @@
+package alertactions
+
+/*
+This file contains utility methods for the AlertAction struct to forward the search results to external webhooks
+*/
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// webhookTimeout is the timeout of the requests performed by PostResultsToURL
+const webhookTimeout = 30 * time.Second
+
+// readAllResults loads all the rows of the results file in memory, as maps having the CSV header fields as keys
+func (aa *AlertAction) readAllResults() ([]map[string]string, error) {
+	rows := make([]map[string]string, 0)
+	err := aa.IterateResults(func(row map[string]string) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// PostResultsToURL loads all the result rows, invokes transformFn to produce the body of a HTTP request from them,
+// and sends the request to targetURL, with the provided method (POST if empty) and content-type.
+// The response is returned to the caller, who is responsible for closing its body.
+// If the response has a status code >= 400, both the response and an error are returned.
+func (aa *AlertAction) PostResultsToURL(ctx context.Context, targetURL, method, contentType string, transformFn func([]map[string]string) ([]byte, error)) (*http.Response, error) {
+	if targetURL == "" {
+		return nil, utils.NewErrInvalidParam("postResultsToURL", nil, "'targetURL' cannot be empty")
+	}
+	if transformFn == nil {
+		return nil, utils.NewErrInvalidParam("postResultsToURL", nil, "'transformFn' cannot be nil")
+	}
+	if method == "" {
+		method = http.MethodPost
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rows, err := aa.readAllResults()
+	if err != nil {
+		return nil, fmt.Errorf("postResultsToURL: %w", err)
+	}
+	body, err := transformFn(rows)
+	if err != nil {
+		return nil, fmt.Errorf("postResultsToURL: transformation of %d rows failed. %w", len(rows), err)
+	}
+	client, err := utils.NewHTTPClient(webhookTimeout, false, "", "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("postResultsToURL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("postResultsToURL: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	aa.Log("DEBUG", `Sending %d results to url="%s" method=%s bytes=%d`, len(rows), targetURL, method, len(body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("postResultsToURL: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return resp, fmt.Errorf("postResultsToURL: %s %s returned status %s", method, targetURL, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+package alertactions
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPostResultsToURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.csv.gz")
+	f, _ := os.Create(path)
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("host,count\nweb01,3\nweb02,5\n"))
+	gz.Close()
+	f.Close()
+
+	var gotBody, gotContentType, gotMethod string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotContentType, gotMethod = string(b), r.Header.Get("Content-Type"), r.Method
+	}))
+	defer srv.Close()
+
+	aa, _ := New("test-webhook", "Test webhook", "", "")
+	aa.runtimeConfig = &alertConfig{ResultsFile: path}
+	resp, err := aa.PostResultsToURL(context.Background(), srv.URL, "", "application/json", func(rows []map[string]string) ([]byte, error) {
+		return json.Marshal(rows)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if gotMethod != http.MethodPost || gotContentType != "application/json" {
+		t.Errorf("PostResultsToURL sent a wrong request. method=%s content-type=%s", gotMethod, gotContentType)
+	}
+	if expected := `[{"count":"3","host":"web01"},{"count":"5","host":"web02"}]`; gotBody != expected {
+		t.Errorf("PostResultsToURL sent a wrong body.\nexpected=%s\ngot=     %s", expected, gotBody)
+	}
+}
@@ -87,6 +87,28 @@ type alertConfig struct {
 	Result        map[string]interface{} `json:"result"`
 }
 
+// getAlertConfigFromFile reads the JSON-based run-time configuration from file path, as getAlertConfigFromJSON does for STDIN
+func getAlertConfigFromFile(path string) (*alertConfig, error) {
+	if path == "" {
+		return nil, fmt.Errorf("getAlertConfigFromFile: file path cannot be empty")
+	}
+	if fi, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("getAlertConfigFromFile: cannot access '%s'. %w", path, err)
+	} else if fi.IsDir() {
+		return nil, fmt.Errorf("getAlertConfigFromFile: '%s' is a directory", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("getAlertConfigFromFile: cannot read '%s'. %w", path, err)
+	}
+	defer f.Close()
+	ac, err := getAlertConfigFromJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("getAlertConfigFromFile: '%s': %w", path, err)
+	}
+	return ac, nil
+}
+
 // getAlertConfigFromJSON reads a JSON-formatted configuration from the provided Reader,
 // parses it and loads it within an alertConfig data structure
 func getAlertConfigFromJSON(input io.Reader) (*alertConfig, error) {
@@ -2,12 +2,20 @@ package alertactions
 
 import (
 	"compress/gzip"
+	"context"
+	"encoding/binary"
 	"encoding/csv"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,12 +29,52 @@ import (
 // this is used to modify the logging format
 var isAtTerminal = isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 
+// DefaultAvgBytesPerRow is the average size of a results row used by GetResultsRowCountEstimate when AlertAction.AvgBytesPerRow is not set
+const DefaultAvgBytesPerRow = 200
+
 // AlertingFunc is the signature required for the functions responsible for:
 //
 //  1. Validate the run-time parameters provided to the AlertAction.
 //  2. Execute the actual AlertAction based on configurations provided on STDIN
 type AlertingFunc func(*AlertAction) error
 
+// AlertingFuncWithContext is the signature of the alerting function receiving a context.
+// ctx is cancelled when the process receives SIGTERM or SIGINT, e.g. when Splunk kills the alert action: the function should then return as soon as possible.
+type AlertingFuncWithContext func(*AlertAction, context.Context) error
+
+// RunResult describes the outcome of an execution of the alert action, as returned by RunE
+type RunResult struct {
+	// Success is true if the execution completed without errors
+	Success bool
+	// Duration of the whole execution
+	Duration time.Duration
+	// RunID is the unique identifier of the execution, also used within the logs
+	RunID string
+	// ValidationPassed is true if the run-time parameters passed validation
+	ValidationPassed bool
+	// DryRun is true if the alerting function was not executed, as dry-run mode was active. See EnableDryRun
+	DryRun bool
+	// ErrorMessage contains the message of the error which interrupted the execution, if any
+	ErrorMessage string
+
+	start time.Time
+}
+
+// failed finalizes the result of a failed execution
+func (res *RunResult) failed(err error) (RunResult, error) {
+	res.Duration = time.Since(res.start)
+	res.Success = false
+	res.ErrorMessage = err.Error()
+	return *res, err
+}
+
+// succeeded finalizes the result of a successful execution
+func (res *RunResult) succeeded() (RunResult, error) {
+	res.Duration = time.Since(res.start)
+	res.Success = true
+	return *res, nil
+}
+
 // AlertAction is the main structure defining how an alert action looks like.
 // It provides a way for the user to define a Splunk alert action and makes
 // standardised methods available.
@@ -43,6 +91,9 @@ type AlertAction struct {
 	Documentation string
 	// IconPath is the name of a file within appserver/static/ to be used to represent this alert action
 	IconPath string
+	// AvgBytesPerRow is the average size of an uncompressed CSV row of the results, used by GetResultsRowCountEstimate.
+	// If <= 0, DefaultAvgBytesPerRow is used.
+	AvgBytesPerRow int
 	// params defines the acceptable parameters for the alert
 	params []*Param
 	// globalParams is used to track the global parameters necessary for the alert.
@@ -53,10 +104,37 @@ type AlertAction struct {
 	validateParams AlertingFunc
 
 	// Execute is a mandatory function used to perform actual alert tasks. This is called by the alert's "Run" method.
-	execute AlertingFunc
+	execute AlertingFuncWithContext
+
+	// shutdownFuncs are executed in reverse order when the execution ends. See RegisterShutdownFunc
+	shutdownFuncs []func()
+	// how long to wait for the alerting function to return after SIGTERM has been received. If 0, wait until it returns
+	shutdownTimeout time.Duration
+	// maximum duration of the execution of the alerting function. If 0, no limit is applied. See WithTimeout
+	maxExecutionTime time.Duration
+	// maximum number of results retained by the search jobs of RunSearch. If 0, defaultSearchMaxCount is used. See SetSearchMaxCount
+	searchMaxCount int
+
+	// preHooks and postHooks are executed before and after the alerting function. See UsePreHook and UsePostHook
+	preHooks  []AlertingFunc
+	postHooks []AlertingFunc
+	// lastError is the error of the execution of the alerting function, possibly replaced by the post-hooks
+	lastError error
+	// executionStart is when the execution of the pre-hooks started
+	executionStart time.Time
 
 	// This debug setting is meant for facilitating development and is not configurable by a user through splunk's inputs.conf
 	debug bool
+	// if dryRun is true, the run-time configuration is parsed and validated, but the alerting function is not executed. See EnableDryRun
+	dryRun bool
+
+	// uiFormat defines the style of the HTML generated with --get-ui-html. See SetUIFormat
+	uiFormat UIFormat
+	// appManifestConfig defines the settings of the app.manifest not derived from the alert action. See SetAppManifestConfig
+	appManifestConfig AppManifestConfig
+
+	// jsonOutput activates logging using JSON lines instead of plain text. Not used when running on a terminal.
+	jsonOutput bool
 
 	// Unique id of this run, generated when starting the "Run" function
 	runID string
@@ -74,12 +152,17 @@ type AlertAction struct {
 	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
+	// logMu serializes the writes of Log
+	logMu sync.Mutex
 }
 
 func New(stanzaName, label, description, iconPath string) (*AlertAction, error) {
 	if stanzaName == "" {
 		return nil, utils.NewErrInvalidParam("alertAction.New", nil, "'stanzaName' cannot be empty")
 	}
+	if !validStanzaName.MatchString(stanzaName) {
+		return nil, utils.NewErrInvalidParam("alertAction.New", nil, "'stanzaName' must be lowercase and only contain letters, digits and dashes '-'. provided: '%s', suggested: '%s'", stanzaName, NormalizeStanzaName(stanzaName))
+	}
 	if label == "" {
 		return nil, utils.NewErrInvalidParam("alertAction.New", nil, "'label' cannot be empty")
 	}
@@ -94,17 +177,64 @@ func New(stanzaName, label, description, iconPath string) (*AlertAction, error)
 	return aa, nil
 }
 
+// validStanzaName matches the names which Splunk accepts for alert actions
+var validStanzaName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NormalizeStanzaName returns a version of s which is acceptable as stanza name of an alert action:
+// lowercase, with spaces and underscores replaced by dashes and all other invalid characters removed.
+func NormalizeStanzaName(s string) string {
+	buf := new(strings.Builder)
+	buf.Grow(len(s))
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			buf.WriteRune(r)
+		case r == '-' || r == '_' || r == ' ':
+			buf.WriteRune('-')
+		}
+	}
+	// collapse repeated dashes and remove leading and trailing ones
+	parts := strings.FieldsFunc(buf.String(), func(r rune) bool { return r == '-' })
+	return strings.Join(parts, "-")
+}
+
 func (aa *AlertAction) EnableDebug() {
 	aa.debug = true
 }
 
+// EnableDryRun activates the dry-run mode: when executed, the alert action parses and validates its run-time configuration,
+// but neither the alerting function nor its hooks are executed. This is also activated by the --dry-run command-line parameter.
+// It is meant for verifying configurations during development and CI/CD pipelines: functions invoked outside of the alerting function,
+// such as the validation function, should check IsDryRun and skip any side effect, e.g. sending tickets or calling APIs.
+func (aa *AlertAction) EnableDryRun() {
+	aa.dryRun = true
+}
+
+// IsDryRun returns true if the dry-run mode is active. See EnableDryRun
+func (aa *AlertAction) IsDryRun() bool {
+	return aa.dryRun
+}
+
+// EnableJSONOutput configures the alert action to emit its logs as JSON lines, such as
+//
+//	{"timestamp":"...","level":"INFO","run_id":"...","message":"..."}
+//
+// This applies to both Log and LogForEndUser. When running interactively on a terminal, the text format is preserved.
+func (aa *AlertAction) EnableJSONOutput() {
+	aa.jsonOutput = true
+}
+
 // RegisterParam adds a given parameter to the alert action.
+// Parameters not bound to a stanza, such as the ones created with ParamBuilder, get the stanza of the alert action.
 func (aa *AlertAction) RegisterParam(p *Param) error {
 	// check if the parameter is already present
 	// return error in case it is already there
 	if _, err := aa.GetParam(p.Name); err == nil {
 		return utils.NewErrInvalidParam("registerParam", nil, "parameter with name '%s' already existing", p.Name)
 	}
+	if p.stanza == "" {
+		p.stanza = aa.StanzaName
+	}
 	if aa.params == nil {
 		aa.params = make([]*Param, 0, 1)
 	}
@@ -211,6 +341,34 @@ func (aa *AlertAction) GetFirstResult() map[string]interface{} {
 	return aa.runtimeConfig.Result
 }
 
+// GetFirstResultTime returns the '_time' field of the first of the search results as time.Time.
+// It returns an error if no runtime-configuration has been loaded or if the field is missing or cannot be parsed.
+func (aa *AlertAction) GetFirstResultTime() (time.Time, error) {
+	result := aa.GetFirstResult()
+	if result == nil {
+		return time.Time{}, fmt.Errorf("getFirstResultTime: no result available")
+	}
+	var epoch float64
+	var err error
+	switch t := result["_time"].(type) {
+	case float64:
+		epoch = t
+	case int:
+		epoch = float64(t)
+	case int64:
+		epoch = float64(t)
+	case string:
+		if epoch, err = strconv.ParseFloat(strings.TrimSpace(t), 64); err != nil {
+			return time.Time{}, fmt.Errorf("getFirstResultTime: cannot parse '_time' value '%s'. %w", t, err)
+		}
+	case nil:
+		return time.Time{}, fmt.Errorf("getFirstResultTime: field '_time' not found within first result")
+	default:
+		return time.Time{}, fmt.Errorf("getFirstResultTime: unsupported type %T for field '_time'", t)
+	}
+	return utils.GetTimeFromEpoch(epoch), nil
+}
+
 // GetSearchUri returns the URI of the search object on the spluknd service API
 func (aa *AlertAction) GetSearchUri() string {
 	if aa.runtimeConfig == nil {
@@ -256,6 +414,48 @@ func (aa *AlertAction) GetResultsFile() (*os.File, error) {
 	return os.Open(aa.runtimeConfig.ResultsFile)
 }
 
+// GetResultsFileSize returns the size in bytes of the (compressed) results file
+func (aa *AlertAction) GetResultsFileSize() (int64, error) {
+	if aa.runtimeConfig == nil {
+		aa.Log("ERROR", "GetResultsFileSize invoked without a runtime-configuration having being loaded.")
+		return 0, fmt.Errorf("missing runtime-configuration: impossible to locate the 'ResultsFile'")
+	}
+	fi, err := os.Stat(aa.runtimeConfig.ResultsFile)
+	if err != nil {
+		return 0, fmt.Errorf("getResultsFileSize: %w", err)
+	}
+	return fi.Size(), nil
+}
+
+// GetResultsRowCountEstimate returns a rough upper bound of the number of rows of the results, computed as the
+// uncompressed size of the results file divided by AvgBytesPerRow.
+// The uncompressed size is read from the gzip trailer, without decompressing the file: this is only accurate for results smaller than 4GB.
+// This is meant for alerting functions needing to bail out early when processing very large result sets.
+func (aa *AlertAction) GetResultsRowCountEstimate() (int, error) {
+	f, err := aa.GetResultsFile()
+	if err != nil {
+		return 0, fmt.Errorf("getResultsRowCountEstimate: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("getResultsRowCountEstimate: %w", err)
+	}
+	if fi.Size() < 4 {
+		return 0, fmt.Errorf("getResultsRowCountEstimate: results file '%s' is not a valid gzip file", fi.Name())
+	}
+	// the last 4 bytes of a gzip file contain the uncompressed size modulo 2^32, little-endian
+	trailer := make([]byte, 4)
+	if _, err = f.ReadAt(trailer, fi.Size()-4); err != nil {
+		return 0, fmt.Errorf("getResultsRowCountEstimate: %w", err)
+	}
+	avg := aa.AvgBytesPerRow
+	if avg <= 0 {
+		avg = DefaultAvgBytesPerRow
+	}
+	return int(binary.LittleEndian.Uint32(trailer)) / avg, nil
+}
+
 func (aa *AlertAction) GetResultsFileReader(f *os.File) (*csv.Reader, error) {
 	if f == nil {
 		aa.Log("ERROR", "GetResultsFileReader invoked without a proper file pointer")
@@ -293,6 +493,36 @@ func (aa *AlertAction) GetNamespace() (*splunkd.Namespace, error) {
 	return splunkd.NewNamespace(aa.runtimeConfig.Owner, aa.runtimeConfig.App, "")
 }
 
+// GetScheduledSearchDefinition retrieves from splunkd the definition of the saved search which triggered the alert action
+func (aa *AlertAction) GetScheduledSearchDefinition() (*splunkd.SavedSearchResource, error) {
+	if aa.runtimeConfig == nil {
+		return nil, fmt.Errorf("getScheduledSearchDefinition invoked without a runtime-configuration having being loaded")
+	}
+	if aa.GetSearchName() == "" {
+		return nil, fmt.Errorf("getScheduledSearchDefinition: the alert action has not been triggered by a saved search")
+	}
+	ss, err := aa.GetSplunkService()
+	if err != nil {
+		return nil, fmt.Errorf("getScheduledSearchDefinition: %w", err)
+	}
+	e, err := splunkd.NewSavedSearchesCollectionNS(ss, aa.GetOwner(), aa.GetApp()).Get(aa.GetSearchName())
+	if err != nil {
+		return nil, fmt.Errorf("getScheduledSearchDefinition: %w", err)
+	}
+	return &e.Content, nil
+}
+
+// GetIndexes returns the collection of the indexes available on splunkd, e.g. to validate a user-configured target index.
+// Returns nil, logging the error, if the splunkd client cannot be initialized.
+func (aa *AlertAction) GetIndexes() *splunkd.IndexesCollection {
+	ss, err := aa.GetSplunkService()
+	if err != nil {
+		aa.Log("ERROR", "GetIndexes: %s", err.Error())
+		return nil
+	}
+	return ss.GetIndexes()
+}
+
 // setSplunkService configures the splunkd client
 // Prerequisites to execution: a runtime configuration must be already available (aa.initRuntime()) when performing this method.
 // The client has already been authenticated using the sessionKey which Splunk provides when starting the alert.
@@ -415,11 +645,24 @@ func (aa *AlertAction) setParams() error {
 			if err := param.setValue(v); err != nil {
 				return fmt.Errorf("esetParams: rror while applying run-time configuration: %s", err.Error())
 			}
+			param.actualValueFromRuntime = true
 		} else {
-			aa.Log("DEBUG", "Parameter '%s' uses default value \"%s\"", param.Name, param.GetValue())
+			// the unresolved default value is logged, as resolving it could reveal a secret
+			loggedVal = param.GetDefaultValue()
+			if param.sensitive {
+				loggedVal = "***masked***"
+			}
+			aa.Log("DEBUG", "Parameter '%s' uses default value \"%s\"", param.Name, loggedVal)
 		}
 	}
-	return nil
+	// verify that all required parameters actually have a value
+	errs := &utils.MultiError{Context: "setParams"}
+	for _, param := range aa.params {
+		if param.IsRequired() && !param.HasSetValue() && param.GetDefaultValue() == "" {
+			errs.Add(fmt.Errorf("required parameter '%s' (%s) has no value within the run-time configuration and no default value", param.Name, param.Title))
+		}
+	}
+	return errs.ErrorOrNil()
 }
 
 // RegisterValidationFunc configures a function used to validate parameters.
@@ -433,6 +676,18 @@ func (aa *AlertAction) RegisterValidationFunc(f AlertingFunc) {
 // RegisterAlertFunc configures the actual alerting function to be executed by the alert action.
 // Not providing a function results in a run-time error, as the alert action would not know what to actually do.
 func (aa *AlertAction) RegisterAlertFunc(f AlertingFunc) {
+	if f == nil {
+		aa.RegisterAlertFuncWithContext(nil)
+		return
+	}
+	aa.RegisterAlertFuncWithContext(func(aa *AlertAction, _ context.Context) error {
+		return f(aa)
+	})
+}
+
+// RegisterAlertFuncWithContext configures the actual alerting function to be executed by the alert action.
+// The context provided to the function is cancelled when the process receives SIGTERM or SIGINT.
+func (aa *AlertAction) RegisterAlertFuncWithContext(f AlertingFuncWithContext) {
 	aa.Log("DEBUG", "Alerting function registered")
 	aa.execute = f
 }
@@ -449,8 +704,16 @@ func (aa *AlertAction) printHelp(f *flag.FlagSet) {
 
 // Run is the function responsible for actual execution of the alert action.
 func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	_, err := aa.RunE(args, stdin, stdout, stderr)
+	return err
+}
+
+// RunE behaves as Run, additionally returning a RunResult describing the outcome of the execution.
+// This is useful when the alert action is invoked as a library function instead of being started by Splunk.
+func (aa *AlertAction) RunE(args []string, stdin io.Reader, stdout, stderr io.Writer) (RunResult, error) {
 	var err error
 	var runTimeConfig *alertConfig
+	res := &RunResult{RunID: aa.runID, start: time.Now()}
 	// set interfaces to outside world
 	aa.stdin = stdin
 	aa.stdout = stdout
@@ -461,6 +724,9 @@ func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Wri
 	// defines the command-line parameters using the 'flag' module
 	executePtr := flags.Bool("execute", false, "Starts execution of the alert action. A JSON-based configuration must be provided via STDIN. This is what Splunk does.")
 	debugPtr := flags.Bool("debug", false, "Activates debug mode, useful only during development")
+	dryRunPtr := flags.Bool("dry-run", false, "Parses and validates the run-time configuration without executing the alerting function. To be used along with -execute or -execute-from-file.")
+	jsonOutputPtr := flags.Bool("json-output", false, "Emit logs as JSON lines instead of plain text. Ignored when running on a terminal.")
+	executeFromFilePtr := flags.String("execute-from-file", "", "Starts execution of the alert action, reading the JSON-based configuration from the specified file instead of STDIN. Useful for development and testing.")
 	interactivePtr := flags.Bool("interactive", false, "Interactively ask for parameter values and start a local execution. Useful for development and debugging only.")
 	getRunTimeConfPtr := flags.Bool("get-runtime-conf-example", false, fmt.Sprintf("Interactively ask for parameter values and generates a JSON-based configuration, as Splunk would send to your alert. You can use this as 'cat conf.json > %s -execute'.", args[0]))
 	getConfPtr := flags.Bool("get-alert-actions-conf", false, "Print out a template for default/alert_actions.conf")
@@ -470,34 +736,53 @@ func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Wri
 	getRestMapConfPtr := flags.Bool("get-rest-map-conf", false, "Print out a template for default/restmap.conf")
 	getSSSpecPtr := flags.Bool("get-saved-searches-spec", false, "Print out a template for README/savedsearches.conf.spec")
 	getDocuPtr := flags.Bool("get-documentation", false, "Print out markdown-formatted documentation for the alert")
+	getOpenAPIPtr := flags.Bool("get-openapi-spec", false, "Print out an OpenAPI 3.0 JSON specification describing the parameters of the alert")
 	getUIHTML := flags.Bool("get-ui-html", false, fmt.Sprintf("Print out a template for the UI configuration to be stored at default/data/ui/alerts/%s.html", aa.StanzaName))
 	if err := flags.Parse(args[1:]); err != nil {
-		return err
+		return res.failed(err)
 	}
 
 	if *debugPtr {
 		aa.EnableDebug()
 	}
+	if *dryRunPtr {
+		aa.EnableDryRun()
+	}
+	if *jsonOutputPtr {
+		aa.EnableJSONOutput()
+	}
 
-	if *executePtr || *interactivePtr {
+	if *executePtr || *executeFromFilePtr != "" || *interactivePtr {
 		start := time.Now()
+		// ctx is cancelled when Splunk stops the alert action
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+		defer stop()
+		// shutdown functions run whatever the outcome of the execution, before the signal handling is stopped
+		defer aa.runShutdownFuncs()
 
 		if aa.execute == nil {
 			aa.Log("FATAL", "No actual alerting function has been defined")
-			return fmt.Errorf("no actual alerting function has been defined")
+			return res.failed(fmt.Errorf("no actual alerting function has been defined"))
 		}
 
-		if *executePtr {
+		if *executeFromFilePtr != "" {
+			aa.Log("INFO", "Parsing run-time JSON configurations from file '%s'", *executeFromFilePtr)
+			runTimeConfig, err = getAlertConfigFromFile(*executeFromFilePtr)
+			if err != nil {
+				aa.Log("FATAL", "Parsing of run-time JSON configurations from file '%s' failed. %s", *executeFromFilePtr, err.Error())
+				return res.failed(err)
+			}
+		} else if *executePtr {
 			aa.Log("INFO", "Parsing run-time JSON configurations from STDIN")
 			runTimeConfig, err = getAlertConfigFromJSON(stdin)
 			if err != nil {
 				aa.Log("FATAL", "Parsing of run-time JSON configurations from STDIN failed. %s", err.Error())
-				return err
+				return res.failed(err)
 			}
 		} else if *interactivePtr {
 			if runTimeConfig, err = aa.getAlertConfigInteractive(); err != nil {
 				aa.Log("FATAL", "Error when preparing execution configuration: %s", err.Error())
-				return err
+				return res.failed(err)
 			}
 		}
 
@@ -505,7 +790,11 @@ func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Wri
 		// initRuntime is in charge of logging the "Execution started" message
 		if err = aa.initRuntime(runTimeConfig); err != nil {
 			aa.Log("FATAL", "Setting of run-time configurations failed. %s", err.Error())
-			return err
+			return res.failed(err)
+		}
+		// in-flight calls to splunkd are aborted as well when the execution is interrupted
+		if aa.splunkd != nil {
+			aa.splunkd.SetContext(ctx)
 		}
 
 		// Note: initRuntime() already performs validation of individual parameters.
@@ -515,17 +804,23 @@ func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Wri
 			aa.Log("INFO", "Validating run-time parameters with registered function")
 			if err = aa.validateParams(aa); err != nil {
 				aa.Log("FATAL", "Validation of run-time parameters failed. %s", err.Error())
-				return err
+				return res.failed(err)
 			}
 		}
+		res.ValidationPassed = true
+		if aa.dryRun {
+			aa.Log("INFO", "dry-run mode: skipping actual execution")
+			res.DryRun = true
+			return res.succeeded()
+		}
 		// At last, perform actual execution of the alerting function
 		aa.Log("INFO", "Executing alerting function")
-		if err = aa.execute(aa); err != nil {
+		if err = aa.runAlertFuncWithHooks(ctx); err != nil {
 			aa.Log("FATAL", "Execution failed. sid=\"%s\" duration_ms=%d. %s", aa.GetSid(), time.Since(start).Milliseconds(), err.Error())
-			return err
+			return res.failed(err)
 		}
 		aa.Log("INFO", "Execution succeeded. sid=\"%s\" duration_ms=%d", aa.GetSid(), time.Since(start).Milliseconds())
-		return nil
+		return res.succeeded()
 	}
 
 	var actionSelected bool
@@ -568,9 +863,17 @@ func (aa *AlertAction) Run(args []string, stdin io.Reader, stdout, stderr io.Wri
 		fmt.Println(aa.generateDocumentation())
 		actionSelected = true
 	}
+	if *getOpenAPIPtr {
+		spec, err := aa.GenerateOpenAPISpec()
+		if err != nil {
+			return res.failed(err)
+		}
+		fmt.Println(string(spec))
+		actionSelected = true
+	}
 	// if no valid command-line parameters were provided
 	if !actionSelected {
 		aa.printHelp(flags)
 	}
-	return nil
+	return res.succeeded()
 }
@@ -0,0 +1,321 @@
+package alertactions
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/splunkd"
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+func TestJSONLogLine(t *testing.T) {
+	aa, err := New("test-alert", "Test alert", "description", "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	line := aa.jsonLogLine(time.Now(), "INFO", "processed %d results for %s", 3, "someone")
+	if !strings.HasSuffix(line, "\n") {
+		t.Errorf("jsonLogLine did not terminate the line with a newline: '%s'", line)
+	}
+	l := logLine{}
+	if err = json.Unmarshal([]byte(line), &l); err != nil {
+		t.Errorf("jsonLogLine did not produce valid JSON: %s. '%s'", err.Error(), line)
+	}
+	if l.Level != "INFO" || l.RunID != aa.GetRunId() || l.Message != "processed 3 results for someone" || l.Timestamp == "" {
+		t.Errorf("jsonLogLine produced wrong contents: %+v", l)
+	}
+}
+
+func TestJSONLogToSplunkd(t *testing.T) {
+	if isAtTerminal {
+		t.Skip("JSON output is disabled when running on a terminal")
+	}
+	aa, _ := New("test-alert", "Test alert", "description", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ss, err := splunkd.New(srv.URL, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(strings.Builder)
+	ss.SetLoggerWriter(buf)
+	aa.splunkd = ss
+	aa.EnableJSONOutput()
+	if err := aa.registerLogger(); err != nil {
+		t.Fatal(err)
+	}
+	if err := aa.RegisterEndUserLogger("main", "myalert"); err != nil {
+		t.Fatal(err)
+	}
+	aa.Log("WARN", "processed %d results", 3)
+	aa.LogForEndUser("INFO", "notified %s", "someone")
+
+	messages := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		l := logLine{}
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			t.Errorf("log line written through the splunkd logger is not valid JSON: %s. '%s'", err.Error(), line)
+		}
+		messages[l.Message] = true
+	}
+	if !messages["processed 3 results"] || !messages["[myalert] notified someone"] {
+		t.Errorf("wrong contents of the log lines: %s", buf.String())
+	}
+}
+
+func TestRunEResult(t *testing.T) {
+	aa, err := New("test-alert", "Test alert", "description", "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	stderr := new(strings.Builder)
+	// no alerting function has been registered: execution must fail
+	res, err := aa.RunE([]string{"test-alert", "--execute"}, strings.NewReader("{}"), new(strings.Builder), stderr)
+	if err == nil {
+		t.Errorf("RunE did not return an error when no alerting function was registered")
+	}
+	if res.Success || res.ValidationPassed || res.ErrorMessage == "" {
+		t.Errorf("RunE returned a wrong result for a failed execution: %+v", res)
+	}
+	if res.RunID != aa.GetRunId() {
+		t.Errorf("RunE returned wrong RunID. expected=%s got=%s", aa.GetRunId(), res.RunID)
+	}
+}
+
+func TestGetFirstResultTime(t *testing.T) {
+	aa, err := New("test-alert", "Test alert", "description", "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	expected := time.UnixMilli(1700000000123)
+	for _, v := range []interface{}{"1700000000.123", 1700000000.123} {
+		aa.runtimeConfig = &alertConfig{Result: map[string]interface{}{"_time": v}}
+		got, err := aa.GetFirstResultTime()
+		if err != nil {
+			t.Errorf("GetFirstResultTime returned an error for _time=%v: %s", v, err.Error())
+		} else if !got.Equal(expected) {
+			t.Errorf("GetFirstResultTime returned a wrong time for _time=%v. expected=%s got=%s", v, expected, got)
+		}
+	}
+	aa.runtimeConfig = &alertConfig{Result: map[string]interface{}{"host": "myhost"}}
+	if _, err = aa.GetFirstResultTime(); err == nil {
+		t.Errorf("GetFirstResultTime did not return an error when _time is missing")
+	}
+}
+
+func TestNewStanzaNameValidation(t *testing.T) {
+	for _, name := range []string{"Test-Alert", "test alert", "test_alert", "test-", "-test", "tést"} {
+		if _, err := New(name, "Test alert", "description", ""); err == nil {
+			t.Errorf("New did not return an error for invalid stanza name '%s'", name)
+		}
+	}
+	for _, name := range []string{"test", "test-alert", "test-alert-2"} {
+		if _, err := New(name, "Test alert", "description", ""); err != nil {
+			t.Errorf("New returned an error for valid stanza name '%s': %s", name, err.Error())
+		}
+	}
+	cases := map[string]string{
+		"Test Alert":   "test-alert",
+		"my_alert--2":  "my-alert-2",
+		" helloWorld ": "helloworld",
+		"a!b@c":        "abc",
+	}
+	for in, expected := range cases {
+		if got := NormalizeStanzaName(in); got != expected {
+			t.Errorf("NormalizeStanzaName('%s') returned wrong value. expected='%s' got='%s'", in, expected, got)
+		}
+	}
+}
+
+func TestGenerateOpenAPISpec(t *testing.T) {
+	aa, err := New("test-alert", "Test alert", "description", "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	p, _ := aa.RegisterNewParam("mode", "Mode", "Execution mode", "fast", "", ParamTypeDropdown, true)
+	p.AddChoice("fast", "Fast")
+	p.AddChoice("slow", "Slow")
+
+	specJSON, err := aa.GenerateOpenAPISpec()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	spec := struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Post struct {
+				RequestBody struct {
+					Content map[string]struct {
+						Schema struct {
+							Required   []string `json:"required"`
+							Properties map[string]struct {
+								Enum []string `json:"enum"`
+							} `json:"properties"`
+						} `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+			} `json:"post"`
+		} `json:"paths"`
+	}{}
+	if err = json.Unmarshal(specJSON, &spec); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	schema := spec.Paths["/test-alert"].Post.RequestBody.Content["application/json"].Schema
+	if !strings.HasPrefix(spec.OpenAPI, "3.0") || len(schema.Required) != 1 || len(schema.Properties["mode"].Enum) != 2 {
+		t.Errorf("GenerateOpenAPISpec produced a wrong specification: %s", specJSON)
+	}
+}
+
+func TestGetAlertConfigFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample_config.json")
+	conf := `{"app":"search","owner":"admin","sid":"scheduler_123","search_name":"my search","configuration":{"param1":"value1"}}`
+	if err := os.WriteFile(path, []byte(conf), 0600); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	ac, err := getAlertConfigFromFile(path)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if ac.App != "search" || ac.Sid != "scheduler_123" || ac.Configuration["param1"] != "value1" {
+		t.Errorf("getAlertConfigFromFile loaded a wrong configuration: %+v", ac)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if _, err = getAlertConfigFromFile(missing); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("getAlertConfigFromFile did not return an error mentioning the missing file. got=%v", err)
+	}
+}
+
+func TestGetResultsFileSize(t *testing.T) {
+	aa, _ := New("test-results-size", "Test results size", "", "")
+	path := filepath.Join(t.TempDir(), "results.csv.gz")
+	f, _ := os.Create(path)
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(strings.Repeat("0123456789", 100)))
+	gz.Close()
+	f.Close()
+	fi, _ := os.Stat(path)
+
+	aa.runtimeConfig = &alertConfig{ResultsFile: path}
+	size, err := aa.GetResultsFileSize()
+	if err != nil || size != fi.Size() {
+		t.Errorf("GetResultsFileSize returned wrong size. expected=%d got=%d err=%v", fi.Size(), size, err)
+	}
+	aa.AvgBytesPerRow = 10
+	if rows, err := aa.GetResultsRowCountEstimate(); err != nil || rows != 100 {
+		t.Errorf("GetResultsRowCountEstimate returned wrong estimate. expected=100 got=%d err=%v", rows, err)
+	}
+	aa.runtimeConfig.ResultsFile = filepath.Join(t.TempDir(), "missing.csv.gz")
+	if _, err := aa.GetResultsFileSize(); err == nil {
+		t.Error("GetResultsFileSize did not fail on a missing file")
+	}
+}
+
+func TestSetUIFormat(t *testing.T) {
+	aa, _ := New("test-ui-format", "Test UI format", "", "")
+	if err := aa.SetUIFormat(UIFormat(42)); err == nil {
+		t.Error("SetUIFormat accepted an invalid format")
+	}
+	if h := aa.generateUIHTML(); !strings.Contains(h, "<splunk-control-group label=\"Instructions\">") {
+		t.Errorf("generateUIHTML does not use the classic format by default: %s", h)
+	}
+	aa.SetUIFormat(UIFormatModern)
+	if h := aa.generateUIHTML(); strings.Contains(h, "<splunk-control-group") {
+		t.Errorf("generateUIHTML did not use the modern format: %s", h)
+	}
+}
+
+func TestSetParamsRequired(t *testing.T) {
+	aa, _ := New("test-required", "Test required", "", "")
+	aa.params = []*Param{
+		{Name: "channel", Title: "Channel", required: true},
+		{Name: "message", Title: "Message", required: true},
+		{Name: "color", Title: "Color", required: true, defaultValue: "red"},
+		{Name: "note", Title: "Note"},
+	}
+	aa.runtimeConfig = &alertConfig{Configuration: map[string]string{"channel": "#general"}}
+	err := aa.setParams()
+	var multiErr *utils.MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 1 || !strings.Contains(err.Error(), "'message'") {
+		t.Errorf("setParams did not report the missing required parameter: %v", err)
+	}
+	aa.runtimeConfig.Configuration["message"] = "hello"
+	if err = aa.setParams(); err != nil {
+		t.Errorf("setParams failed although all required parameters have a value: %v", err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	// after the initialization of the run-time, logs are sent to splunkd
+	var logsMu sync.Mutex
+	logs := new(strings.Builder)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/authentication/current-context" {
+			fmt.Fprint(w, `{"entry":[{"name":"context","content":{"username":"admin"}}]}`)
+			return
+		}
+		logsMu.Lock()
+		io.Copy(logs, r.Body)
+		logsMu.Unlock()
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+	conf := fmt.Sprintf(`{"app":"search","owner":"admin","server_uri":"%s","session_key":"abc","configuration":{}}`, srv.URL)
+
+	for _, args := range [][]string{{"test-alert", "--execute", "--dry-run"}, {"test-alert", "--execute"}} {
+		aa, _ := New("test-alert", "Test alert", "", "")
+		executed, validated := false, false
+		aa.RegisterAlertFunc(func(aa *AlertAction) error {
+			executed = true
+			return nil
+		})
+		aa.RegisterValidationFunc(func(aa *AlertAction) error {
+			validated = true
+			return nil
+		})
+		dryRun := utils.In("--dry-run", args)
+		if !dryRun {
+			// dry-run mode can also be activated programmatically
+			aa.EnableDryRun()
+		}
+		logsMu.Lock()
+		logs.Reset()
+		logsMu.Unlock()
+		stderr := new(strings.Builder)
+		res, err := aa.RunE(args, strings.NewReader(conf), new(strings.Builder), stderr)
+		if err != nil {
+			t.Fatalf("RunE returned an error: %s\n%s", err.Error(), stderr.String())
+		}
+		if !aa.IsDryRun() || executed || !validated || !res.DryRun || !res.ValidationPassed {
+			t.Errorf("wrong dry-run execution. args=%v executed=%t validated=%t result=%+v", args, executed, validated, res)
+		}
+		logsMu.Lock()
+		if !strings.Contains(logs.String(), "dry-run mode: skipping actual execution") {
+			t.Errorf("dry-run was not logged: %s", logs.String())
+		}
+		logsMu.Unlock()
+	}
+	aa, _ := New("test-alert", "Test alert", "", "")
+	if !strings.Contains(aa.generateAlertActionsConf(), "--dry-run") {
+		t.Error("alert_actions.conf does not document the dry-run mode")
+	}
+}
@@ -18,6 +18,18 @@ This type can also represent global paramenters, whose value is defined in some
 
  6. The Run() method parses run-time configurations, reads-in the values of the global parameters and invokes the provided alerting function.
 
+Parameters are best created with [NewParamBuilder], which avoids the many positional arguments of RegisterNewParam:
+
+	channel, err := alertactions.NewParamBuilder("channel").
+		Title("Channel").
+		Description("Channel to be notified").
+		Default("#alerts").
+		Required().
+		Build()
+	if err == nil {
+		aa.RegisterParam(channel)
+	}
+
 The following is an example of the run-time configuration sent by Splunk:
 
 	{
@@ -71,13 +71,34 @@ type Param struct {
 	// availableOptions is a slice of admissible choices for the values of this parameter
 	// intended to be used to represent parameters of type dropdown and radio
 	availableOptions []paramOption
+	// searchQuery is the SPL query populating the choices of parameters of type ParamTypeSearchDropdown
+	searchQuery string
 	// actualValue is the actual value for the parameter provided by run-time configurations
 	actualValue string
 	// actualValueIsSet tracks whether a value for the parameter has been actually set.
 	// if false, the DefaultValue will be returned when asking for the parameter's value
 	actualValueIsSet bool
+	// actualValueFromRuntime tracks whether the actual value comes from the run-time configuration provided by splunk,
+	// which can be edited by any author of an alert. Such values are never read from files
+	actualValueFromRuntime bool
+	// allowFileValue enables reading values with format file://<path> from files. See AllowFileValue
+	allowFileValue bool
+	// secretResolver is used to resolve the values of sensitive parameters referencing an external secret store
+	secretResolver SecretResolver
+	// envVarOverride is the name of the environment variable which, if set, overrides the value of the parameter. See SetEnvVarOverride
+	envVarOverride string
 }
 
+// SecretResolver retrieves a secret from an external secret store, such as HashiCorp Vault or AWS Secrets Manager.
+// path is the reference to the secret, without the scheme prefix (e.g. "vault://")
+type SecretResolver func(path string) (string, error)
+
+// fileScheme is the prefix of values to be read from a file, see GetValueFromFile
+const fileScheme = "file://"
+
+// secretSchemes lists the prefixes identifying values which must be resolved through a SecretResolver
+var secretSchemes = []string{"vault://", "aws-secretsmanager://"}
+
 // NewGlobalParam instantiates a global parameter, whose value will be read from splunk's configuration file
 // when starting up the alert action.
 func NewGlobalParam(configFile, stanza, name, title, description, defaultValue string, required bool) (*Param, error) {
@@ -151,6 +172,20 @@ func (p *Param) AddChoice(value, visibleValue string) error {
 	return nil
 }
 
+// SetSearchDropdownQuery configures the Splunk search providing the choices of a parameter of type ParamTypeSearchDropdown.
+// The function returns an error if the parameter has a different uiType or if the search is empty.
+func (p *Param) SetSearchDropdownQuery(search string) error {
+	if p.uiType != ParamTypeSearchDropdown {
+		return fmt.Errorf("param '%s': search query can only be set for parameters of type ParamTypeSearchDropdown", p.Name)
+	}
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return fmt.Errorf("param '%s': invald parameter: 'search' cannot be empty string", p.Name)
+	}
+	p.searchQuery = search
+	return nil
+}
+
 // setValue sets the run-time value of the parameter. It performs validation of the value based on the parameter's configurations such as AvailableChoices.
 // Returns an error in case the validation failed
 func (p *Param) setValue(v string) error {
@@ -164,6 +199,7 @@ func (p *Param) setValue(v string) error {
 			if c.Value == v {
 				p.actualValue = v
 				p.actualValueIsSet = true
+				p.actualValueFromRuntime = false
 				return nil
 			}
 		}
@@ -171,6 +207,7 @@ func (p *Param) setValue(v string) error {
 	}
 	p.actualValue = v
 	p.actualValueIsSet = true
+	p.actualValueFromRuntime = false
 	return nil
 }
 
@@ -183,13 +220,67 @@ func (p *Param) SetValue(v string) error {
 }
 
 // GetValue returns the run-time value which was forcibly set for this parameter, or its DefaultValue in case no value has been set
+// If an environment variable has been configured with SetEnvVarOverride and is set, its value is returned instead.
 // It substitutes env variables in the $var and ${var} within the value
 // Note: this does NOT access any Splunkd endpoint to read the value from splunk's .conf files.
+// If enabled with AllowFileValue, values with format file://<path> are replaced by the trimmed content of the file, see GetValueFromFile.
+// If the parameter is sensitive and a SecretResolver has been configured, values referencing a secret store are resolved.
+// In case the resolution fails, an empty string is returned: use GetResolvedValue to get the error.
 func (p *Param) GetValue() string {
-	if p.actualValueIsSet {
-		return os.ExpandEnv(p.actualValue)
+	v, _ := p.GetResolvedValue()
+	return v
+}
+
+// GetResolvedValue behaves as GetValue, additionally returning the error raised when reading values with format file://<path>,
+// or when resolving values with format vault://<path> or aws-secretsmanager://<path> through the configured SecretResolver.
+func (p *Param) GetResolvedValue() (string, error) {
+	v := os.ExpandEnv(p.defaultValue)
+	fromRuntime := false
+	if ev, found := p.lookupEnvVarOverride(); found {
+		v = ev
+	} else if p.actualValueIsSet {
+		v = os.ExpandEnv(p.actualValue)
+		fromRuntime = p.actualValueFromRuntime
+	}
+	if path, found := strings.CutPrefix(v, fileScheme); found && p.allowFileValue && !fromRuntime {
+		return p.GetValueFromFile(path)
+	}
+	if !p.sensitive || p.secretResolver == nil {
+		return v, nil
+	}
+	for _, scheme := range secretSchemes {
+		if path, found := strings.CutPrefix(v, scheme); found {
+			secret, err := p.secretResolver(path)
+			if err != nil {
+				return "", fmt.Errorf("param '%s': cannot resolve secret '%s'. %w", p.Name, v, err)
+			}
+			return secret, nil
+		}
+	}
+	return v, nil
+}
+
+// GetValueFromFile returns the content of the file at path, without surrounding spaces and newlines.
+// This is used for values with format file://<path>, such as secrets mounted as files by Docker or Kubernetes.
+func (p *Param) GetValueFromFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("param '%s': cannot read value from file '%s'. %w", p.Name, path, err)
 	}
-	return os.ExpandEnv(p.defaultValue)
+	return strings.TrimSpace(string(content)), nil
+}
+
+// AllowFileValue enables reading values with format file://<path> from the file at path, such as secrets mounted by Docker or Kubernetes.
+// This only applies to the default value, to the environment variable override and to the values read from .conf files of global parameters:
+// values provided within the run-time configuration of an alert can be edited by any author of alerts, and are never read from files.
+func (p *Param) AllowFileValue() {
+	p.allowFileValue = true
+}
+
+// SetSecretResolver configures the function used to resolve values referencing an external secret store,
+// having format vault://<path> or aws-secretsmanager://<path>. This only applies to sensitive parameters.
+func (p *Param) SetSecretResolver(fn SecretResolver) {
+	p.secretResolver = fn
 }
 
 // GetChoices returns a list of the internal values of the acceptable options for the parameter.
@@ -214,6 +305,11 @@ func (p *Param) IsSensitive() bool {
 	return p.sensitive
 }
 
+// IsRequired informs whether a value must be available for the parameter, either set at run-time or as default value.
+func (p *Param) IsRequired() bool {
+	return p.required
+}
+
 // GetConfigDefinition returns a triple (configFile, stanza, param name) defining where this parameter has been defined.
 func (p *Param) GetConfigDefinition() (configFile, stanza, paramName string) {
 	return p.configFile, p.stanza, p.Name
@@ -239,11 +335,35 @@ func (p *Param) ReadValueNS(client *splunkd.Client, owner, app string) (string,
 	return col.GetProperty(p.stanza, p.Name)
 }
 
-// HasSetValue informs whether a forced value has been set for the parameter.
+// HasSetValue informs whether a forced value has been set for the parameter, also through the environment variable configured with SetEnvVarOverride.
 func (p *Param) HasSetValue() bool {
+	if _, found := p.lookupEnvVarOverride(); found {
+		return true
+	}
 	return p.actualValueIsSet
 }
 
+// SetEnvVarOverride configures the environment variable envVarName, converted to uppercase, to override the value of the parameter.
+// When the variable is set, its value takes precedence over both the run-time value and the default value,
+// e.g. to configure an alert action or modular input running within a container without modifying splunk's configuration files.
+// Use an empty envVarName to remove the override.
+func (p *Param) SetEnvVarOverride(envVarName string) {
+	p.envVarOverride = strings.ToUpper(strings.TrimSpace(envVarName))
+}
+
+// GetEnvVarOverride returns the name of the environment variable overriding the value of the parameter, or an empty string
+func (p *Param) GetEnvVarOverride() string {
+	return p.envVarOverride
+}
+
+// lookupEnvVarOverride returns the value of the environment variable overriding the value of the parameter, if configured and set
+func (p *Param) lookupEnvVarOverride() (string, bool) {
+	if p.envVarOverride == "" {
+		return "", false
+	}
+	return os.LookupEnv(p.envVarOverride)
+}
+
 // GetDefaultValue returns the value of the parameter to be used in case no specific configuration is available
 func (p *Param) GetDefaultValue() string {
 	return p.defaultValue
@@ -0,0 +1,159 @@
+package alertactions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+/* This file defines a fluent builder of parameters, an alternative to the many positional arguments of NewParam and NewGlobalParam */
+
+// ParamBuilder configures a Param through chained calls, which is then created by Build.
+// Use NewParamBuilder to instantiate it:
+//
+//	p, err := alertactions.NewParamBuilder("channel").
+//		Title("Channel").
+//		Description("Channel to be notified").
+//		Default("#alerts").
+//		Required().
+//		Build()
+//
+// Build the parameter of a run-time configuration and register it with AlertAction.RegisterParam.
+// Use Global to build a global parameter, to be registered with AlertAction.RegisterGlobalParam or ModularInput.RegisterGlobalParam.
+type ParamBuilder struct {
+	name         string
+	title        string
+	description  string
+	defaultValue string
+	placeholder  string
+	uiType       ParamType
+	required     bool
+	sensitive    bool
+	global       bool
+	configFile   string
+	stanza       string
+	choices      []paramOption
+	searchQuery  string
+}
+
+// NewParamBuilder returns a builder of a parameter called name
+func NewParamBuilder(name string) *ParamBuilder {
+	return &ParamBuilder{name: name}
+}
+
+// Title sets the visible name of the parameter, used within the UI. It is mandatory.
+func (b *ParamBuilder) Title(title string) *ParamBuilder {
+	b.title = title
+	return b
+}
+
+// Description sets the description of the parameter
+func (b *ParamBuilder) Description(description string) *ParamBuilder {
+	b.description = description
+	return b
+}
+
+// Default sets the value used in case none has been set by the run-time configurations
+func (b *ParamBuilder) Default(value string) *ParamBuilder {
+	b.defaultValue = value
+	return b
+}
+
+// Placeholder sets a sample of the value, shown within the UI for parameters of type ParamTypeText and ParamTypeTextArea
+func (b *ParamBuilder) Placeholder(placeholder string) *ParamBuilder {
+	b.placeholder = placeholder
+	return b
+}
+
+// UIType sets how the parameter is represented within the UI. Use the ParamTypeXXX constants.
+func (b *ParamBuilder) UIType(uiType ParamType) *ParamBuilder {
+	b.uiType = uiType
+	return b
+}
+
+// Required marks the parameter as requiring a value, either set at run-time or as default value
+func (b *ParamBuilder) Required() *ParamBuilder {
+	b.required = true
+	return b
+}
+
+// Sensitive marks the parameter as containing sensitive data, which is masked when being logged
+func (b *ParamBuilder) Sensitive() *ParamBuilder {
+	b.sensitive = true
+	return b
+}
+
+// Global makes the parameter a global one, whose value is read from 'stanza' of splunk configuration file 'configFile'
+func (b *ParamBuilder) Global(configFile, stanza string) *ParamBuilder {
+	b.global = true
+	b.configFile = configFile
+	b.stanza = stanza
+	return b
+}
+
+// AddChoice adds an acceptable value for parameters of type ParamTypeDropdown and ParamTypeRadio. See Param.AddChoice
+func (b *ParamBuilder) AddChoice(value, label string) *ParamBuilder {
+	b.choices = append(b.choices, paramOption{Value: value, VisibleValue: label})
+	return b
+}
+
+// SearchDropdownQuery sets the search populating the choices of a parameter of type ParamTypeSearchDropdown. See Param.SetSearchDropdownQuery
+func (b *ParamBuilder) SearchDropdownQuery(search string) *ParamBuilder {
+	b.searchQuery = search
+	return b
+}
+
+// Build creates the configured parameter.
+// It returns an error if name or title are missing, if configFile or stanza are missing for a global parameter,
+// or if choices or search query do not fit the UI type.
+func (b *ParamBuilder) Build() (*Param, error) {
+	var missing []string
+	if strings.TrimSpace(b.name) == "" {
+		missing = append(missing, "name")
+	}
+	if strings.TrimSpace(b.title) == "" {
+		missing = append(missing, "title")
+	}
+	if b.global && strings.TrimSpace(b.configFile) == "" {
+		missing = append(missing, "configFile")
+	}
+	if b.global && strings.TrimSpace(b.stanza) == "" {
+		missing = append(missing, "stanza")
+	}
+	if len(missing) > 0 {
+		return nil, utils.NewErrInvalidParam("paramBuilder.Build", nil, "parameter '%s' is missing mandatory fields: %s", b.name, strings.Join(missing, ", "))
+	}
+	if len(b.choices) > 0 && b.uiType != ParamTypeDropdown && b.uiType != ParamTypeRadio {
+		return nil, utils.NewErrInvalidParam("paramBuilder.Build", nil, "parameter '%s': choices can only be added to parameters of type ParamTypeDropdown or ParamTypeRadio", b.name)
+	}
+
+	var p *Param
+	var err error
+	if b.global {
+		p, err = newParameter(b.configFile, b.stanza, b.name, b.title, b.description, b.defaultValue, b.placeholder, b.uiType, b.required)
+	} else {
+		// the stanza of run-time parameters is the one of the alert action, set when registering the parameter
+		p, err = newParameter("alert_actions.conf", "*", b.name, b.title, b.description, b.defaultValue, b.placeholder, b.uiType, b.required)
+		if p != nil {
+			p.stanza = ""
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("paramBuilder.Build: %w", err)
+	}
+	if b.sensitive {
+		p.SetSensitive()
+	}
+	for _, c := range b.choices {
+		if err = p.AddChoice(c.Value, c.VisibleValue); err != nil {
+			return nil, fmt.Errorf("paramBuilder.Build: %w", err)
+		}
+	}
+	if b.searchQuery != "" {
+		if err = p.SetSearchDropdownQuery(b.searchQuery); err != nil {
+			return nil, fmt.Errorf("paramBuilder.Build: %w", err)
+		}
+	}
+	return p, nil
+}
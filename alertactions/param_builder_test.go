@@ -0,0 +1,99 @@
+package alertactions
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParamBuilder(t *testing.T) {
+	p, err := NewParamBuilder("severity").
+		Title("Severity").
+		Description("Severity of the notification").
+		Default("low").
+		Required().
+		UIType(ParamTypeDropdown).
+		AddChoice("low", "Low").
+		AddChoice("high", "High").
+		Build()
+	if err != nil {
+		t.Fatalf("Build returned an error: %s", err.Error())
+	}
+	if p.Name != "severity" || p.Title != "Severity" || p.GetDefaultValue() != "low" || !p.IsRequired() || p.IsSensitive() || strings.Join(p.GetChoices(), ",") != "low,high" {
+		t.Errorf("wrong parameter built: %+v", p)
+	}
+	if err := p.SetValue("medium"); err == nil {
+		t.Error("built parameter accepted a value not included within its choices")
+	}
+
+	aa, _ := New("test-builder", "Test builder", "", "")
+	if err := aa.RegisterParam(p); err != nil {
+		t.Fatal(err)
+	}
+	if p.GetStanza() != "test-builder" || p.GetConfigFile() != "alert_actions" {
+		t.Errorf("registered parameter was not bound to the stanza of the alert action: %s", p.String())
+	}
+
+	g, err := NewParamBuilder("token").Title("Token").Sensitive().Global("notify.conf", "api").Build()
+	if err != nil {
+		t.Fatalf("Build of a global parameter returned an error: %s", err.Error())
+	}
+	if !g.IsSensitive() || g.String() != "notify[api]/token" {
+		t.Errorf("wrong global parameter built: %s", g.String())
+	}
+	if err := aa.RegisterGlobalParam(g); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParamBuilderErrors(t *testing.T) {
+	cases := []struct {
+		name     string
+		builder  *ParamBuilder
+		expected string
+	}{
+		{"no name", NewParamBuilder("").Title("Title"), "name"},
+		{"no title", NewParamBuilder("p"), "title"},
+		{"no name nor title", NewParamBuilder(" "), "name, title"},
+		{"global without config file", NewParamBuilder("p").Title("P").Global("", "stanza"), "configFile"},
+		{"global without stanza", NewParamBuilder("p").Title("P").Global("app.conf", ""), "stanza"},
+		{"choices of text parameter", NewParamBuilder("p").Title("P").AddChoice("a", "A"), "choices can only be added"},
+		{"empty choice", NewParamBuilder("p").Title("P").UIType(ParamTypeRadio).AddChoice("", "A"), "'value' cannot be empty"},
+		{"duplicated choice", NewParamBuilder("p").Title("P").UIType(ParamTypeRadio).AddChoice("a", "A").AddChoice("a", "B"), "duplicated"},
+		{"search query of dropdown", NewParamBuilder("p").Title("P").UIType(ParamTypeDropdown).SearchDropdownQuery("| inputlookup x"), "ParamTypeSearchDropdown"},
+		{"invalid ui type", NewParamBuilder("p").Title("P").UIType(ParamType(100)), "uiType"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := c.builder.Build()
+			if err == nil || p != nil {
+				t.Fatalf("Build did not fail. param=%v", p)
+			}
+			if !strings.Contains(err.Error(), c.expected) {
+				t.Errorf("error does not describe the problem '%s': %s", c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func ExampleParamBuilder() {
+	aa, _ := New("notify", "Notify", "Notifies a remote API", "")
+	channel, _ := NewParamBuilder("channel").
+		Title("Channel").
+		Description("Channel to be notified").
+		Placeholder("#alerts").
+		Required().
+		Build()
+	aa.RegisterParam(channel)
+
+	url, _ := NewParamBuilder("url").
+		Title("API URL").
+		Description("URL of the remote API").
+		Required().
+		Global("notify", "api").
+		Build()
+	aa.RegisterGlobalParam(url)
+
+	fmt.Println(channel, url)
+	// Output: alert_actions[notify]/channel notify[api]/url
+}
@@ -2,7 +2,10 @@ package alertactions
 
 import (
 	"fmt"
+	"html"
 	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
 /* This file defines the "Param" methods used to generate configuration files, spec files and UI elements.
@@ -19,19 +22,33 @@ The following functions are used by the corresponding AlertAction functions in o
 //	the resulting line within the .conf file will be:
 //	    config.debug = ...
 func (p *Param) GenerateSpec(namePrefix string) string {
+	return p.GenerateSpecWithPrefixFunc(func(paramName string) string {
+		if namePrefix == "" {
+			return paramName
+		}
+		return strings.TrimRight(namePrefix, ".") + "." + paramName
+	})
+}
+
+// GenerateSpecWithPrefixFunc behaves as GenerateSpec, using prefixFn to compute the name of the parameter within the specification.
+// If prefixFn is nil, the name of the parameter is used as-is.
+func (p *Param) GenerateSpecWithPrefixFunc(prefixFn func(paramName string) string) string {
 	buf := new(strings.Builder)
 	// pre-growing the buffer to 512 bytes: this avoids doing this continuously when executing buf.WriteString()
 	buf.Grow(512)
 
-	if namePrefix != "" {
-		namePrefix = strings.TrimRight(namePrefix, ".")
-		fmt.Fprintf(buf, "%s.", namePrefix)
+	name := p.Name
+	if prefixFn != nil {
+		name = prefixFn(p.Name)
 	}
 	fmt.Fprintf(buf, `%s = <string>
 *  %s: %s
 *  Required: %v
 *  Default value: "%s"
-`, p.Name, p.Title, strings.ReplaceAll(p.Description, "\n", " "), p.required, strings.ReplaceAll(p.defaultValue, "\n", " "))
+`, name, p.Title, strings.ReplaceAll(p.Description, "\n", " "), p.required, strings.ReplaceAll(p.defaultValue, "\n", " "))
+	if p.envVarOverride != "" {
+		fmt.Fprintf(buf, "*  Overridden by environment variable: %s\n", p.envVarOverride)
+	}
 
 	if len(p.availableOptions) > 0 {
 		fmt.Fprintf(buf, "* Available choices: %s", strings.Join(p.GetChoices(), "; "))
@@ -49,6 +66,14 @@ func (p *Param) GenerateSpec(namePrefix string) string {
 //	the resulting line within the .conf file will be:
 //	    config.debug = ...
 func (p *Param) GenerateConf(namePrefix string) string {
+	return p.GenerateConfWithPrefixFunc(func(paramName string) string {
+		return namePrefix + paramName
+	})
+}
+
+// GenerateConfWithPrefixFunc behaves as GenerateConf, using prefixFn to compute the name of the parameter within the configuration file.
+// If prefixFn is nil, the name of the parameter is used as-is.
+func (p *Param) GenerateConfWithPrefixFunc(prefixFn func(paramName string) string) string {
 	buf := new(strings.Builder)
 	// pre-growing the buffer to 512 bytes: this avoids doing this continuously when executing buf.WriteString()
 	buf.Grow(512)
@@ -57,12 +82,22 @@ func (p *Param) GenerateConf(namePrefix string) string {
 	if len(p.availableOptions) > 0 {
 		fmt.Fprintf(buf, "# Available choices: %s\n", strings.Join(p.GetChoices(), "; "))
 	}
+	if p.envVarOverride != "" {
+		fmt.Fprintf(buf, "# Overridden by environment variable %s, if set\n", p.envVarOverride)
+	}
 
-	if namePrefix == "" {
-		fmt.Fprintf(buf, "%s = %s\n", p.Name, strings.ReplaceAll(p.defaultValue, "\n", "\\\n"))
-	} else {
-		fmt.Fprintf(buf, "%s%s = %s\n", namePrefix, p.Name, strings.ReplaceAll(p.defaultValue, "\n", "\\\n"))
+	defaultValue := strings.ReplaceAll(p.defaultValue, "\n", "\\\n")
+	if p.sensitive {
+		fmt.Fprint(buf, "## CAUTION: This is a sensitive parameter; do not store plain-text values in version control\n")
+		if defaultValue != "" {
+			defaultValue = "<sensitive>"
+		}
+	}
+	name := p.Name
+	if prefixFn != nil {
+		name = prefixFn(p.Name)
 	}
+	fmt.Fprintf(buf, "%s = %s\n", name, defaultValue)
 
 	return buf.String()
 }
@@ -77,14 +112,23 @@ func (p *Param) GenerateRestMapConf(stanzaName string) string {
 	return fmt.Sprintf("#action.%s.param.%s = validate( match('action.%s.param.%s', \"^SOME REGULAR EXPRESSION HERE$\"), \"Setting '%s' is invalid, ADD SOME CUSTOM MESSAGE HERE\")\n", stanzaName, p.Name, stanzaName, p.Name, p.Title)
 }
 
+// GenerateTransformsConf returns a stanza of splunk's transforms.conf extracting the parameter as a field from key="value" pairs
+// found within the events, to be referenced with a REPORT- setting of props.conf.
+func (p *Param) GenerateTransformsConf() string {
+	return utils.GenerateTransformsConf(p.Name, p.Title, p.Description)
+}
+
 // getUIXML returns a string which can be used to build a HTML UI for the parameter
 // https://dev.splunk.com/enterprise/docs/devtools/customalertactions/createuicaa#Custom-HTML-component-reference
-func (p *Param) getUIHTML(stanzaName string) string {
+func (p *Param) getUIHTML(stanzaName string, format UIFormat) string {
 	// this only is only needed for NON global parameters
 	// global parameters get an empty string
 	if p.configFile != "" && p.stanza != "" {
 		return ""
 	}
+	if format == UIFormatModern {
+		return p.getUIHTMLModern(stanzaName)
+	}
 	buf := new(strings.Builder)
 	// pre-growing the buffer to 512 bytes: this avoids doing this continuously when executing buf.WriteString()
 	buf.Grow(512)
@@ -98,6 +142,8 @@ func (p *Param) getUIHTML(stanzaName string) string {
 		fmt.Fprintf(buf, "  <splunk-text-input name=\"action.%s.param.%s\" placeholder=\"%s\" id=\"%s\"></splunk-text-input>\n", stanzaName, p.Name, p.placeholder, p.Name)
 	case ParamTypeTextArea:
 		fmt.Fprintf(buf, "  <splunk-text-area name=\"action.%s.param.%s\" placeholder=\"%s\" id=\"%s\"></splunk-text-area>\n", stanzaName, p.Name, p.placeholder, p.Name)
+	case ParamTypeSearchDropdown:
+		fmt.Fprintf(buf, "  <splunk-search-dropdown name=\"action.%s.param.%s\" id=\"%s\" data-search-query=\"%s\"></splunk-search-dropdown>\n", stanzaName, p.Name, p.Name, html.EscapeString(p.searchQuery))
 	case ParamTypeDropdown:
 		fmt.Fprintf(buf, "  <splunk-select name=\"action.%s.param.%s\" id=\"%s\">\n", stanzaName, p.Name, p.Name)
 		for _, c := range p.availableOptions {
@@ -118,6 +164,47 @@ func (p *Param) getUIHTML(stanzaName string) string {
 	return buf.String()
 }
 
+// getUIHTMLModern returns the HTML UI for the parameter using standard HTML elements with data-param-name attributes, as used by Splunk 9.x
+// Texts provided by the developer are HTML-escaped.
+func (p *Param) getUIHTMLModern(stanzaName string) string {
+	buf := new(strings.Builder)
+	// pre-growing the buffer to 512 bytes: this avoids doing this continuously when executing buf.WriteString()
+	buf.Grow(512)
+
+	// attrs are shared by all the elements used to represent the parameter
+	attrs := fmt.Sprintf("name=\"action.%s.param.%s\" id=\"%s\" data-param-name=\"%s\"", stanzaName, p.Name, p.Name, p.Name)
+	fmt.Fprintf(buf, "<div class=\"control-group\">\n  <label class=\"control-label\" for=\"%s\">%s", p.Name, html.EscapeString(p.Title))
+	if p.required {
+		fmt.Fprint(buf, "<span style=\"color:red;margin: 0 0 0 2px;\">*</span>")
+	}
+	fmt.Fprint(buf, "</label>\n")
+	switch p.uiType {
+	case ParamTypeText:
+		fmt.Fprintf(buf, "  <input type=\"text\" %s placeholder=\"%s\"/>\n", attrs, html.EscapeString(p.placeholder))
+	case ParamTypeTextArea:
+		fmt.Fprintf(buf, "  <textarea %s placeholder=\"%s\"></textarea>\n", attrs, html.EscapeString(p.placeholder))
+	case ParamTypeSearchDropdown:
+		fmt.Fprintf(buf, "  <select %s data-search-query=\"%s\"></select>\n", attrs, html.EscapeString(p.searchQuery))
+	case ParamTypeDropdown:
+		fmt.Fprintf(buf, "  <select %s>\n", attrs)
+		for _, c := range p.availableOptions {
+			fmt.Fprintf(buf, "    <option value=\"%s\">%s</option>\n", html.EscapeString(c.Value), html.EscapeString(c.VisibleValue))
+		}
+		fmt.Fprintf(buf, "  </select>\n")
+	case ParamTypeRadio:
+		for i, c := range p.availableOptions {
+			// radio buttons share the same name, but need distinct ids
+			fmt.Fprintf(buf, "  <label><input type=\"radio\" name=\"action.%s.param.%s\" id=\"%s_%d\" data-param-name=\"%s\" value=\"%s\"/> %s</label>\n", stanzaName, p.Name, p.Name, i, p.Name, html.EscapeString(c.Value), html.EscapeString(c.VisibleValue))
+		}
+	case ParamTypeColorPicker:
+		fmt.Fprintf(buf, "  <input type=\"color\" %s/>\n", attrs)
+	}
+	fmt.Fprintf(buf, "  <span class=\"help-block\">%s</span>\n", html.EscapeString(strings.ReplaceAll(p.Description, "\n", " ")))
+	fmt.Fprint(buf, "</div>\n")
+
+	return buf.String()
+}
+
 // GenerateDocumentation returns a markdown-formatted list-item which describes the parameter
 func (p *Param) GenerateDocumentation() string {
 	buf := new(strings.Builder)
@@ -136,7 +223,13 @@ func (p *Param) GenerateDocumentation() string {
 	}
 	fmt.Fprint(buf, p.Description)
 
-	if p.defaultValue != "" {
+	if p.sensitive {
+		// never expose the values of sensitive parameters, not even their defaults
+		if p.defaultValue != "" {
+			fmt.Fprint(buf, "    Default value: (sensitive — not shown)")
+		}
+		fmt.Fprint(buf, "    Note: this is a sensitive parameter, its value must be provided via configuration and not hardcoded.")
+	} else if p.defaultValue != "" {
 		fmt.Fprintf(buf, "    Default value: `%s`", p.defaultValue)
 	}
 
@@ -0,0 +1,175 @@
+package alertactions
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* This file defines the getters converting the value of a parameter into a specific type */
+
+// GetBool returns the value of the parameter, as provided by GetValue, converted into a boolean.
+// Accepted values are the ones of strconv.ParseBool, as well as yes/no and on/off, case-insensitive.
+func (p *Param) GetBool() (bool, error) {
+	v, err := p.getTrimmedValue()
+	if err != nil {
+		return false, err
+	}
+	b, err := parseBool(v)
+	if err != nil {
+		return false, p.conversionError(v, "boolean")
+	}
+	return b, nil
+}
+
+// parseBool converts v into a boolean, accepting the values of strconv.ParseBool, as well as yes/no and on/off, case-insensitive
+func parseBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(strings.ToLower(v))
+}
+
+// GetInt64 returns the value of the parameter, as provided by GetValue, converted into an integer
+func (p *Param) GetInt64() (int64, error) {
+	v, err := p.getTrimmedValue()
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, p.conversionError(v, "integer")
+	}
+	return i, nil
+}
+
+// GetFloat64 returns the value of the parameter, as provided by GetValue, converted into a float
+func (p *Param) GetFloat64() (float64, error) {
+	v, err := p.getTrimmedValue()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, p.conversionError(v, "float")
+	}
+	return f, nil
+}
+
+// GetDuration returns the value of the parameter, as provided by GetValue, converted into a time.Duration.
+// The value can be either a Go duration string such as "1m30s", or an integer number of seconds.
+func (p *Param) GetDuration() (time.Duration, error) {
+	v, err := p.getTrimmedValue()
+	if err != nil {
+		return 0, err
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, p.conversionError(v, "duration")
+	}
+	return d, nil
+}
+
+// GetURL returns the value of the parameter, as provided by GetValue, parsed as an absolute URL having scheme and host
+func (p *Param) GetURL() (*url.URL, error) {
+	v, err := p.getTrimmedValue()
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, p.conversionError(v, "absolute URL")
+	}
+	return u, nil
+}
+
+// MustGetBool behaves as GetBool, returning defaultVal in case the value cannot be converted.
+// The conversion error is written on stderr.
+func (p *Param) MustGetBool(defaultVal bool) bool {
+	b, err := p.GetBool()
+	if err != nil {
+		p.logConversionError(err, defaultVal)
+		return defaultVal
+	}
+	return b
+}
+
+// MustGetInt64 behaves as GetInt64, returning defaultVal in case the value cannot be converted.
+// The conversion error is written on stderr.
+func (p *Param) MustGetInt64(defaultVal int64) int64 {
+	i, err := p.GetInt64()
+	if err != nil {
+		p.logConversionError(err, defaultVal)
+		return defaultVal
+	}
+	return i
+}
+
+// MustGetFloat64 behaves as GetFloat64, returning defaultVal in case the value cannot be converted.
+// The conversion error is written on stderr.
+func (p *Param) MustGetFloat64(defaultVal float64) float64 {
+	f, err := p.GetFloat64()
+	if err != nil {
+		p.logConversionError(err, defaultVal)
+		return defaultVal
+	}
+	return f
+}
+
+// MustGetDuration behaves as GetDuration, returning defaultVal in case the value cannot be converted.
+// The conversion error is written on stderr.
+func (p *Param) MustGetDuration(defaultVal time.Duration) time.Duration {
+	d, err := p.GetDuration()
+	if err != nil {
+		p.logConversionError(err, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
+// MustGetURL behaves as GetURL, returning defaultVal in case the value cannot be converted.
+// The conversion error is written on stderr.
+func (p *Param) MustGetURL(defaultVal *url.URL) *url.URL {
+	u, err := p.GetURL()
+	if err != nil {
+		p.logConversionError(err, defaultVal)
+		return defaultVal
+	}
+	return u
+}
+
+// getTrimmedValue returns the resolved value of the parameter without surrounding spaces
+func (p *Param) getTrimmedValue() (string, error) {
+	v, err := p.GetResolvedValue()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// conversionError returns the error raised when value v cannot be converted into typeName.
+// The value is not reported for sensitive parameters.
+func (p *Param) conversionError(v, typeName string) error {
+	if p.sensitive {
+		v = "***"
+	}
+	source := "value"
+	if !p.actualValueIsSet {
+		source = "default value"
+	}
+	return fmt.Errorf("param '%s': %s '%s' cannot be converted into %s", p.Name, source, v, typeName)
+}
+
+// logConversionError writes err on stderr, informing that defaultVal is being used instead
+func (p *Param) logConversionError(err error, defaultVal interface{}) {
+	fmt.Fprintf(os.Stderr, "WARN - %s. Using %v instead\n", err.Error(), defaultVal)
+}
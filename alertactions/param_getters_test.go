@@ -0,0 +1,185 @@
+package alertactions
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTypedParam returns a parameter having defaultValue, and value v if v is not nil
+func newTypedParam(defaultValue string, v *string) *Param {
+	p := &Param{Title: "title", Name: "typed", defaultValue: defaultValue}
+	if v != nil {
+		p.setValue(*v)
+	}
+	return p
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestParamGetBool(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		value        *string
+		expected     bool
+		wantErr      bool
+	}{
+		{"true", nil, true, false},
+		{"0", nil, false, false},
+		{"maybe", nil, false, true},
+		{"", nil, false, true},
+		{"false", strPtr("YES"), true, false},
+		{"true", strPtr("No"), false, false},
+		{"false", strPtr(" on "), true, false},
+		{"true", strPtr("OFF"), false, false},
+		{"false", strPtr("T"), true, false},
+		{"false", strPtr("1"), true, false},
+		{"true", strPtr("enabled"), false, true},
+	}
+	for _, c := range cases {
+		b, err := newTypedParam(c.defaultValue, c.value).GetBool()
+		if (err != nil) != c.wantErr || b != c.expected {
+			t.Errorf("GetBool default=%q value=%v: expected=%v wantErr=%v, got=%v err=%v", c.defaultValue, c.value, c.expected, c.wantErr, b, err)
+		}
+	}
+	if !newTypedParam("wrong", nil).MustGetBool(true) || newTypedParam("wrong", nil).MustGetBool(false) {
+		t.Error("MustGetBool did not return the provided default on conversion errors")
+	}
+	if newTypedParam("yes", nil).MustGetBool(false) != true {
+		t.Error("MustGetBool did not return the converted value")
+	}
+}
+
+func TestParamGetInt64(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		value        *string
+		expected     int64
+		wantErr      bool
+	}{
+		{"10", nil, 10, false},
+		{"-3", nil, -3, false},
+		{"ten", nil, 0, true},
+		{"", nil, 0, true},
+		{"1.5", nil, 0, true},
+		{"9223372036854775808", nil, 0, true},
+		{"1", strPtr(" 42 "), 42, false},
+		{"ten", strPtr("7"), 7, false},
+	}
+	for _, c := range cases {
+		i, err := newTypedParam(c.defaultValue, c.value).GetInt64()
+		if (err != nil) != c.wantErr || i != c.expected {
+			t.Errorf("GetInt64 default=%q value=%v: expected=%v wantErr=%v, got=%v err=%v", c.defaultValue, c.value, c.expected, c.wantErr, i, err)
+		}
+	}
+	if newTypedParam("ten", nil).MustGetInt64(5) != 5 || newTypedParam("3", nil).MustGetInt64(5) != 3 {
+		t.Error("MustGetInt64 returned a wrong value")
+	}
+}
+
+func TestParamGetFloat64(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		value        *string
+		expected     float64
+		wantErr      bool
+	}{
+		{"1.5", nil, 1.5, false},
+		{"-2", nil, -2, false},
+		{"1e3", nil, 1000, false},
+		{"1,5", nil, 0, true},
+		{"", nil, 0, true},
+		{"0", strPtr("0.25"), 0.25, false},
+	}
+	for _, c := range cases {
+		f, err := newTypedParam(c.defaultValue, c.value).GetFloat64()
+		if (err != nil) != c.wantErr || f != c.expected {
+			t.Errorf("GetFloat64 default=%q value=%v: expected=%v wantErr=%v, got=%v err=%v", c.defaultValue, c.value, c.expected, c.wantErr, f, err)
+		}
+	}
+	if newTypedParam("x", nil).MustGetFloat64(0.5) != 0.5 || newTypedParam("2.5", nil).MustGetFloat64(0.5) != 2.5 {
+		t.Error("MustGetFloat64 returned a wrong value")
+	}
+}
+
+func TestParamGetDuration(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		value        *string
+		expected     time.Duration
+		wantErr      bool
+	}{
+		{"30", nil, 30 * time.Second, false},
+		{"1m30s", nil, 90 * time.Second, false},
+		{"250ms", nil, 250 * time.Millisecond, false},
+		{"0", nil, 0, false},
+		{"-5", nil, -5 * time.Second, false},
+		{"1.5", nil, 0, true},
+		{"5 minutes", nil, 0, true},
+		{"", nil, 0, true},
+		{"30", strPtr("2h"), 2 * time.Hour, false},
+	}
+	for _, c := range cases {
+		d, err := newTypedParam(c.defaultValue, c.value).GetDuration()
+		if (err != nil) != c.wantErr || d != c.expected {
+			t.Errorf("GetDuration default=%q value=%v: expected=%v wantErr=%v, got=%v err=%v", c.defaultValue, c.value, c.expected, c.wantErr, d, err)
+		}
+	}
+	if newTypedParam("soon", nil).MustGetDuration(time.Minute) != time.Minute || newTypedParam("10", nil).MustGetDuration(time.Minute) != 10*time.Second {
+		t.Error("MustGetDuration returned a wrong value")
+	}
+}
+
+func TestParamGetURL(t *testing.T) {
+	cases := []struct {
+		defaultValue string
+		value        *string
+		expected     string
+		wantErr      bool
+	}{
+		{"https://api.example.com/v1?x=1", nil, "https://api.example.com/v1?x=1", false},
+		{"http://localhost:8080", nil, "http://localhost:8080", false},
+		{"api.example.com/v1", nil, "", true},
+		{"/relative/path", nil, "", true},
+		{"http://[::1", nil, "", true},
+		{"", nil, "", true},
+		{"", strPtr("https://hooks.example.com"), "https://hooks.example.com", false},
+	}
+	for _, c := range cases {
+		u, err := newTypedParam(c.defaultValue, c.value).GetURL()
+		if (err != nil) != c.wantErr || (u != nil && u.String() != c.expected) || (u == nil && !c.wantErr) {
+			t.Errorf("GetURL default=%q value=%v: expected=%v wantErr=%v, got=%v err=%v", c.defaultValue, c.value, c.expected, c.wantErr, u, err)
+		}
+	}
+	fallback, _ := url.Parse("https://fallback.example.com")
+	if newTypedParam("not a url", nil).MustGetURL(fallback) != fallback || newTypedParam("https://x.example.com", nil).MustGetURL(fallback).Host != "x.example.com" {
+		t.Error("MustGetURL returned a wrong value")
+	}
+}
+
+func TestParamGettersErrors(t *testing.T) {
+	t.Setenv("TEST_PARAM_TIMEOUT", "45")
+	if d, err := newTypedParam("${TEST_PARAM_TIMEOUT}", nil).GetDuration(); err != nil || d != 45*time.Second {
+		t.Errorf("GetDuration did not expand environment variables. got=%v err=%v", d, err)
+	}
+
+	p := newTypedParam("secret-value", nil)
+	p.SetSensitive()
+	if _, err := p.GetInt64(); err == nil || strings.Contains(err.Error(), "secret-value") {
+		t.Errorf("conversion error of a sensitive parameter must not contain its value: %v", err)
+	} else if !strings.Contains(err.Error(), "default value") {
+		t.Errorf("conversion error does not inform that the default value was used: %v", err)
+	}
+
+	resolveErr := errors.New("vault unavailable")
+	p = newTypedParam("vault://secret/timeout", nil)
+	p.SetSensitive()
+	p.SetSecretResolver(func(path string) (string, error) { return "", resolveErr })
+	if _, err := p.GetDuration(); !errors.Is(err, resolveErr) {
+		t.Errorf("GetDuration did not return the error of the secret resolver: %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package alertactions
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -88,3 +93,247 @@ func TestParamAcceptableValues(t *testing.T) {
 	}
 
 }
+
+func TestParamDocumentationSensitive(t *testing.T) {
+	p := Param{
+		Title:        "Password",
+		Name:         "password",
+		Description:  "descr",
+		defaultValue: "password123",
+		sensitive:    true,
+	}
+	doc := p.GenerateDocumentation()
+	if strings.Contains(doc, p.defaultValue) {
+		t.Errorf("GenerateDocumentation exposed the default value of a sensitive parameter: %s", doc)
+	}
+	if !strings.Contains(doc, "(sensitive — not shown)") {
+		t.Errorf("GenerateDocumentation did not mark the default value of a sensitive parameter as hidden: %s", doc)
+	}
+}
+
+func TestParamConfSensitive(t *testing.T) {
+	p := Param{
+		Title:        "API token",
+		Name:         "token",
+		Description:  "descr",
+		defaultValue: "secret-token",
+		sensitive:    true,
+	}
+	conf := p.GenerateConf("param.")
+	if strings.Contains(conf, p.defaultValue) {
+		t.Errorf("GenerateConf exposed the default value of a sensitive parameter: %s", conf)
+	}
+	if !strings.Contains(conf, "param.token = <sensitive>") || !strings.Contains(conf, "## CAUTION") {
+		t.Errorf("GenerateConf did not mark the sensitive parameter: %s", conf)
+	}
+}
+
+func TestParamSearchDropdown(t *testing.T) {
+	p := Param{Title: "Index", Name: "index", Description: "descr", uiType: ParamTypeSearchDropdown}
+	var err error
+	if err = p.SetSearchDropdownQuery(""); err == nil {
+		t.Error("SetSearchDropdownQuery did not return an error when provided with an empty search")
+	}
+	if err = p.SetSearchDropdownQuery(`| eventcount summarize=false index="*"`); err != nil {
+		t.Errorf("SetSearchDropdownQuery returned an error for a valid search: %s", err.Error())
+	}
+	h := p.getUIHTML("my-alert", UIFormatClassic)
+	if !strings.Contains(h, "<splunk-search-dropdown") || !strings.Contains(h, `data-search-query="| eventcount summarize=false index=&#34;*&#34;"`) {
+		t.Errorf("getUIHTML did not generate a proper splunk-search-dropdown element: %s", h)
+	}
+
+	text := Param{Title: "Text", Name: "text", Description: "descr", uiType: ParamTypeText}
+	if err = text.SetSearchDropdownQuery("| makeresults"); err == nil {
+		t.Error("SetSearchDropdownQuery did not return an error for a parameter which is not a search dropdown")
+	}
+}
+
+func TestParamSecretResolver(t *testing.T) {
+	p := Param{Title: "Password", Name: "password", sensitive: true}
+	p.SetSecretResolver(func(path string) (string, error) {
+		if path == "secret/data/app#password" {
+			return "resolved", nil
+		}
+		return "", fmt.Errorf("secret not found")
+	})
+
+	p.setValue("vault://secret/data/app#password")
+	if p.GetValue() != "resolved" {
+		t.Errorf("GetValue did not resolve the vault:// reference. got='%s'", p.GetValue())
+	}
+	p.setValue("aws-secretsmanager://missing")
+	if _, err := p.GetResolvedValue(); err == nil {
+		t.Error("GetResolvedValue did not return an error when the resolver failed")
+	}
+	p.setValue("plain value")
+	if p.GetValue() != "plain value" {
+		t.Errorf("GetValue modified a value not referencing a secret store. got='%s'", p.GetValue())
+	}
+	// non-sensitive parameters are never resolved
+	p.sensitive = false
+	p.setValue("vault://secret/data/app#password")
+	if p.GetValue() != "vault://secret/data/app#password" {
+		t.Errorf("GetValue resolved the value of a non-sensitive parameter. got='%s'", p.GetValue())
+	}
+
+	// resolved secrets are not logged when a parameter uses its default value
+	aa, _ := New("test-secret", "Test secret", "", "")
+	stderr := new(strings.Builder)
+	aa.stderr = stderr
+	aa.EnableDebug()
+	sp, err := aa.RegisterNewParam("password", "Password", "API password", "vault://secret/data/app#password", "", ParamTypeText, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.SetSensitive()
+	sp.SetSecretResolver(func(path string) (string, error) { return "s3cr3t", nil })
+	aa.runtimeConfig = &alertConfig{Configuration: map[string]string{}}
+	if err := aa.setParams(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stderr.String(), "s3cr3t") || !strings.Contains(stderr.String(), "***masked***") {
+		t.Errorf("the default value of a sensitive parameter was not masked: %s", stderr.String())
+	}
+}
+
+func TestParamValueFromFile(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "api_token")
+	if err := os.WriteFile(secretFile, []byte("  s3cr3t-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	portFile := filepath.Join(dir, "port")
+	os.WriteFile(portFile, []byte("8443\n"), 0600)
+
+	p := Param{Title: "Token", Name: "token", defaultValue: "file://" + secretFile}
+	if p.GetValue() != "file://"+secretFile {
+		t.Errorf("GetValue read the value from file without AllowFileValue. got='%s'", p.GetValue())
+	}
+	p.AllowFileValue()
+	if p.GetValue() != "s3cr3t-token" {
+		t.Errorf("GetValue did not read the default value from file. got='%s'", p.GetValue())
+	}
+	p.setValue("file://" + portFile)
+	if port, err := p.GetInt64(); err != nil || port != 8443 {
+		t.Errorf("GetInt64 did not read the value from file. got=%d err=%v", port, err)
+	}
+	t.Setenv("TEST_SECRETS_DIR", dir)
+	p.setValue("file://${TEST_SECRETS_DIR}/api_token")
+	if p.GetValue() != "s3cr3t-token" {
+		t.Errorf("GetValue did not expand environment variables within the file path. got='%s'", p.GetValue())
+	}
+
+	missing := filepath.Join(dir, "missing")
+	p.setValue("file://" + missing)
+	if p.GetValue() != "" {
+		t.Errorf("GetValue returned a value for a missing file: '%s'", p.GetValue())
+	}
+	_, err := p.GetResolvedValue()
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "'token'") || !strings.Contains(err.Error(), missing) {
+		t.Errorf("error does not report parameter name and file path: %v", err)
+	}
+	if _, err := p.GetValueFromFile(dir); err == nil {
+		t.Error("GetValueFromFile did not fail when reading a directory")
+	}
+	if v, err := p.GetValueFromFile(secretFile); err != nil || v != "s3cr3t-token" {
+		t.Errorf("GetValueFromFile returned a wrong value. got='%s' err=%v", v, err)
+	}
+
+	// values of the run-time configuration are never read from files, as any alert author can edit them
+	aa, _ := New("test-file", "Test file", "", "")
+	aa.stderr = new(strings.Builder)
+	rp, err := aa.RegisterNewParam("token", "Token", "API token", "", "", ParamTypeText, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp.AllowFileValue()
+	aa.runtimeConfig = &alertConfig{Configuration: map[string]string{"token": "file://" + secretFile}}
+	if err := aa.setParams(); err != nil {
+		t.Fatal(err)
+	}
+	if rp.GetValue() != "file://"+secretFile {
+		t.Errorf("GetValue read a run-time value from file. got='%s'", rp.GetValue())
+	}
+}
+
+func TestParamGeneratePrefix(t *testing.T) {
+	p := Param{Title: "Debug", Name: "debug", defaultValue: "false"}
+	if conf := p.GenerateConf("param."); !strings.Contains(conf, "param.debug = false\n") {
+		t.Errorf("GenerateConf did not prefix the parameter name: %s", conf)
+	}
+	if spec := p.GenerateSpec("config"); !strings.HasPrefix(spec, "config.debug = <string>") {
+		t.Errorf("GenerateSpec did not prefix the parameter name: %s", spec)
+	}
+	upper := func(name string) string { return "action.x." + strings.ToUpper(name) }
+	if conf := p.GenerateConfWithPrefixFunc(upper); !strings.Contains(conf, "action.x.DEBUG = false\n") {
+		t.Errorf("GenerateConfWithPrefixFunc did not use the prefix function: %s", conf)
+	}
+	if spec := p.GenerateSpecWithPrefixFunc(nil); !strings.HasPrefix(spec, "debug = <string>") {
+		t.Errorf("GenerateSpecWithPrefixFunc did not use the plain name with a nil prefix function: %s", spec)
+	}
+}
+
+func TestParamUIHTMLModern(t *testing.T) {
+	p := Param{Title: "Channel", Name: "channel", Description: "descr", placeholder: "#general", uiType: ParamTypeText, required: true}
+	h := p.getUIHTML("my-alert", UIFormatModern)
+	if !strings.Contains(h, `<input type="text" name="action.my-alert.param.channel" id="channel" data-param-name="channel" placeholder="#general"/>`) {
+		t.Errorf("getUIHTML did not generate a modern text input: %s", h)
+	}
+	if strings.Contains(h, "<splunk-") {
+		t.Errorf("getUIHTML generated classic elements in modern format: %s", h)
+	}
+	if h = p.getUIHTML("my-alert", UIFormatClassic); !strings.Contains(h, `<splunk-text-input name="action.my-alert.param.channel"`) {
+		t.Errorf("getUIHTML did not generate a classic text input: %s", h)
+	}
+
+	p = Param{Title: "Severity <level>", Name: "severity", Description: `"high" & "low"`, uiType: ParamTypeDropdown,
+		availableOptions: []paramOption{{Value: `a"b`, VisibleValue: "<b>bold</b>"}}}
+	h = p.getUIHTML("my-alert", UIFormatModern)
+	if !strings.Contains(h, `>Severity &lt;level&gt;`) || !strings.Contains(h, `&#34;high&#34; &amp; &#34;low&#34;`) || !strings.Contains(h, `<option value="a&#34;b">&lt;b&gt;bold&lt;/b&gt;</option>`) {
+		t.Errorf("getUIHTML did not escape the texts of the parameter in modern format: %s", h)
+	}
+	p.uiType = ParamTypeText
+	p.placeholder = `say "hi"`
+	if h = p.getUIHTML("my-alert", UIFormatModern); !strings.Contains(h, `placeholder="say &#34;hi&#34;"`) {
+		t.Errorf("getUIHTML did not escape the placeholder in modern format: %s", h)
+	}
+}
+
+func TestParamGenerateTransformsConf(t *testing.T) {
+	p, _ := NewParamBuilder("ticket_id").Title("Ticket ID").Description("Id of the created ticket").Build()
+	expected := `# Ticket ID: Id of the created ticket
+[extract_ticket_id]
+REGEX = (?:^|\s)ticket_id="(?<ticket_id>(?:[^"\\]|\\.)*)"
+`
+	if conf := p.GenerateTransformsConf(); conf != expected {
+		t.Errorf("wrong transforms.conf stanza generated.\nexpected:\n%s\ngot:\n%s", expected, conf)
+	}
+
+	// '-' and '.' are not allowed within the names of PCRE groups
+	p, _ = NewParamBuilder("ticket-id.v2").Title("Ticket ID").Build()
+	if conf := p.GenerateTransformsConf(); !strings.Contains(conf, `REGEX = (?:^|\s)ticket-id\.v2="(?<ticket_id_v2>`) || !strings.Contains(conf, "[extract_ticket-id.v2]") {
+		t.Errorf("the name of the extracted field was not sanitized: %s", conf)
+	}
+}
+
+func TestParamEnvVarOverride(t *testing.T) {
+	p, _ := NewParamBuilder("api_url").Title("API URL").Default("https://localhost").Build()
+	p.SetEnvVarOverride("myaction_api_url")
+	if p.GetEnvVarOverride() != "MYACTION_API_URL" {
+		t.Errorf("wrong environment variable name: %s", p.GetEnvVarOverride())
+	}
+	if p.HasSetValue() || p.GetValue() != "https://localhost" {
+		t.Errorf("parameter overridden by an unset environment variable. value=%s", p.GetValue())
+	}
+	p.setValue("https://example.com")
+	t.Setenv("MYACTION_API_URL", "https://from-env.example.com")
+	if !p.HasSetValue() || p.GetValue() != "https://from-env.example.com" {
+		t.Errorf("environment variable did not take precedence over the set value. value=%s", p.GetValue())
+	}
+	if spec := p.GenerateSpec("param."); !strings.Contains(spec, "Overridden by environment variable: MYACTION_API_URL") {
+		t.Errorf("spec does not document the environment variable: %s", spec)
+	}
+	if conf := p.GenerateConf("param."); !strings.Contains(conf, "# Overridden by environment variable MYACTION_API_URL, if set") {
+		t.Errorf("conf does not document the environment variable: %s", conf)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Package testutil provides a harness to write unit tests of alert actions without a running Splunk instance.
+
+[TestAlertAction] executes an [alertactions.AlertAction] as Splunk would with '--execute': it provides the JSON-based
+run-time configuration, including the results of the triggering search, and emulates the splunkd server the alert action connects to.
+The logs written by the alert action, both on stderr and to splunkd's internal logging endpoint, are captured for inspection.
+
+Requests to splunkd other than authentication and logging, e.g. the ones reading the values of global parameters,
+are served by the handler provided with [TestAlertAction.SetSplunkdMock].
+
+Example:
+
+	func TestNotify(t *testing.T) {
+		aa, _ := alertactions.New("notify", "Notify", "Notifies a remote API", "")
+		channel, _ := alertactions.NewParamBuilder("channel").Title("Channel").Description("Channel to be notified").Required().Build()
+		aa.RegisterParam(channel)
+		apiURL, _ := alertactions.NewParamBuilder("url").Title("API URL").Description("URL of the remote API").Required().Global("notify", "api").Build()
+		aa.RegisterGlobalParam(apiURL)
+		aa.RegisterAlertFunc(func(aa *alertactions.AlertAction) error {
+			url, _ := aa.GetGlobalParam("url")
+			return aa.IterateResults(func(row map[string]string) error {
+				aa.Log("INFO", "notifying url=%s host=%s", url.GetValue(), row["host"])
+				return nil
+			})
+		})
+
+		ta := testutil.NewTestAlertAction(aa).
+			SetConfig(map[string]string{"channel": "#alerts"}).
+			SetResultsCSV([]map[string]string{{"host": "web-1"}, {"host": "web-2"}}).
+			SetSplunkdMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// GET /servicesNS/admin/search/properties/notify/api
+				fmt.Fprint(w, `{"entry":[{"name":"url","content":"https://api.example.com"}]}`)
+			}))
+		defer ta.Close()
+
+		if err := ta.Run(); err != nil {
+			t.Fatal(err)
+		}
+		logs := strings.Join(ta.CapturedLogs(), "\n")
+		if !strings.Contains(logs, "notifying url=https://api.example.com host=web-2") {
+			t.Errorf("unexpected logs: %s", logs)
+		}
+	}
+*/
+package testutil
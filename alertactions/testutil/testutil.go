@@ -0,0 +1,244 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prigio/splunk-go-sdk/alertactions"
+)
+
+// Values of the run-time configuration provided to the alert action, as Splunk would do
+const (
+	TestApp        = "search"
+	TestOwner      = "admin"
+	TestSid        = "scheduler__admin__search__test_at_1700000000_1"
+	TestSearchName = "test search"
+	TestSessionKey = "test-session-key"
+)
+
+// TestAlertAction executes an AlertAction as Splunk would, providing its run-time configuration
+// and emulating the splunkd endpoints it needs, and captures the logs it writes.
+type TestAlertAction struct {
+	*alertactions.AlertAction
+
+	configuration map[string]string
+	result        map[string]interface{}
+	resultsFile   string
+	// tempFiles are removed by Close
+	tempFiles []string
+
+	server *httptest.Server
+	mock   http.Handler
+
+	logsMu sync.Mutex
+	logs   []string
+}
+
+// runtimeConfig is the JSON-based configuration which Splunk provides on stdin
+type runtimeConfig struct {
+	App           string                 `json:"app"`
+	Owner         string                 `json:"owner"`
+	ResultsFile   string                 `json:"results_file"`
+	ServerHost    string                 `json:"server_host"`
+	ServerUri     string                 `json:"server_uri"`
+	SessionKey    string                 `json:"session_key"`
+	Sid           string                 `json:"sid"`
+	SearchName    string                 `json:"search_name"`
+	Configuration map[string]string      `json:"configuration"`
+	Result        map[string]interface{} `json:"result"`
+}
+
+// NewTestAlertAction returns a harness for aa, which must have its parameters and alerting function already registered
+func NewTestAlertAction(aa *alertactions.AlertAction) *TestAlertAction {
+	return &TestAlertAction{AlertAction: aa, configuration: map[string]string{}, result: map[string]interface{}{}}
+}
+
+// SetConfig sets the values of the parameters of the alert action, as configured by the user within the UI
+func (ta *TestAlertAction) SetConfig(config map[string]string) *TestAlertAction {
+	ta.configuration = config
+	return ta
+}
+
+// SetFirstResult sets the first result of the search which triggered the alert
+func (ta *TestAlertAction) SetFirstResult(result map[string]interface{}) *TestAlertAction {
+	ta.result = result
+	return ta
+}
+
+// SetResultsCSV writes rows into a gzip-compressed CSV file, provided to the alert action as the results of the search.
+// The header contains the union of the fields of all rows, sorted by name. The file is removed by Close.
+// If no first result has been set, the first row is used as such.
+func (ta *TestAlertAction) SetResultsCSV(rows []map[string]string) *TestAlertAction {
+	path, err := writeResultsCSV(rows)
+	if err != nil {
+		panic(fmt.Sprintf("testutil.SetResultsCSV: %s", err.Error()))
+	}
+	ta.resultsFile = path
+	ta.tempFiles = append(ta.tempFiles, path)
+	if len(ta.result) == 0 && len(rows) > 0 {
+		ta.result = make(map[string]interface{}, len(rows[0]))
+		for k, v := range rows[0] {
+			ta.result[k] = v
+		}
+	}
+	return ta
+}
+
+// writeResultsCSV writes rows into a temporary gzip-compressed CSV file and returns its path
+func writeResultsCSV(rows []map[string]string) (string, error) {
+	fieldSet := map[string]bool{}
+	for _, row := range rows {
+		for k := range row {
+			fieldSet[k] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for k := range fieldSet {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	f, err := os.CreateTemp("", "results-*.csv.gz")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	w := csv.NewWriter(gz)
+	w.Write(fields)
+	for _, row := range rows {
+		record := make([]string, len(fields))
+		for i, k := range fields {
+			record[i] = row[k]
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// SetSplunkdMock configures handler to serve the requests the alert action sends to splunkd, e.g. to read global parameters.
+// Authentication and internal logging endpoints are emulated by the harness and do not reach handler.
+func (ta *TestAlertAction) SetSplunkdMock(handler http.Handler) *TestAlertAction {
+	ta.mock = handler
+	ta.startServer()
+	return ta
+}
+
+// startServer starts the emulated splunkd server, if not already running
+func (ta *TestAlertAction) startServer() {
+	if ta.server == nil {
+		ta.server = httptest.NewServer(http.HandlerFunc(ta.serveSplunkd))
+	}
+}
+
+// serveSplunkd emulates the splunkd endpoints used by every alert action, and forwards any other request to the mock
+func (ta *TestAlertAction) serveSplunkd(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/services/authentication/current-context":
+		fmt.Fprintf(w, `{"entry":[{"name":"context","content":{"username":"%s","roles":["admin"],"defaultApp":"%s"}}]}`, TestOwner, TestApp)
+	case "/services/receivers/simple":
+		body, _ := io.ReadAll(r.Body)
+		ta.captureLogs(bytes.NewReader(body))
+		fmt.Fprintf(w, `{"index":"%s","bytes":%d}`, r.URL.Query().Get("index"), len(body))
+	default:
+		if ta.mock != nil {
+			ta.mock.ServeHTTP(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"messages":[{"type":"ERROR","text":"testutil: no splunkd mock configured for %s %s"}]}`, r.Method, r.URL.Path)
+	}
+}
+
+// captureLogs collects the non-empty lines read from r
+func (ta *TestAlertAction) captureLogs(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			ta.logsMu.Lock()
+			ta.logs = append(ta.logs, line)
+			ta.logsMu.Unlock()
+		}
+	}
+}
+
+// Run executes the alert action with the configured run-time configuration, as Splunk would do with '--execute':
+// parameters are set and validated, then the alerting function is executed.
+// Logs captured by previous runs are discarded.
+func (ta *TestAlertAction) Run() error {
+	ta.startServer()
+	conf, err := json.Marshal(runtimeConfig{
+		App:           TestApp,
+		Owner:         TestOwner,
+		ResultsFile:   ta.resultsFile,
+		ServerHost:    "localhost",
+		ServerUri:     ta.server.URL,
+		SessionKey:    TestSessionKey,
+		Sid:           TestSid,
+		SearchName:    TestSearchName,
+		Configuration: ta.configuration,
+		Result:        ta.result,
+	})
+	if err != nil {
+		return fmt.Errorf("testutil run: %w", err)
+	}
+	ta.logsMu.Lock()
+	ta.logs = nil
+	ta.logsMu.Unlock()
+
+	// logs written on stderr are read through a pipe while the alert action is running
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		ta.captureLogs(pr)
+		// drain the pipe in case of overlong lines, so that writers never block
+		io.Copy(io.Discard, pr)
+		close(done)
+	}()
+	err = ta.AlertAction.Run([]string{ta.StanzaName, "--execute"}, bytes.NewReader(conf), io.Discard, pw)
+	pw.Close()
+	<-done
+	return err
+}
+
+// CapturedLogs returns the log lines written by the alert action during the last call to Run,
+// both on stderr and to splunkd's internal logging endpoint.
+func (ta *TestAlertAction) CapturedLogs() []string {
+	ta.logsMu.Lock()
+	defer ta.logsMu.Unlock()
+	logs := make([]string, len(ta.logs))
+	copy(logs, ta.logs)
+	return logs
+}
+
+// Close stops the emulated splunkd server and removes the temporary files created by the harness
+func (ta *TestAlertAction) Close() {
+	if ta.server != nil {
+		ta.server.Close()
+		ta.server = nil
+	}
+	for _, f := range ta.tempFiles {
+		os.Remove(f)
+	}
+	ta.tempFiles = nil
+}
@@ -0,0 +1,80 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prigio/splunk-go-sdk/alertactions"
+)
+
+func newNotifyAlert(t *testing.T) *alertactions.AlertAction {
+	aa, err := alertactions.New("notify", "Notify", "Notifies a remote API", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aa.RegisterNewParam("channel", "Channel", "Channel to be notified", "", "", alertactions.ParamTypeText, true)
+	aa.RegisterNewGlobalParam("notify", "api", "url", "API URL", "URL of the remote API", "", true)
+	aa.RegisterAlertFunc(func(aa *alertactions.AlertAction) error {
+		channel, _ := aa.GetParam("channel")
+		url, _ := aa.GetGlobalParam("url")
+		if aa.GetFirstResult()["host"] == "fail" {
+			return errors.New("remote API refused the notification")
+		}
+		return aa.IterateResults(func(row map[string]string) error {
+			aa.Log("INFO", "notifying url=%s channel=%s host=%s", url.GetValue(), channel.GetValue(), row["host"])
+			return nil
+		})
+	})
+	return aa
+}
+
+func TestTestAlertAction(t *testing.T) {
+	var requests []string
+	ta := NewTestAlertAction(newNotifyAlert(t)).
+		SetConfig(map[string]string{"channel": "#alerts"}).
+		SetResultsCSV([]map[string]string{{"host": "web-1"}, {"host": "web-2", "status": "500"}}).
+		SetSplunkdMock(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{"entry":[{"name":"url","content":"https://api.example.com"}]}`)
+		}))
+	defer ta.Close()
+
+	if err := ta.Run(); err != nil {
+		t.Fatalf("Run returned an error: %s\n%s", err.Error(), strings.Join(ta.CapturedLogs(), "\n"))
+	}
+	if len(requests) != 1 || requests[0] != "GET /servicesNS/admin/search/properties/notify/api" {
+		t.Errorf("the global parameter was not read through the mock: %v", requests)
+	}
+	logs := strings.Join(ta.CapturedLogs(), "\n")
+	for _, expected := range []string{"notifying url=https://api.example.com channel=#alerts host=web-1", "host=web-2", "Execution succeeded"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("log message '%s' was not captured. logs:\n%s", expected, logs)
+		}
+	}
+	if ta.GetFirstResult()["host"] != "web-1" || ta.GetSid() != TestSid {
+		t.Errorf("the run-time configuration was not provided to the alert action. result=%v sid=%s", ta.GetFirstResult(), ta.GetSid())
+	}
+
+	ta.SetFirstResult(map[string]interface{}{"host": "fail"})
+	if err := ta.Run(); err == nil {
+		t.Error("Run did not return the error of the alerting function")
+	}
+	if logs := strings.Join(ta.CapturedLogs(), "\n"); !strings.Contains(logs, "remote API refused the notification") {
+		t.Errorf("the failure was not logged. logs:\n%s", logs)
+	}
+}
+
+func TestTestAlertActionWithoutMock(t *testing.T) {
+	ta := NewTestAlertAction(newNotifyAlert(t)).SetConfig(map[string]string{"channel": "#alerts"})
+	defer ta.Close()
+	// the required global parameter cannot be read
+	if err := ta.Run(); err == nil {
+		t.Error("Run did not fail when splunkd could not provide the global parameter")
+	}
+	if len(ta.CapturedLogs()) == 0 {
+		t.Error("no logs were captured")
+	}
+}
@@ -1,14 +1,17 @@
 module alertaction_helloWorld
 
-go 1.20
+go 1.21
 
 replace github.com/prigio/splunk-go-sdk => ../../
 
 require github.com/prigio/splunk-go-sdk v1.2.0
 
 require (
+	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/google/uuid v1.2.0 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
+	golang.org/x/net v0.10.0 // indirect
 	golang.org/x/sys v0.9.0 // indirect
 	golang.org/x/term v0.9.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
 )
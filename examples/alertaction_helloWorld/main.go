@@ -33,7 +33,7 @@ func doAlert(aa *alertactions.AlertAction) error {
 
 func main() {
 	// Prepare the script
-	script, err := alertactions.New("helloWorld", "Hello world alert", "Writes the text provided as a parameter into the chosen file.", "missingIcon.png")
+	script, err := alertactions.New("hello-world", "Hello world alert", "Writes the text provided as a parameter into the chosen file.", "missingIcon.png")
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		// this will NOT run deferred function, so in case we have any, need to take care about that. Simply: do NOT use such functions within the main() ;-)
@@ -1,12 +1,17 @@
 module github.com/prigio/splunk-go-sdk
 
-go 1.20
+go 1.21
 
 require (
 	github.com/google/go-querystring v1.1.0
 	github.com/google/uuid v1.2.0
 	github.com/mattn/go-isatty v0.0.19
+	golang.org/x/net v0.10.0
 	golang.org/x/term v0.9.0
+	golang.org/x/time v0.5.0
 )
 
-require golang.org/x/sys v0.9.0 // indirect
+require (
+	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+)
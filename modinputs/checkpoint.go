@@ -0,0 +1,278 @@
+package modinputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+/* This file defines the manager of the checkpoints used by modular inputs to persist their state across executions */
+
+// ErrNoCheckpoint is returned when reading a checkpoint which has never been written, has been deleted or has expired
+var ErrNoCheckpoint = errors.New("checkpoint not found")
+
+// CheckpointManager reads and writes checkpoints within a directory, one file per stanza.
+// This is typically used to track the last processed cursor/timestamp of a stanza across executions and Splunk restarts.
+// Writes are atomic: data is written to a temporary file, which is then renamed.
+// A CheckpointManager can be used concurrently by multiple goroutines.
+type CheckpointManager struct {
+	dir string
+	mu  sync.RWMutex
+	// checkpoints older than ttl are considered as not existing. If 0, checkpoints never expire. See WithTTL
+	ttl time.Duration
+	// if gzip is true, checkpoints are stored gzip-compressed. See WithGzip
+	gzip bool
+	// ext is the extension of the checkpoint files. See WithFileExtension
+	ext string
+}
+
+// CheckpointOption configures a CheckpointManager created with NewCheckpointManager
+type CheckpointOption func(cm *CheckpointManager)
+
+// WithTTL makes checkpoints older than d be treated as if they did not exist, e.g. to reseed an input after a long pause.
+// The age of a checkpoint is given by the last time it was written.
+func WithTTL(d time.Duration) CheckpointOption {
+	return func(cm *CheckpointManager) {
+		cm.ttl = d
+	}
+}
+
+// WithGzip enables the gzip compression of the checkpoints, reducing the disk usage of large checkpoints.
+// Unless configured with WithFileExtension, compressed checkpoints have extension '.json.gz'.
+func WithGzip(enabled bool) CheckpointOption {
+	return func(cm *CheckpointManager) {
+		cm.gzip = enabled
+	}
+}
+
+// WithFileExtension configures the extension of the checkpoint files. The default is '.json'
+func WithFileExtension(ext string) CheckpointOption {
+	return func(cm *CheckpointManager) {
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		cm.ext = ext
+	}
+}
+
+// NewCheckpointManager returns a manager storing checkpoints within dir
+func NewCheckpointManager(dir string, opts ...CheckpointOption) *CheckpointManager {
+	cm := &CheckpointManager{dir: dir}
+	for _, opt := range opts {
+		opt(cm)
+	}
+	if cm.ext == "" {
+		cm.ext = ".json"
+		if cm.gzip {
+			cm.ext = ".json.gz"
+		}
+	}
+	return cm
+}
+
+// GetCheckpointManager returns a manager storing checkpoints within the checkpoint directory provided by Splunk at run-time
+func (mi *ModularInput) GetCheckpointManager() *CheckpointManager {
+	if mi.checkpointManager == nil || mi.checkpointManager.dir != mi.checkpointDir {
+		mi.checkpointManager = NewCheckpointManager(mi.checkpointDir)
+	}
+	return mi.checkpointManager
+}
+
+// getPath returns the path of the checkpoint file of stanzaName. The stanza name is URL-encoded, since it contains '://'
+func (cm *CheckpointManager) getPath(stanzaName string) (string, error) {
+	if cm.dir == "" {
+		return "", fmt.Errorf("no checkpoint directory available")
+	}
+	if stanzaName == "" {
+		return "", utils.NewErrInvalidParam("checkpoint", nil, "'stanzaName' cannot be empty")
+	}
+	return filepath.Join(cm.dir, url.QueryEscape(stanzaName)+cm.ext), nil
+}
+
+// isExpired returns true if the checkpoint file described by fi is older than the TTL
+func (cm *CheckpointManager) isExpired(fi os.FileInfo) bool {
+	return cm.ttl > 0 && time.Since(fi.ModTime()) > cm.ttl
+}
+
+// Read returns the contents of the checkpoint of stanzaName. If no checkpoint exists, ErrNoCheckpoint is returned.
+func (cm *CheckpointManager) Read(stanzaName string) ([]byte, error) {
+	path, err := cm.getPath(stanzaName)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint read: %w", err)
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("checkpoint read '%s': %w", stanzaName, ErrNoCheckpoint)
+	} else if err != nil {
+		return nil, fmt.Errorf("checkpoint read '%s': %w", stanzaName, err)
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("checkpoint read '%s': %w", stanzaName, err)
+	} else if cm.isExpired(fi) {
+		return nil, fmt.Errorf("checkpoint read '%s': expired after %s: %w", stanzaName, cm.ttl, ErrNoCheckpoint)
+	}
+	var r io.Reader = f
+	if cm.gzip {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint read '%s': %w", stanzaName, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint read '%s': %w", stanzaName, err)
+	}
+	return data, nil
+}
+
+// Write atomically replaces the checkpoint of stanzaName with data
+func (cm *CheckpointManager) Write(stanzaName string, data []byte) error {
+	path, err := cm.getPath(stanzaName)
+	if err != nil {
+		return fmt.Errorf("checkpoint write: %w", err)
+	}
+	if cm.gzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err = gz.Write(data); err == nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+		}
+		data = buf.Bytes()
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if err = os.MkdirAll(cm.dir, 0o750); err != nil {
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	// the temporary file is created within the same directory, as rename is only atomic within the same filesystem
+	tmp, err := os.CreateTemp(cm.dir, ".checkpoint-*.tmp")
+	if err != nil {
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("checkpoint write '%s': %w", stanzaName, err)
+	}
+	return nil
+}
+
+// Delete removes the checkpoint of stanzaName. Deleting a non-existing checkpoint is not an error.
+func (cm *CheckpointManager) Delete(stanzaName string) error {
+	path, err := cm.getPath(stanzaName)
+	if err != nil {
+		return fmt.Errorf("checkpoint delete: %w", err)
+	}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("checkpoint delete '%s': %w", stanzaName, err)
+	}
+	return nil
+}
+
+// ReadJSON reads the checkpoint of stanzaName and unmarshals it into v. If no checkpoint exists, ErrNoCheckpoint is returned.
+func (cm *CheckpointManager) ReadJSON(stanzaName string, v any) error {
+	data, err := cm.Read(stanzaName)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("checkpoint readJSON '%s': %w", stanzaName, err)
+	}
+	return nil
+}
+
+// WriteJSON marshals v to JSON and atomically stores it as checkpoint of stanzaName
+func (cm *CheckpointManager) WriteJSON(stanzaName string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("checkpoint writeJSON '%s': %w", stanzaName, err)
+	}
+	return cm.Write(stanzaName, data)
+}
+
+// Age returns how long ago the checkpoint of stanzaName was written. If no checkpoint exists, ErrNoCheckpoint is returned.
+// The age of expired checkpoints is returned as well.
+func (cm *CheckpointManager) Age(stanzaName string) (time.Duration, error) {
+	path, err := cm.getPath(stanzaName)
+	if err != nil {
+		return 0, fmt.Errorf("checkpoint age: %w", err)
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("checkpoint age '%s': %w", stanzaName, ErrNoCheckpoint)
+	} else if err != nil {
+		return 0, fmt.Errorf("checkpoint age '%s': %w", stanzaName, err)
+	}
+	return time.Since(fi.ModTime()), nil
+}
+
+// IsExpired returns true if the checkpoint of stanzaName is older than the TTL configured with WithTTL.
+// Missing checkpoints are not expired.
+func (cm *CheckpointManager) IsExpired(stanzaName string) bool {
+	age, err := cm.Age(stanzaName)
+	return err == nil && cm.ttl > 0 && age > cm.ttl
+}
+
+// ListCheckpoints returns the names of the stanzas having a checkpoint which has not expired
+func (cm *CheckpointManager) ListCheckpoints() ([]string, error) {
+	if cm.dir == "" {
+		return nil, fmt.Errorf("checkpoint list: no checkpoint directory available")
+	}
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	entries, err := os.ReadDir(cm.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("checkpoint list: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		encoded, found := strings.CutSuffix(e.Name(), cm.ext)
+		if !found || e.IsDir() || strings.HasPrefix(e.Name(), ".checkpoint-") {
+			continue
+		}
+		name, err := url.QueryUnescape(encoded)
+		if err != nil {
+			continue
+		}
+		if fi, err := e.Info(); err != nil || cm.isExpired(fi) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
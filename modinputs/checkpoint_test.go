@@ -0,0 +1,152 @@
+package modinputs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCheckpointReadWrite(t *testing.T) {
+	mi, _ := New("test-checkpoint", "Test checkpoint", "")
+	mi.checkpointDir = t.TempDir()
+	cm := mi.GetCheckpointManager()
+	stanza := "test-checkpoint://my input"
+
+	if _, err := cm.Read(stanza); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Read of a missing checkpoint did not return ErrNoCheckpoint: %v", err)
+	}
+	type cursor struct {
+		LastTime int64  `json:"last_time"`
+		Token    string `json:"token"`
+	}
+	if err := cm.WriteJSON(stanza, cursor{LastTime: 1700000000, Token: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	got := cursor{}
+	if err := cm.ReadJSON(stanza, &got); err != nil || got.LastTime != 1700000000 || got.Token != "abc" {
+		t.Errorf("ReadJSON returned wrong checkpoint: %+v err=%v", got, err)
+	}
+
+	// the stanza name is encoded, and no temporary files are left behind
+	entries, _ := os.ReadDir(mi.checkpointDir)
+	if len(entries) != 1 || strings.ContainsAny(entries[0].Name(), ":/ ") || !strings.HasSuffix(entries[0].Name(), ".json") {
+		t.Errorf("unexpected files within the checkpoint directory: %v", entries)
+	}
+
+	if err := cm.Delete(stanza); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.Read(stanza); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Read of a deleted checkpoint did not return ErrNoCheckpoint: %v", err)
+	}
+	if err := cm.Delete(stanza); err != nil {
+		t.Errorf("Delete of a missing checkpoint returned an error: %v", err)
+	}
+}
+
+func TestCheckpointConcurrentAccess(t *testing.T) {
+	for _, cm := range []*CheckpointManager{NewCheckpointManager(t.TempDir()), NewCheckpointManager(t.TempDir(), WithGzip(true))} {
+		stanza := "test://concurrent"
+		wg := sync.WaitGroup{}
+		errs := make(chan error, 100)
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				errs <- cm.Write(stanza, []byte(fmt.Sprintf(`{"value":%d}`, i)))
+			}(i)
+			go func() {
+				defer wg.Done()
+				var v struct{ Value int }
+				// readers must either see no checkpoint or a complete one, never a partially written file
+				if err := cm.ReadJSON(stanza, &v); err != nil && !errors.Is(err, ErrNoCheckpoint) {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("gzip=%t: %s", cm.gzip, err.Error())
+			}
+		}
+	}
+}
+
+func TestCheckpointTTL(t *testing.T) {
+	dir := t.TempDir()
+	cm := NewCheckpointManager(dir, WithTTL(time.Hour), WithFileExtension("ckpt"))
+	if err := cm.Write("test://recent", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Write("test://old", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if cm.IsExpired("test://recent") || cm.IsExpired("test://missing") {
+		t.Error("IsExpired reported a recent or missing checkpoint as expired")
+	}
+	if _, err := cm.Age("test://missing"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Age of a missing checkpoint did not return ErrNoCheckpoint: %v", err)
+	}
+
+	// the checkpoint is aged by moving its modification time into the past
+	twoHoursAgo := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, url.QueryEscape("test://old")+".ckpt"), twoHoursAgo, twoHoursAgo); err != nil {
+		t.Fatal(err)
+	}
+	if age, err := cm.Age("test://old"); err != nil || age < 2*time.Hour {
+		t.Errorf("wrong age of the checkpoint. age=%s err=%v", age, err)
+	}
+	if !cm.IsExpired("test://old") {
+		t.Error("IsExpired did not report the old checkpoint as expired")
+	}
+	if _, err := cm.Read("test://old"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Read of an expired checkpoint did not return ErrNoCheckpoint: %v", err)
+	}
+	if names, err := cm.ListCheckpoints(); err != nil || len(names) != 1 || names[0] != "test://recent" {
+		t.Errorf("ListCheckpoints returned wrong checkpoints. names=%v err=%v", names, err)
+	}
+	// writing again reseeds the checkpoint
+	if err := cm.Write("test://old", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cm.Read("test://old"); err != nil {
+		t.Errorf("Read of a rewritten checkpoint returned an error: %v", err)
+	}
+}
+
+func TestCheckpointGzip(t *testing.T) {
+	dir := t.TempDir()
+	cm := NewCheckpointManager(dir, WithGzip(true))
+	stanza := "test://compressed"
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("event-id-%06d", i)
+	}
+	if err := cm.WriteJSON(stanza, ids); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	if err := cm.ReadJSON(stanza, &got); err != nil || len(got) != 1000 || got[999] != ids[999] {
+		t.Fatalf("ReadJSON did not round-trip the compressed checkpoint. len=%d err=%v", len(got), err)
+	}
+	fi, err := os.Stat(filepath.Join(dir, url.QueryEscape(stanza)+".json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := json.Marshal(ids)
+	if fi.Size() >= int64(len(plain)) {
+		t.Errorf("checkpoint was not compressed. size=%d uncompressed=%d", fi.Size(), len(plain))
+	}
+	if names, err := cm.ListCheckpoints(); err != nil || len(names) != 1 || names[0] != stanza {
+		t.Errorf("ListCheckpoints returned wrong checkpoints. names=%v err=%v", names, err)
+	}
+}
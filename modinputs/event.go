@@ -2,10 +2,13 @@ package modinputs
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prigio/splunk-go-sdk/utils"
@@ -14,38 +17,32 @@ import (
 // SplunkEvent is structure used to feed log data to splunk using the XML streaming mode.
 // See: https://docs.splunk.com/Documentation/Splunk/8.1.1/AdvancedDev/ModInputsStream
 type SplunkEvent struct {
-	Unbroken       bool // unbroken events are events whose data attribute spans multiple <event> xml elements
-	Done           bool // the last of a series of unbroken events uses <done/> to signalize the event is now complete
-	Time           time.Time
-	cachedTime     time.Time
-	Data           string
-	SourceType     string
-	Index          string
-	Host           string
-	Source         string
-	Stanza         string
-	cachedEpochStr string
+	Unbroken   bool // unbroken events are events whose data attribute spans multiple <event> xml elements
+	Done       bool // the last of a series of unbroken events uses <done/> to signalize the event is now complete
+	Time       time.Time
+	Data       string
+	SourceType string
+	Index      string
+	Host       string
+	Source     string
+	Stanza     string
 }
 
 // EpochTime reads the Time parameters of SplunkEvent se and returns an floating point
 // representation of the time expressed as Epoch with millisecond precision
+// The value is recomputed at every call: caching it within the event is not goroutine-safe,
+// and the formatting cost is small compared to the generation of the whole XML (see BenchmarkEpochTimeStr).
 func (se *SplunkEvent) epochTimeStr() string {
-	// checks if the event timestamp changed wrt the cached version
-	if se.Time != se.cachedTime {
-		// regenerate the cached epoch representation
-		se.cachedTime = se.Time
-		se.cachedEpochStr = strconv.FormatFloat(utils.GetEpoch(se.Time), 'f', 3, 64)
-	}
-	return se.cachedEpochStr
+	return strconv.FormatFloat(utils.GetEpoch(se.Time), 'f', 3, 64)
 }
 
 // writeOut is a private function which allows the modular input to skip counting the events emitted.
 // useful for internal logging, which is not counter.
-func (se *SplunkEvent) writeOut() (cnt int, err error) {
+func (se *SplunkEvent) writeOut(w io.Writer) (cnt int, err error) {
 	if xmlStr, err := se.xml(); err != nil {
 		return -1, err
 	} else {
-		return os.Stdout.WriteString(xmlStr)
+		return io.WriteString(w, xmlStr)
 	}
 }
 
@@ -98,7 +95,8 @@ func (se *SplunkEvent) xml() (string, error) {
 	// It would be easy to use xml.Marshal, but tests revelaed it takes 30% time to generate events than this method.
 	// For the xml needed to generate the Scheme it is not important, as that is only done once per execution.
 	// But the events logging is much more time-critical.
-	if se.Data == "" {
+	if se.Data == "" && !se.Done {
+		// an empty <done/> event is admissible, as it marks the end of a series of unbroken events
 		return "", fmt.Errorf("events must have at least the data field set to be written to XML")
 	}
 
@@ -165,6 +163,341 @@ func (se *SplunkEvent) xml() (string, error) {
 	return buf.String(), nil
 }
 
+// ErrBuilderFinished is returned when using an UnbrokenEventBuilder or a MultiLineEventWriter after its Finish() or Abort() methods have been called
+var ErrBuilderFinished = errors.New("unbroken event already finished")
+
+// ErrMultiLineEventFinished is the same as ErrBuilderFinished.
+//
+// Deprecated: use ErrBuilderFinished, which is returned by both MultiLineEventWriter and UnbrokenEventBuilder.
+var ErrMultiLineEventFinished = ErrBuilderFinished
+
+// UnbrokenEventBuilder emits one logical event whose data is split into chunks, each written as an <event unbroken="1"> XML element.
+// Contrarily to MultiLineEventWriter, the chunks are concatenated by Splunk as-is, without adding newlines.
+// The event is completed by Finish, or by Abort if the data cannot be completed.
+// Use [ModularInput.NewUnbrokenEventBuilder] to instantiate it.
+// See: https://docs.splunk.com/Documentation/Splunk/8.1.1/AdvancedDev/ModInputsStream#Unbroken_events
+type UnbrokenEventBuilder struct {
+	mi    *ModularInput
+	event SplunkEvent
+	// number of chunks written so far
+	chunks int
+	// done is true once the <done/> marker has been written
+	done bool
+}
+
+// NewUnbrokenEventBuilder returns a builder of an unbroken event, whose attributes are based on the ones of NewDefaultEvent(stanza)
+func (mi *ModularInput) NewUnbrokenEventBuilder(stanza *Stanza) *UnbrokenEventBuilder {
+	ev := mi.NewDefaultEvent(stanza)
+	ev.Unbroken = true
+	ev.Done = false
+	return &UnbrokenEventBuilder{mi: mi, event: *ev}
+}
+
+// AppendData writes out a chunk of the data of the event. data cannot be empty.
+func (ueb *UnbrokenEventBuilder) AppendData(data string) error {
+	if ueb.done {
+		return ErrBuilderFinished
+	}
+	if data == "" {
+		return utils.NewErrInvalidParam("unbrokenEventBuilder.AppendData", nil, "'data' cannot be empty")
+	}
+	return ueb.write(data, false)
+}
+
+// Finish writes out the last chunk of the data of the event along with the <done/> marker which completes it.
+// data can be empty, if all the data has already been written with AppendData.
+func (ueb *UnbrokenEventBuilder) Finish(data string) error {
+	if ueb.done {
+		return ErrBuilderFinished
+	}
+	if data == "" && ueb.chunks == 0 {
+		return utils.NewErrInvalidParam("unbrokenEventBuilder.Finish", nil, "'data' cannot be empty for an event without chunks")
+	}
+	return ueb.write(data, true)
+}
+
+// Abort writes out an empty <done/> marker, so that Splunk does not wait for further chunks of an event which cannot be completed.
+// The chunks already written are indexed as they are. Nothing is written if no chunk has been written yet.
+func (ueb *UnbrokenEventBuilder) Abort() error {
+	if ueb.done {
+		return ErrBuilderFinished
+	}
+	if ueb.chunks == 0 {
+		ueb.done = true
+		return nil
+	}
+	return ueb.write("", true)
+}
+
+// write outputs a chunk of the event, possibly marking it as done
+func (ueb *UnbrokenEventBuilder) write(data string, done bool) error {
+	if ueb.done {
+		return ErrBuilderFinished
+	}
+	ueb.event.Data = data
+	ueb.event.Done = done
+	ueb.mi.writeMu.Lock()
+	defer ueb.mi.writeMu.Unlock()
+	if _, err := ueb.event.writeOut(ueb.mi.getStdout()); err != nil {
+		return err
+	}
+	ueb.chunks++
+	if done {
+		ueb.done = true
+		// the whole unbroken event is counted only once
+		ueb.mi.countDataEvents(1)
+		ueb.mi.countDataEventsByStanzaName(ueb.event.Stanza, 1)
+	}
+	return nil
+}
+
+// MultiLineEventWriter emits one logical event which spans multiple <event unbroken="1"> XML elements.
+// It is an UnbrokenEventBuilder whose chunks are lines: each appended line is written out immediately, terminated by a newline.
+// The event is completed by calling Finish(), or by Abort() if the data cannot be completed.
+// Use [ModularInput.NewMultiLineEvent] to instantiate it.
+// See: https://docs.splunk.com/Documentation/Splunk/8.1.1/AdvancedDev/ModInputsStream#Unbroken_events
+type MultiLineEventWriter struct {
+	builder UnbrokenEventBuilder
+}
+
+// NewMultiLineEvent returns a writer for an unbroken multi-line event, whose attributes are based on the ones of NewDefaultEvent(stanza)
+func (mi *ModularInput) NewMultiLineEvent(stanza *Stanza) *MultiLineEventWriter {
+	return &MultiLineEventWriter{builder: *mi.NewUnbrokenEventBuilder(stanza)}
+}
+
+// AppendLine writes out a line of the multi-line event using the event's current timestamp.
+// A newline is added to data, as Splunk concatenates the data of unbroken events as-is.
+func (mlw *MultiLineEventWriter) AppendLine(data string) error {
+	return mlw.AppendLineWithTime(data, mlw.builder.event.Time)
+}
+
+// AppendLineWithTime writes out a line of the multi-line event using timestamp t
+func (mlw *MultiLineEventWriter) AppendLineWithTime(data string, t time.Time) error {
+	if mlw.builder.done {
+		return ErrBuilderFinished
+	}
+	mlw.builder.event.Time = t
+	return mlw.builder.AppendData(data + "\n")
+}
+
+// Finish writes out the <done/> marker which completes the multi-line event.
+// Further calls to the writer return ErrBuilderFinished
+func (mlw *MultiLineEventWriter) Finish() error {
+	return mlw.builder.write("", true)
+}
+
+// Abort completes the multi-line event as UnbrokenEventBuilder.Abort does
+func (mlw *MultiLineEventWriter) Abort() error {
+	return mlw.builder.Abort()
+}
+
+// kvValueEscaper escapes the values of the key="value" pairs generated by AddField
+var kvValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// kvKeySanitizer replaces the characters which cannot be part of a key of the key="value" pairs generated by AddField
+var kvKeySanitizer = strings.NewReplacer(" ", "_", "\t", "_", "\n", "_", "\r", "_", "=", "_", `"`, "_")
+
+// formatKV returns key="value", with double-quotes and backslashes within value escaped.
+// Newlines are kept as-is within the quoted value.
+func formatKV(key, value string) string {
+	return kvKeySanitizer.Replace(key) + `="` + kvValueEscaper.Replace(value) + `"`
+}
+
+// AddField appends a key="value" pair to the Data of the event, separated by a space from the existing data.
+// Double-quotes and backslashes within value are escaped, and characters not allowed within key (spaces, '=', '"') are replaced by '_'.
+// The event is returned to allow chaining calls.
+func (se *SplunkEvent) AddField(key, value string) *SplunkEvent {
+	if se.Data != "" {
+		se.Data += " "
+	}
+	se.Data += formatKV(key, value)
+	return se
+}
+
+// SetFields replaces the Data of the event with the key="value" pairs of fields, sorted by key.
+// See AddField for the escaping rules.
+func (se *SplunkEvent) SetFields(fields map[string]string) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	se.Data = ""
+	for _, k := range keys {
+		se.AddField(k, fields[k])
+	}
+}
+
+// EventBuilder provides a fluent interface to create a SplunkEvent whose data is made of key="value" pairs.
+//
+//	ev := NewEventBuilder().WithIndex("main").WithField("user", "admin").WithField("action", "login").Build()
+type EventBuilder struct {
+	event SplunkEvent
+}
+
+// NewEventBuilder returns a builder for an event with the current time
+func NewEventBuilder() *EventBuilder {
+	return &EventBuilder{event: SplunkEvent{Time: time.Now()}}
+}
+
+// NewEventBuilder returns a builder for an event based on the attributes of NewDefaultEvent(stanza)
+func (mi *ModularInput) NewEventBuilder(stanza *Stanza) *EventBuilder {
+	return &EventBuilder{event: *mi.NewDefaultEvent(stanza)}
+}
+
+func (eb *EventBuilder) WithTime(t time.Time) *EventBuilder {
+	eb.event.Time = t
+	return eb
+}
+
+func (eb *EventBuilder) WithSource(source string) *EventBuilder {
+	eb.event.Source = source
+	return eb
+}
+
+func (eb *EventBuilder) WithSourcetype(sourcetype string) *EventBuilder {
+	eb.event.SourceType = sourcetype
+	return eb
+}
+
+func (eb *EventBuilder) WithIndex(index string) *EventBuilder {
+	eb.event.Index = index
+	return eb
+}
+
+func (eb *EventBuilder) WithHost(host string) *EventBuilder {
+	eb.event.Host = host
+	return eb
+}
+
+// WithField appends a key="value" pair to the data of the event. Fields are emitted in the order they are added.
+func (eb *EventBuilder) WithField(key, value string) *EventBuilder {
+	eb.event.AddField(key, value)
+	return eb
+}
+
+// Build returns a new event with the configured attributes. The builder can be reused afterwards.
+func (eb *EventBuilder) Build() *SplunkEvent {
+	se := eb.event
+	return &se
+}
+
+// EventPool hands out reusable SplunkEvent instances, reducing the allocations
+// performed by modular inputs generating large amounts of events.
+// The pool can be used concurrently by multiple goroutines.
+type EventPool struct {
+	defaults SplunkEvent
+	pool     sync.Pool
+}
+
+// NewEventPool returns an EventPool whose events are initialized with the attributes of defaults.
+// The Data and Time attributes of defaults are ignored.
+func NewEventPool(defaults SplunkEvent) *EventPool {
+	defaults.Data = ""
+	defaults.Time = time.Time{}
+	ep := &EventPool{defaults: defaults}
+	ep.pool.New = func() any {
+		se := ep.defaults
+		return &se
+	}
+	return ep
+}
+
+// Get returns an event from the pool, initialized with the defaults of the pool and the current time.
+// Once the event has been written, it should be returned to the pool using Put.
+func (ep *EventPool) Get() *SplunkEvent {
+	se := ep.pool.Get().(*SplunkEvent)
+	se.Time = time.Now()
+	return se
+}
+
+// Put returns an event to the pool. Data is cleared, Time is reset to its zero value and
+// all other attributes are restored to the defaults of the pool.
+// The event must not be used after having been returned to the pool.
+func (ep *EventPool) Put(se *SplunkEvent) {
+	if se == nil {
+		return
+	}
+	*se = ep.defaults
+	ep.pool.Put(se)
+}
+
+// EventBuffer accumulates events to be written out with ModularInput.WriteToSplunkBatch,
+// reducing the overhead of writing events one at a time.
+// The buffer can be used concurrently by multiple goroutines.
+type EventBuffer struct {
+	mi      *ModularInput // modular input used for automatic flushes. If nil, the buffer is only flushed explicitly
+	maxSize int           // number of events triggering an automatic flush. 0 disables the check
+	maxAge  time.Duration // age of the oldest buffered event triggering an automatic flush. 0 disables the check
+
+	mu        sync.Mutex
+	events    []*SplunkEvent
+	oldestAdd time.Time // when the first event currently in the buffer was added
+}
+
+// NewEventBuffer returns an EventBuffer which automatically flushes its events to mi
+// as soon as it contains at least maxSize events, or the oldest buffered event has been added more than maxAge ago.
+// The age is checked when adding events: no background flushing is performed.
+// Use 0 for maxSize and/or maxAge to disable the corresponding automatic flush.
+func (mi *ModularInput) NewEventBuffer(maxSize int, maxAge time.Duration) (*EventBuffer, error) {
+	if maxSize < 0 {
+		return nil, utils.NewErrInvalidParam("NewEventBuffer", nil, "'maxSize' cannot be negative")
+	}
+	if maxAge < 0 {
+		return nil, utils.NewErrInvalidParam("NewEventBuffer", nil, "'maxAge' cannot be negative")
+	}
+	return &EventBuffer{mi: mi, maxSize: maxSize, maxAge: maxAge}, nil
+}
+
+// Add appends an event to the buffer. If the conditions for an automatic flush are met,
+// the buffered events are written out and the error of the flush, if any, is returned.
+func (eb *EventBuffer) Add(se *SplunkEvent) error {
+	if se == nil {
+		return utils.NewErrInvalidParam("EventBuffer.Add", nil, "'se' cannot be nil")
+	}
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	if len(eb.events) == 0 {
+		eb.oldestAdd = time.Now()
+	}
+	eb.events = append(eb.events, se)
+	if eb.mi == nil {
+		return nil
+	}
+	if (eb.maxSize > 0 && len(eb.events) >= eb.maxSize) || (eb.maxAge > 0 && time.Since(eb.oldestAdd) >= eb.maxAge) {
+		return eb.flush(eb.mi)
+	}
+	return nil
+}
+
+// Len returns the number of events currently in the buffer
+func (eb *EventBuffer) Len() int {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return len(eb.events)
+}
+
+// Flush writes out all the buffered events to mi and empties the buffer.
+// If the events cannot be written, they are discarded anyway and the error is returned.
+func (eb *EventBuffer) Flush(mi *ModularInput) error {
+	if mi == nil {
+		return utils.NewErrInvalidParam("EventBuffer.Flush", nil, "'mi' cannot be nil")
+	}
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return eb.flush(mi)
+}
+
+// flush is the non-locking version of Flush
+func (eb *EventBuffer) flush(mi *ModularInput) error {
+	if len(eb.events) == 0 {
+		return nil
+	}
+	events := eb.events
+	eb.events = nil
+	return mi.WriteToSplunkBatch(events)
+}
+
 /*
 
 // string generates a plain-text representation of the SplunkEvent.
@@ -1,8 +1,15 @@
 package modinputs
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -93,3 +100,454 @@ func BenchmarkXML(b *testing.B) {
 	}
 
 }
+
+func TestMultiLineEvent(t *testing.T) {
+	mi := &ModularInput{StanzaName: "teststanzaname"}
+	st := Stanza{Name: "testscheme://testinputname"}
+
+	// capture what gets written to Stdout
+	stdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	mlw := mi.NewMultiLineEvent(&st)
+	mlw.AppendLine("first line")
+	mlw.AppendLineWithTime("second line", time.Now())
+	finishErr := mlw.Finish()
+	afterErr := mlw.AppendLine("too late")
+	abortErr := mlw.Abort()
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+
+	if finishErr != nil {
+		t.Errorf("MultiLineEventWriter.Finish returned an error: %s", finishErr.Error())
+	}
+	if !errors.Is(afterErr, ErrMultiLineEventFinished) {
+		t.Errorf("MultiLineEventWriter.AppendLine did not return ErrMultiLineEventFinished after Finish. got=%v", afterErr)
+	}
+	if !errors.Is(abortErr, ErrBuilderFinished) {
+		t.Errorf("MultiLineEventWriter.Abort did not return ErrBuilderFinished after Finish. got=%v", abortErr)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("MultiLineEventWriter wrote the wrong number of XML events. expected=3 got=%d. XML: %s", len(lines), out)
+	}
+	for _, l := range lines {
+		if !strings.Contains(l, `unbroken="1"`) {
+			t.Errorf("MultiLineEventWriter wrote an event without unbroken=\"1\": %s", l)
+		}
+	}
+	if strings.Contains(lines[0], "<done/>") || !strings.Contains(lines[2], "<done/>") {
+		t.Errorf("MultiLineEventWriter did not write <done/> only on the last event. XML: %s", out)
+	}
+	if mi.cntDataEventsGeneratedTotal != 1 {
+		t.Errorf("MultiLineEventWriter did not count the multi-line event once. expected=1 got=%d", mi.cntDataEventsGeneratedTotal)
+	}
+}
+
+func TestUnbrokenEventBuilder(t *testing.T) {
+	stdout := &strings.Builder{}
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: stdout}
+	st := Stanza{Name: "testscheme://testinputname"}
+
+	ueb := mi.NewUnbrokenEventBuilder(&st)
+	if err := ueb.AppendData(""); err == nil {
+		t.Error("UnbrokenEventBuilder.AppendData accepted empty data")
+	}
+	for _, chunk := range []string{"<payload id=", `"42">`, "a & b"} {
+		if err := ueb.AppendData(chunk); err != nil {
+			t.Fatalf("UnbrokenEventBuilder.AppendData returned an error: %s", err.Error())
+		}
+	}
+	if err := ueb.Finish("</payload>"); err != nil {
+		t.Fatalf("UnbrokenEventBuilder.Finish returned an error: %s", err.Error())
+	}
+	if err := ueb.AppendData("too late"); !errors.Is(err, ErrBuilderFinished) {
+		t.Errorf("UnbrokenEventBuilder.AppendData did not return ErrBuilderFinished after Finish. got=%v", err)
+	}
+	if err := ueb.Abort(); !errors.Is(err, ErrBuilderFinished) {
+		t.Errorf("UnbrokenEventBuilder.Abort did not return ErrBuilderFinished after Finish. got=%v", err)
+	}
+
+	// reconstruct the event as Splunk would do
+	dec := xml.NewDecoder(strings.NewReader("<stream>" + stdout.String() + "</stream>"))
+	var stream struct {
+		Events []struct {
+			Unbroken string    `xml:"unbroken,attr"`
+			Data     string    `xml:"data"`
+			Done     *struct{} `xml:"done"`
+		} `xml:"event"`
+	}
+	if err := dec.Decode(&stream); err != nil {
+		t.Fatalf("invalid XML written: %s. XML: %s", err.Error(), stdout.String())
+	}
+	if len(stream.Events) != 4 {
+		t.Fatalf("UnbrokenEventBuilder wrote the wrong number of XML events. expected=4 got=%d. XML: %s", len(stream.Events), stdout.String())
+	}
+	data := ""
+	for i, ev := range stream.Events {
+		if ev.Unbroken != "1" {
+			t.Errorf("UnbrokenEventBuilder wrote an event without unbroken=\"1\": %+v", ev)
+		}
+		if (ev.Done != nil) != (i == 3) {
+			t.Errorf("UnbrokenEventBuilder did not write <done/> only on the last event. XML: %s", stdout.String())
+		}
+		data += ev.Data
+	}
+	if data != `<payload id="42">a & b</payload>` {
+		t.Errorf("wrong data of the reconstructed event: %s", data)
+	}
+	if mi.cntDataEventsGeneratedTotal != 1 {
+		t.Errorf("UnbrokenEventBuilder did not count the unbroken event once. expected=1 got=%d", mi.cntDataEventsGeneratedTotal)
+	}
+
+	// an aborted event is terminated by an empty <done/>
+	stdout.Reset()
+	ueb = mi.NewUnbrokenEventBuilder(&st)
+	ueb.AppendData("partial")
+	if err := ueb.Abort(); err != nil {
+		t.Fatalf("UnbrokenEventBuilder.Abort returned an error: %s", err.Error())
+	}
+	if !strings.HasSuffix(stdout.String(), "<data></data><done/></event>\n") {
+		t.Errorf("UnbrokenEventBuilder.Abort did not write an empty <done/> event. XML: %s", stdout.String())
+	}
+	if err := ueb.Finish("more"); !errors.Is(err, ErrBuilderFinished) {
+		t.Errorf("UnbrokenEventBuilder.Finish did not return ErrBuilderFinished after Abort. got=%v", err)
+	}
+}
+
+func BenchmarkEpochTimeStr(b *testing.B) {
+	se := &SplunkEvent{Time: time.Now()}
+	for n := 0; n < b.N; n++ {
+		se.epochTimeStr()
+	}
+}
+
+func newTestBatchEvents(n int) []*SplunkEvent {
+	events := make([]*SplunkEvent, n)
+	for i := range events {
+		events[i] = &SplunkEvent{
+			Time:       time.Now(),
+			Data:       "event number " + strconv.Itoa(i),
+			SourceType: "testsourcetype",
+			Index:      "testindex",
+			Stanza:     "testscheme://testinput",
+		}
+	}
+	return events
+}
+
+func TestWriteToSplunkBatch(t *testing.T) {
+	var out bytes.Buffer
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: &out}
+
+	if err := mi.WriteToSplunkBatch(newTestBatchEvents(5)); err != nil {
+		t.Fatalf("WriteToSplunkBatch returned an error: %s", err.Error())
+	}
+	if cnt := strings.Count(out.String(), "<event"); cnt != 5 {
+		t.Errorf("WriteToSplunkBatch wrote the wrong number of events. expected=5 got=%d", cnt)
+	}
+	if mi.cntDataEventsGeneratedTotal != 5 || mi.cntDataEventsGeneratedbyStanza != 5 {
+		t.Errorf("WriteToSplunkBatch did not count the events. expected=5 got total=%d stanza=%d", mi.cntDataEventsGeneratedTotal, mi.cntDataEventsGeneratedbyStanza)
+	}
+
+	// an invalid event prevents the whole batch from being written
+	out.Reset()
+	events := newTestBatchEvents(3)
+	events[1].Data = ""
+	if err := mi.WriteToSplunkBatch(events); err == nil {
+		t.Error("WriteToSplunkBatch did not return an error for a batch containing an event without data")
+	}
+	if out.Len() != 0 || mi.cntDataEventsGeneratedTotal != 5 {
+		t.Errorf("WriteToSplunkBatch wrote out part of an invalid batch. written=%q cnt=%d", out.String(), mi.cntDataEventsGeneratedTotal)
+	}
+}
+
+func TestWriteToSplunkRateLimit(t *testing.T) {
+	var out bytes.Buffer
+	stderr := new(strings.Builder)
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: &out, stderr: stderr, debug: true}
+	if err := mi.SetRateLimit(-1); err == nil {
+		t.Error("SetRateLimit accepted a negative rate")
+	}
+	if err := mi.SetBurstLimit(0); err == nil {
+		t.Error("SetBurstLimit accepted a burst of 0")
+	}
+	if err := mi.SetRateLimit(200); err != nil {
+		t.Fatal(err)
+	}
+	if err := mi.SetBurstLimit(10); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first 10 events are written at once, the other 40 at 200 events per second
+	start := time.Now()
+	var wg sync.WaitGroup
+	for g := 0; g < 2; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, se := range newTestBatchEvents(10) {
+				mi.WriteToSplunk(se)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := mi.WriteToSplunkBatch(newTestBatchEvents(30)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("rate limit was not applied: 50 events written in %s", elapsed)
+	}
+	if cnt := strings.Count(out.String(), "<event"); cnt != 50 {
+		t.Errorf("wrong number of events written. expected=50 got=%d", cnt)
+	}
+	if !strings.Contains(stderr.String(), "rate limiting active: waiting for token") {
+		t.Errorf("waiting for the rate limiter was not logged: %s", stderr.String())
+	}
+	stats := mi.GetRateLimiterStats()
+	if stats.Limit != 200 || stats.Burst != 10 || stats.EventsWritten != 50 || stats.EventsDelayed == 0 || stats.CurrentRate <= 0 || stats.CurrentRate > 300 {
+		t.Errorf("wrong rate limiter stats: %+v", stats)
+	}
+
+	// events waiting when the streaming function gets cancelled are dropped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mi.rateLimitCtx = ctx
+	if err := mi.WriteToSplunkBatch(newTestBatchEvents(30)); !errors.Is(err, context.Canceled) {
+		t.Errorf("write was not aborted by the cancellation of the context. err=%v", err)
+	}
+	if stats := mi.GetRateLimiterStats(); stats.EventsDropped != 30 {
+		t.Errorf("dropped events not counted: %+v", stats)
+	}
+}
+
+func TestWriteToSplunkConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: &out}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, se := range newTestBatchEvents(10) {
+				mi.WriteToSplunk(se)
+			}
+			mi.WriteToSplunkBatch(newTestBatchEvents(10))
+		}()
+	}
+	wg.Wait()
+	if mi.cntDataEventsGeneratedTotal != 160 {
+		t.Errorf("concurrent writes were not counted correctly. expected=160 got=%d", mi.cntDataEventsGeneratedTotal)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 160 {
+		t.Fatalf("concurrent writes produced the wrong number of lines. expected=160 got=%d", len(lines))
+	}
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "<event") || !strings.HasSuffix(l, "</event>") {
+			t.Fatalf("concurrent writes produced an interleaved event: %s", l)
+		}
+	}
+}
+
+func TestEventBuffer(t *testing.T) {
+	var out bytes.Buffer
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: &out}
+
+	if _, err := mi.NewEventBuffer(-1, 0); err == nil {
+		t.Error("NewEventBuffer accepted a negative maxSize")
+	}
+
+	eb, err := mi.NewEventBuffer(3, 0)
+	if err != nil {
+		t.Fatalf("NewEventBuffer returned an error: %s", err.Error())
+	}
+	events := newTestBatchEvents(4)
+	for _, se := range events[0:2] {
+		if err := eb.Add(se); err != nil {
+			t.Fatalf("EventBuffer.Add returned an error: %s", err.Error())
+		}
+	}
+	if out.Len() != 0 || eb.Len() != 2 {
+		t.Errorf("EventBuffer flushed before reaching maxSize. written=%d buffered=%d", out.Len(), eb.Len())
+	}
+	eb.Add(events[2])
+	if eb.Len() != 0 || mi.cntDataEventsGeneratedTotal != 3 {
+		t.Errorf("EventBuffer did not flush automatically upon reaching maxSize. buffered=%d cnt=%d", eb.Len(), mi.cntDataEventsGeneratedTotal)
+	}
+	eb.Add(events[3])
+	if err := eb.Flush(mi); err != nil {
+		t.Fatalf("EventBuffer.Flush returned an error: %s", err.Error())
+	}
+	if eb.Len() != 0 || mi.cntDataEventsGeneratedTotal != 4 {
+		t.Errorf("EventBuffer.Flush did not write the buffered events. buffered=%d cnt=%d", eb.Len(), mi.cntDataEventsGeneratedTotal)
+	}
+
+	// flushing based on age
+	ebAge, _ := mi.NewEventBuffer(0, 10*time.Millisecond)
+	ebAge.Add(newTestBatchEvents(1)[0])
+	time.Sleep(15 * time.Millisecond)
+	ebAge.Add(newTestBatchEvents(1)[0])
+	if ebAge.Len() != 0 || mi.cntDataEventsGeneratedTotal != 6 {
+		t.Errorf("EventBuffer did not flush automatically upon reaching maxAge. buffered=%d cnt=%d", ebAge.Len(), mi.cntDataEventsGeneratedTotal)
+	}
+}
+
+func BenchmarkWriteToSplunk(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	events := newTestBatchEvents(100)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, se := range events {
+			mi.WriteToSplunk(se)
+		}
+	}
+}
+
+func BenchmarkWriteToSplunkBatch(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	events := newTestBatchEvents(100)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		mi.WriteToSplunkBatch(events)
+	}
+}
+
+func BenchmarkWriteToSplunkParallel(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	events := newTestBatchEvents(100)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for _, se := range events {
+				mi.WriteToSplunk(se)
+			}
+		}
+	})
+}
+
+func BenchmarkWriteToSplunkBatchParallel(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	events := newTestBatchEvents(100)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mi.WriteToSplunkBatch(events)
+		}
+	})
+}
+
+func TestEventPool(t *testing.T) {
+	pool := NewEventPool(SplunkEvent{SourceType: "poolst", Index: "poolidx", Host: "poolhost", Source: "poolsrc", Data: "ignored"})
+
+	se := pool.Get()
+	if se.Data != "" || se.SourceType != "poolst" || se.Index != "poolidx" || se.Host != "poolhost" || se.Source != "poolsrc" {
+		t.Errorf("EventPool.Get returned an event without the pool defaults: %+v", se)
+	}
+	if se.Time.IsZero() {
+		t.Error("EventPool.Get returned an event without time")
+	}
+	se.Data = "some data"
+	se.Index = "otheridx"
+	se.Unbroken = true
+	pool.Put(se)
+	if se.Data != "" || !se.Time.IsZero() || se.Index != "poolidx" || se.Unbroken {
+		t.Errorf("EventPool.Put did not reset the event to the pool defaults: %+v", se)
+	}
+
+	mi := &ModularInput{StanzaName: "teststanzaname", defaultSourcetype: "defaultst"}
+	st := Stanza{Name: "testscheme://testinputname", Params: []Param{{Name: "index", Value: "stanzaidx"}}}
+	ev := mi.NewDefaultEvent(&st, pool)
+	if ev.Index != "stanzaidx" || ev.Host != "poolhost" || ev.SourceType != "poolst" || ev.Stanza != st.Name {
+		t.Errorf("NewDefaultEvent with a pool did not combine stanza and pool attributes: %+v", ev)
+	}
+	pool.Put(ev)
+}
+
+func BenchmarkEventAllocation(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	st := Stanza{Name: "testscheme://testinputname", Params: []Param{{Name: "index", Value: "main"}}}
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		// 10k events, as generated in one second by a high-throughput input
+		for i := 0; i < 10000; i++ {
+			ev := mi.NewDefaultEvent(&st)
+			ev.Data = "benchmark event"
+			mi.WriteToSplunk(ev)
+		}
+	}
+}
+
+func BenchmarkEventAllocationPooled(b *testing.B) {
+	mi := &ModularInput{StanzaName: "teststanzaname", stdout: io.Discard}
+	st := Stanza{Name: "testscheme://testinputname", Params: []Param{{Name: "index", Value: "main"}}}
+	pool := NewEventPool(SplunkEvent{SourceType: "teststanzaname"})
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for i := 0; i < 10000; i++ {
+			ev := mi.NewDefaultEvent(&st, pool)
+			ev.Data = "benchmark event"
+			mi.WriteToSplunk(ev)
+			pool.Put(ev)
+		}
+	}
+}
+
+func TestEventFields(t *testing.T) {
+	se := &SplunkEvent{Time: time.Now()}
+	se.AddField("user", "admin").AddField("msg", `he said "hi"`).AddField("path", `C:\temp`).AddField("multi", "line1\nline2").AddField("bad key=", "v")
+	expected := `user="admin" msg="he said \"hi\"" path="C:\\temp" multi="line1` + "\n" + `line2" bad_key_="v"`
+	if se.Data != expected {
+		t.Errorf("AddField generated wrong data.\nexpected: %s\ngot:      %s", expected, se.Data)
+	}
+
+	se.SetFields(map[string]string{"b": "2", "a": "1", "c": `"3"`})
+	if se.Data != `a="1" b="2" c="\"3\""` {
+		t.Errorf("SetFields generated wrong data: %s", se.Data)
+	}
+
+	// the generated XML must be valid, and contain the original data once parsed
+	xmlStr, err := se.xml()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := struct {
+		Data string `xml:"data"`
+	}{}
+	if err := xml.Unmarshal([]byte(xmlStr), &parsed); err != nil {
+		t.Fatalf("event with fields generated invalid XML: %s. XML: %s", err.Error(), xmlStr)
+	}
+	if parsed.Data != se.Data {
+		t.Errorf("XML of event with fields does not contain the original data. expected=%s got=%s", se.Data, parsed.Data)
+	}
+}
+
+func TestEventBuilder(t *testing.T) {
+	tn := time.Now()
+	built := NewEventBuilder().
+		WithTime(tn).
+		WithSource("src").
+		WithSourcetype("st").
+		WithIndex("idx").
+		WithHost("hst").
+		WithField("user", "admin").
+		WithField("action", `log "in"`).
+		Build()
+
+	manual := &SplunkEvent{Time: tn, Source: "src", SourceType: "st", Index: "idx", Host: "hst"}
+	manual.AddField("user", "admin").AddField("action", `log "in"`)
+	if *built != *manual {
+		t.Errorf("EventBuilder generated a different event than the manual one.\nbuilt:  %+v\nmanual: %+v", built, manual)
+	}
+
+	mi := &ModularInput{StanzaName: "teststanzaname", defaultSourcetype: "defaultst"}
+	st := Stanza{Name: "testscheme://testinputname", Params: []Param{{Name: "index", Value: "stanzaidx"}}}
+	ev := mi.NewEventBuilder(&st).WithField("k", "v").Build()
+	if ev.Index != "stanzaidx" || ev.SourceType != "defaultst" || ev.Stanza != st.Name || ev.Data != `k="v"` {
+		t.Errorf("ModularInput.NewEventBuilder did not start from the default event: %+v", ev)
+	}
+}
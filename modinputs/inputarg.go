@@ -4,6 +4,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
 // ArgValidation defines an enumeration of the available splunk-provided splunk argument evaluations
@@ -59,6 +61,26 @@ type InputArg struct {
 	Validation       string `xml:"validation,omitempty"`
 	RequiredOnCreate bool   `xml:"required_on_create"`
 	RequiredOnEdit   bool   `xml:"required_on_edit"`
+	// Sensitive parameters, such as passwords or API keys, have their values masked within the logs
+	Sensitive bool `xml:"-"`
+	// EnvVarOverride is the name of the environment variable which, if set, overrides the value configured within inputs.conf.
+	// See ModularInput.EnableEnvOverrideForAll
+	EnvVarOverride string `xml:"-"`
+}
+
+// maskedValue replaces the values of sensitive parameters within the logs
+const maskedValue = "***masked***"
+
+// sensitiveComment is added to the generated configuration files to mark sensitive parameters
+const sensitiveComment = "# SENSITIVE - value will be masked in logs\n"
+
+// maskedDefaultValue returns the default value of the argument to be written within generated files,
+// or "<sensitive>" if the argument is sensitive: the values of sensitive arguments are never exposed, not even their defaults.
+func (mia *InputArg) maskedDefaultValue() string {
+	if mia.Sensitive && mia.DefaultValue != "" {
+		return "<sensitive>"
+	}
+	return mia.DefaultValue
 }
 
 func (mia *InputArg) SetValidation(validationRule ArgValidation) {
@@ -83,11 +105,17 @@ func (mia *InputArg) getInputsSpec() string {
 	fmt.Fprintf(buf, `%s = <%s>
 *  %s: %s
 *  Default value: "%s"
-`, mia.Name, mia.DataType, mia.Title, strings.ReplaceAll(mia.Description, "\n", " "), strings.ReplaceAll(mia.DefaultValue, "\n", " "))
+`, mia.Name, mia.DataType, mia.Title, strings.ReplaceAll(mia.Description, "\n", " "), strings.ReplaceAll(mia.maskedDefaultValue(), "\n", " "))
 
 	if len(mia.Validation) > 0 {
 		fmt.Fprintf(buf, "* Custom validation: %s\n", mia.Validation)
 	}
+	if mia.EnvVarOverride != "" {
+		fmt.Fprintf(buf, "*  Overridden by environment variable: %s\n", mia.EnvVarOverride)
+	}
+	if mia.Sensitive {
+		buf.WriteString(sensitiveComment)
+	}
 	return buf.String()
 }
 
@@ -100,17 +128,29 @@ func (mia *InputArg) getInputsConf() string {
 	fmt.Fprintf(buf, `#  %s: %s
 #  Data type: %s
 #  Default value: "%s"
-`, mia.Title, strings.ReplaceAll(mia.Description, "\n", " "), mia.DataType, mia.DefaultValue)
+`, mia.Title, strings.ReplaceAll(mia.Description, "\n", " "), mia.DataType, mia.maskedDefaultValue())
 
 	if len(mia.Validation) > 0 {
 		fmt.Fprintf(buf, "# Custom validation: %s\n", mia.Validation)
 	}
+	if mia.EnvVarOverride != "" {
+		fmt.Fprintf(buf, "# Overridden by environment variable %s, if set\n", mia.EnvVarOverride)
+	}
+	if mia.Sensitive {
+		buf.WriteString(sensitiveComment)
+	}
 
-	fmt.Fprintf(buf, "%s = %s\n", mia.Name, strings.ReplaceAll(mia.DefaultValue, "\n", "\\\n"))
+	fmt.Fprintf(buf, "%s = %s\n", mia.Name, strings.ReplaceAll(mia.maskedDefaultValue(), "\n", "\\\n"))
 
 	return buf.String()
 }
 
+// GenerateTransformsConf returns a stanza of splunk's transforms.conf extracting the argument as a field from key="value" pairs
+// found within the events, such as the ones written with SplunkEvent.AddField. It can be referenced with a REPORT- setting of props.conf.
+func (mia *InputArg) GenerateTransformsConf() string {
+	return utils.GenerateTransformsConf(mia.Name, mia.Title, mia.Description)
+}
+
 // GenerateDocumentation returns a markdown-formatted list-item which describes the parameter
 func (mia *InputArg) GenerateDocumentation() string {
 	buf := new(strings.Builder)
@@ -125,7 +165,9 @@ func (mia *InputArg) GenerateDocumentation() string {
 
 	fmt.Fprint(buf, mia.Description)
 
-	if mia.DefaultValue != "" {
+	if mia.Sensitive && mia.DefaultValue != "" {
+		fmt.Fprint(buf, "    Default value: (sensitive — not shown)")
+	} else if mia.DefaultValue != "" {
 		fmt.Fprintf(buf, "    Default value: `%s`", mia.DefaultValue)
 	}
 
@@ -54,6 +54,19 @@ type inputConfig struct {
 	Stanzas []Stanza `xml:"configuration>stanza"`
 }
 
+// getInputConfigFromFile reads the XML-based input configuration from file path. If path is '-', stdin is read instead.
+func getInputConfigFromFile(path string, stdin io.Reader) (*inputConfig, error) {
+	if path == "-" {
+		return getInputConfigFromXML(stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("getInputConfigFromFile: %w", err)
+	}
+	defer f.Close()
+	return getInputConfigFromXML(f)
+}
+
 // getInputConfigFromXML reads a XML-formatted configuration from the provided Reader,
 // parses it and loads it within an inputConfig data structure
 func getInputConfigFromXML(input io.Reader) (*inputConfig, error) {
@@ -107,7 +120,11 @@ func getInputConfigInteractive(mi *ModularInput) (*inputConfig, error) {
 		if arg.Description != "" {
 			prompt = fmt.Sprintf("%s\n    %s\n", prompt, arg.Description)
 		}
-		val = utils.AskForInput(prompt, arg.DefaultValue, false)
+		// values of sensitive parameters are not echoed on the terminal
+		val = utils.AskForInput(prompt, arg.DefaultValue, arg.Sensitive)
+		if arg.Sensitive && val == "" {
+			val = arg.DefaultValue
+		}
 		stanza.Params[seq] = Param{Name: arg.Name, Value: val}
 	}
 
@@ -145,8 +162,8 @@ type validationConfig struct {
 	URI           string   `xml:"server_uri"`
 	SessionKey    string   `xml:"session_key"`
 	CheckpointDir string   `xml:"checkpoint_dir"`
-	// there can only be one validation item
-	Item Stanza `xml:"item"`
+	// Splunk provides one validation item. More items are validated by a function registered with RegisterValidationFuncForAll
+	Items []Stanza `xml:"item"`
 }
 
 // getValidationConfigFromXML reads a XML-formatted configuration from the provided "Reader" object,
@@ -157,7 +174,7 @@ func getValidationConfigFromXML(input io.Reader) (*validationConfig, error) {
 		input = os.Stdin
 	}
 	buf := new(bytes.Buffer)
-	if cnt, err := buf.ReadFrom(os.Stdin); err != nil {
+	if cnt, err := buf.ReadFrom(input); err != nil {
 		return nil, fmt.Errorf("getValidationConfigFromXML: %w", err)
 	} else if cnt < 10 {
 		// additionally check for data which is waaaay too small to be parsed.
@@ -170,3 +187,36 @@ func getValidationConfigFromXML(input io.Reader) (*validationConfig, error) {
 	}
 	return vc, nil
 }
+
+// stanzaParamFlags collects the values of repeated '--param name=value' command-line flags
+// It implements the flag.Value interface.
+type stanzaParamFlags []Param
+
+func (spf *stanzaParamFlags) String() string {
+	if spf == nil {
+		return ""
+	}
+	kv := make([]string, 0, len(*spf))
+	for _, p := range *spf {
+		kv = append(kv, p.Name+"="+p.Value)
+	}
+	return strings.Join(kv, " ")
+}
+
+func (spf *stanzaParamFlags) Set(nameValue string) error {
+	name, value, found := strings.Cut(nameValue, "=")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		return fmt.Errorf("parameter must have format name=value. provided: '%s'", nameValue)
+	}
+	*spf = append(*spf, Param{Name: name, Value: value})
+	return nil
+}
+
+// getTestStanza builds a synthetic stanza named '<stanzaName>://test' using the provided parameters
+func getTestStanza(mi *ModularInput, params stanzaParamFlags) Stanza {
+	return Stanza{
+		Name:   fmt.Sprintf("%s://test", mi.StanzaName),
+		Params: []Param(params),
+	}
+}
@@ -2,6 +2,8 @@ package modinputs
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -69,3 +71,87 @@ func TestLoadConfigFromStdin(t *testing.T) {
 	}
 
 }
+
+func TestLoadValidationConfigFromReader(t *testing.T) {
+	hostname := "myHost"
+	inputXml := fmt.Sprintf(`<items>
+  <server_host>%s</server_host>
+  <server_uri>https://127.0.0.1:8089</server_uri>
+  <session_key>123102983109283019283</session_key>
+  <checkpoint_dir>/opt/splunk/var/lib/splunk/modinputs</checkpoint_dir>
+  <item name="aaa">
+    <param name="param1">value1</param>
+  </item>
+</items>`, hostname)
+
+	vc, err := getValidationConfigFromXML(strings.NewReader(inputXml))
+	if err != nil {
+		t.Errorf("Testing getValidationConfigFromXML: %s", err.Error())
+		t.FailNow()
+	}
+	if vc.Hostname != hostname {
+		t.Errorf("Wrong hostname loaded: expected='%s', got='%s'", hostname, vc.Hostname)
+	}
+	if len(vc.Items) != 1 {
+		t.Fatalf("Wrong number of items loaded: expected=%d, got=%d", 1, len(vc.Items))
+	}
+	if vc.Items[0].Name != "aaa" {
+		t.Errorf("Wrong item name loaded: expected='%s', got='%s'", "aaa", vc.Items[0].Name)
+	}
+	if vc.Items[0].Param("param1") != "value1" {
+		t.Errorf("Wrong param1 loaded: expected='%s', got='%s'", "value1", vc.Items[0].Param("param1"))
+	}
+}
+
+func TestTestStanzaParams(t *testing.T) {
+	var params stanzaParamFlags
+	if err := params.Set("param1=value1"); err != nil {
+		t.Errorf("stanzaParamFlags.Set returned an error for a valid parameter: %s", err.Error())
+	}
+	if err := params.Set("param2=a=b"); err != nil {
+		t.Errorf("stanzaParamFlags.Set returned an error for a valid parameter: %s", err.Error())
+	}
+	if err := params.Set("novalue"); err == nil {
+		t.Errorf("stanzaParamFlags.Set did not return an error for a parameter without '='")
+	}
+
+	s := getTestStanza(&ModularInput{StanzaName: "myscheme"}, params)
+	if s.Name != "myscheme://test" {
+		t.Errorf("Wrong test stanza name: expected='%s', got='%s'", "myscheme://test", s.Name)
+	}
+	if s.Param("param1") != "value1" || s.Param("param2") != "a=b" {
+		t.Errorf("Wrong test stanza params: %+v", s.Params)
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	inputXml := `<input>
+  <server_host>myHost</server_host>
+  <server_uri>https://127.0.0.1:8089</server_uri>
+  <session_key>123102983109283019283</session_key>
+  <checkpoint_dir>/opt/splunk/var/lib/splunk/modinputs</checkpoint_dir>
+  <configuration>
+    <stanza name="myScheme://aaa">
+        <param name="param1">value1</param>
+    </stanza>
+  </configuration>
+</input>`
+	path := filepath.Join(t.TempDir(), "conf.xml")
+	if err := os.WriteFile(path, []byte(inputXml), 0600); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	for _, p := range []string{path, "-"} {
+		c, err := getInputConfigFromFile(p, strings.NewReader(inputXml))
+		if err != nil {
+			t.Errorf("getInputConfigFromFile('%s') returned an error: %s", p, err.Error())
+			continue
+		}
+		if c.Hostname != "myHost" || len(c.Stanzas) != 1 {
+			t.Errorf("getInputConfigFromFile('%s') loaded wrong configuration: %+v", p, c)
+		}
+	}
+	if _, err := getInputConfigFromFile(filepath.Join(t.TempDir(), "missing.xml"), nil); err == nil {
+		t.Errorf("getInputConfigFromFile did not return an error for a missing file")
+	}
+}
@@ -1,12 +1,19 @@
 package modinputs
 
 import (
+	"context"
 	"encoding/xml"
 	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +21,7 @@ import (
 	"github.com/prigio/splunk-go-sdk/alertactions"
 	"github.com/prigio/splunk-go-sdk/splunkd"
 	"github.com/prigio/splunk-go-sdk/utils"
+	"golang.org/x/time/rate"
 )
 
 // isAtTerminal is a boolean which is true if the alert action is being executed on a command-line or not.
@@ -21,11 +29,23 @@ import (
 var isAtTerminal = isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 
 // StreamingFunc is the signature of the function used to generate the data for the modular input
+//
+// Deprecated: use StreamingFuncWithContext, which allows aborting long-running work when Splunk stops the modular input.
 type StreamingFunc func(*ModularInput, Stanza) error
 
 // StreamingFuncSingleInstance is the signature of the function used to generate the data for the modular input when running in single instance mode
+//
+// Deprecated: use StreamingFuncSingleInstanceWithContext, which allows aborting long-running work when Splunk stops the modular input.
 type StreamingFuncSingleInstance func(*ModularInput, []Stanza) error
 
+// StreamingFuncWithContext is the signature of the function used to generate the data for the modular input.
+// ctx is cancelled when the process receives SIGTERM or SIGINT, e.g. when Splunk restarts: the function should then return as soon as possible.
+type StreamingFuncWithContext func(context.Context, *ModularInput, Stanza) error
+
+// StreamingFuncSingleInstanceWithContext is the signature of the function used to generate the data for the modular input when running in single instance mode.
+// ctx is cancelled when the process receives SIGTERM or SIGINT, e.g. when Splunk restarts: the function should then return as soon as possible.
+type StreamingFuncSingleInstanceWithContext func(context.Context, *ModularInput, []Stanza) error
+
 // ValidationFun is the signature of the function used to validate the parameters received from Splunk
 // (only used if the mod input is configured to use external validation)
 type ValidationFunc func(*ModularInput, Stanza) error
@@ -55,11 +75,32 @@ type ModularInput struct {
 
 	// (optional) function used to validate data. Expected only if the modular input is configured to use "external validation"
 	validate ValidationFunc
+	// if true, the validation function is executed on all the provided stanzas instead of the first one only. See RegisterValidationFuncForAll
+	validateAll bool
 	// function used to stream generated data when the modular input is executed once per each configuration stanza
-	stream StreamingFunc
+	stream StreamingFuncWithContext
 
 	// function used to stream generated data when the modular input is executed in single-instance mode: once for all configuration stanzas
-	streamSingleInstance StreamingFuncSingleInstance
+	streamSingleInstance StreamingFuncSingleInstanceWithContext
+
+	// maximum number of stanzas processed concurrently by a function registered with RegisterStreamingFuncSingleInstanceWithPool or RegisterValidationFuncForAll
+	maxStanzaConcurrency int
+	// stanzaErrorHandler, if set, is notified of the failures of the stanzas processed by the streaming pool instead of aggregating them
+	stanzaErrorHandler func(Stanza, error)
+
+	// how long to wait for the streaming function to return after the context has been cancelled. If 0, wait until it returns
+	shutdownTimeout time.Duration
+
+	// if > 0, the streaming function is executed again after this interval, within the same process, until SIGTERM is received
+	autoRerunInterval time.Duration
+
+	// custom header and footer surrounding the streamed events, used instead of <stream></stream> when streamWrapperSet is true
+	streamHeader     string
+	streamFooter     string
+	streamWrapperSet bool
+
+	// appManifestConfig defines the settings of the app.manifest not derived from the modular input. See SetAppManifestConfig
+	appManifestConfig alertactions.AppManifestConfig
 
 	// This debug setting is meant for facilitating development and is not configurable by a user through splunk's inputs.conf
 	debug bool
@@ -79,13 +120,38 @@ type ModularInput struct {
 	uri           string
 	sessionKey    string
 	checkpointDir string
-	stanzas       []Stanza
+	// serviceUsername and servicePassword are used to authenticate again when the session of the splunkd client expires. See SetSplunkServiceCredentials
+	serviceUsername string
+	servicePassword string
+	// checkpointManager is created by GetCheckpointManager on the checkpointDir
+	checkpointManager *CheckpointManager
+	stanzas           []Stanza
 	// Unique id of this run, generated when starting the "Run" function
 	runID string
+	// logHandler, if set with SetLogger, receives all the logs instead of the built-in handlers
+	logHandler slog.Handler
+	// terminalLogHandler is the built-in handler used when running at a terminal, created once by getLogHandler
+	terminalLogHandler     slog.Handler
+	terminalLogHandlerOnce sync.Once
+	// if envOverrideEnabled, the values of all parameters can be overridden by environment variables named after envOverridePrefix. See EnableEnvOverrideForAll
+	envOverrideEnabled bool
+	envOverridePrefix  string
+	// rateLimiter, if not nil, limits the number of events written per second. Shared by all the goroutines writing events. See SetRateLimit
+	rateLimiter *rate.Limiter
+	// rateLimitBurst is the burst size of rateLimiter. See SetBurstLimit
+	rateLimitBurst int
+	// rateLimitCtx aborts the writes waiting for rateLimiter. It is the context of the streaming function
+	rateLimitCtx context.Context
+	// statistics of the rate limiter, updated atomically. See GetRateLimiterStats
+	rateLimitStats rateLimitCounters
 	// private variables
 	internalLogEvent               *SplunkEvent //this is used to setup a standardized event using for logging to index=_internal. If this is not nil, internal loggin is performed through SplunkEvents written on Stdout instead of plain output on Stderr
 	cntDataEventsGeneratedbyStanza int64        // counter of data events emitted by the stanza being currently processed (internal loggin is excluded)
 	cntDataEventsGeneratedTotal    int64        // counter of data events emitted in total (internal loggin is excluded)
+	// counters of data events emitted by each stanza, keyed by stanza name. Only tracked when processing stanzas concurrently, see RegisterStreamingFuncSingleInstanceWithPool
+	cntDataEventsByStanzaName map[string]int64
+	// writeMu serializes the writes of events on stdout, so that XML payloads of concurrent writers do not interleave
+	writeMu sync.Mutex
 }
 
 func New(stanzaName, label, description string) (*ModularInput, error) {
@@ -117,7 +183,20 @@ func (mi *ModularInput) SetSingleInstanceExecution() error {
 }
 
 // RegisterStreamingFunc registera a streaming function to be executed on one configuration stanza which is provided by Splunk at run time
+//
+// Deprecated: use RegisterStreamingFuncWithContext.
 func (mi *ModularInput) RegisterStreamingFunc(f StreamingFunc) error {
+	if f == nil {
+		return mi.RegisterStreamingFuncWithContext(nil)
+	}
+	return mi.RegisterStreamingFuncWithContext(func(_ context.Context, mi *ModularInput, s Stanza) error {
+		return f(mi, s)
+	})
+}
+
+// RegisterStreamingFuncWithContext registers a streaming function to be executed on one configuration stanza which is provided by Splunk at run time.
+// The context provided to the function is cancelled when the process receives SIGTERM or SIGINT.
+func (mi *ModularInput) RegisterStreamingFuncWithContext(f StreamingFuncWithContext) error {
 	if mi.useSingleInstance {
 		return fmt.Errorf(("registerStreamingFunc: cannot register a streaming function when SingleInstanceMode is activated"))
 	}
@@ -126,7 +205,20 @@ func (mi *ModularInput) RegisterStreamingFunc(f StreamingFunc) error {
 }
 
 // RegisterStreamingFunc registera a streaming function to be executed on one configuration stanza which is provided by Splunk at run time
+//
+// Deprecated: use RegisterStreamingFuncSingleInstanceWithContext.
 func (mi *ModularInput) RegisterStreamingFuncSingleInstance(f StreamingFuncSingleInstance) error {
+	if f == nil {
+		return mi.RegisterStreamingFuncSingleInstanceWithContext(nil)
+	}
+	return mi.RegisterStreamingFuncSingleInstanceWithContext(func(_ context.Context, mi *ModularInput, s []Stanza) error {
+		return f(mi, s)
+	})
+}
+
+// RegisterStreamingFuncSingleInstanceWithContext registers a streaming function to be executed on all the configuration stanzas provided by Splunk at run time.
+// The context provided to the function is cancelled when the process receives SIGTERM or SIGINT.
+func (mi *ModularInput) RegisterStreamingFuncSingleInstanceWithContext(f StreamingFuncSingleInstanceWithContext) error {
 	if !mi.useSingleInstance {
 		return fmt.Errorf("registerStreamingFuncSingleInstance: cannot register a single-instance streaming function when SingleInstanceMode is not activated")
 	}
@@ -134,16 +226,64 @@ func (mi *ModularInput) RegisterStreamingFuncSingleInstance(f StreamingFuncSingl
 	return nil
 }
 
+// SetShutdownTimeout configures how long Run waits for the streaming function to return once SIGTERM or SIGINT has been received.
+// When the timeout expires, Run returns an error without waiting any longer. If d is 0 (the default), Run waits until the streaming function returns.
+func (mi *ModularInput) SetShutdownTimeout(d time.Duration) error {
+	if d < 0 {
+		return utils.NewErrInvalidParam("setShutdownTimeout", nil, "'d' cannot be negative")
+	}
+	mi.shutdownTimeout = d
+	return nil
+}
+
+// SetAutoRerun configures the modular input to execute the streaming function again, within the same process, once 'interval' has elapsed after a successful execution.
+// This is useful for inputs maintaining expensive in-memory state (connection pools, parsed certificates...) which would be lost when restarting the process.
+// A new run id is generated for each iteration. Receiving SIGTERM or SIGINT stops the loop; an error returned by the streaming function stops it as well.
+// Setting interval to 0 disables the automatic re-execution.
+func (mi *ModularInput) SetAutoRerun(interval time.Duration) error {
+	if interval < 0 {
+		return utils.NewErrInvalidParam("SetAutoRerun", nil, "'interval' cannot be negative")
+	}
+	mi.autoRerunInterval = interval
+	return nil
+}
+
+// SetStreamWrapper overrides the default "<stream>" and "</stream>" strings written on stdout before and after the streamed events.
+// This is meant for Splunk-compatible ingestion pipelines expecting a different wrapper. Empty strings suppress the header and/or footer entirely.
+func (mi *ModularInput) SetStreamWrapper(header, footer string) {
+	mi.streamHeader = header
+	mi.streamFooter = footer
+	mi.streamWrapperSet = true
+}
+
+// getStreamWrapper returns the header and footer to be written before and after the streamed events
+func (mi *ModularInput) getStreamWrapper() (header, footer string) {
+	if mi.streamWrapperSet {
+		return mi.streamHeader, mi.streamFooter
+	}
+	return "<stream>", "</stream>"
+}
+
 // EnableDebug sets debug mode for the modular input
 func (mi *ModularInput) EnableDebug() {
 	mi.debug = true
 }
 
+// SetDocumentation configures the markdown-formatted documentation of the modular input, printed out by --get-documentation
+func (mi *ModularInput) SetDocumentation(markdown string) {
+	mi.Documentation = markdown
+}
+
 // IsDebug returns true if debug mode has been activated for the modular input
 func (mi *ModularInput) IsDebug() bool {
 	return mi.debug
 }
 
+// IsSingleInstance returns true if the modular input processes all configuration stanzas within one execution
+func (mi *ModularInput) IsSingleInstance() bool {
+	return mi.useSingleInstance
+}
+
 func (mi *ModularInput) GetRunId() string {
 	if mi.runID == "" {
 		mi.runID = uuid.New().String()[0:8]
@@ -163,6 +303,34 @@ func (mi *ModularInput) GetSplunkService() (*splunkd.Client, error) {
 	return mi.splunkd, nil
 }
 
+// SetSplunkServiceCredentials configures the credentials used by GetSplunkServiceWithHealthCheck to authenticate again
+// when the session key provided by Splunk has expired, e.g. for long-running modular inputs.
+func (mi *ModularInput) SetSplunkServiceCredentials(username, password string) {
+	mi.serviceUsername = username
+	mi.servicePassword = password
+}
+
+// GetSplunkServiceWithHealthCheck returns the client provided by GetSplunkService, after verifying with Ping that its session is still valid.
+// If splunkd rejects the session, the client authenticates again with the credentials configured with SetSplunkServiceCredentials.
+// An error is returned if the session is not valid and cannot be refreshed.
+func (mi *ModularInput) GetSplunkServiceWithHealthCheck() (*splunkd.Client, error) {
+	ss, err := mi.GetSplunkService()
+	if err != nil {
+		return nil, fmt.Errorf("getSplunkServiceWithHealthCheck: %w", err)
+	}
+	if err = ss.Ping(); err == nil {
+		return ss, nil
+	}
+	if !splunkd.IsUnauthorized(err) || mi.serviceUsername == "" {
+		return nil, fmt.Errorf("getSplunkServiceWithHealthCheck: %w", err)
+	}
+	mi.Log("INFO", `Session of the splunkd client is not valid anymore, authenticating again. username="%s"`, mi.serviceUsername)
+	if err = ss.RefreshSession(mi.serviceUsername, mi.servicePassword); err != nil {
+		return nil, fmt.Errorf("getSplunkServiceWithHealthCheck: %w", err)
+	}
+	return ss, nil
+}
+
 // setSplunkService configures the splunkd client
 // Prerequisites to execution: a runtime configuration (sessionkey + splunkd URI) must be already available when performing this method.
 // The client has already been authenticated using the sessionKey which Splunk provides when starting the modular input.
@@ -191,9 +359,90 @@ func (mi *ModularInput) setSplunkService() error {
 	return nil
 }
 
+// GetParamNames returns a list of all the parameters defined for the modular input so far
+func (mi *ModularInput) GetParamNames() []string {
+	var paramsList = make([]string, len(mi.Args))
+	for i, a := range mi.Args {
+		paramsList[i] = a.Name
+	}
+	return paramsList
+}
+
+// isSensitiveParam returns true if name is the name of an argument registered as sensitive
+func (mi *ModularInput) isSensitiveParam(name string) bool {
+	for _, a := range mi.Args {
+		if a.Name == name {
+			return a.Sensitive
+		}
+	}
+	return false
+}
+
+// maskStanza returns a copy of stanza in which the values of sensitive parameters are masked, to be used for logging purposes
+func (mi *ModularInput) maskStanza(stanza Stanza) Stanza {
+	masked := stanza
+	masked.Params = make([]Param, len(stanza.Params))
+	for i, p := range stanza.Params {
+		if mi.isSensitiveParam(p.Name) && p.Value != "" {
+			p.Value = maskedValue
+		}
+		masked.Params[i] = p
+	}
+	masked.ParamLists = make([]ParamList, len(stanza.ParamLists))
+	for i, p := range stanza.ParamLists {
+		if mi.isSensitiveParam(p.Name) {
+			p.Values = []string{maskedValue}
+		}
+		masked.ParamLists[i] = p
+	}
+	return masked
+}
+
+// maskArgs returns a copy of the command-line arguments in which the values of sensitive parameters provided with --param are masked
+func (mi *ModularInput) maskArgs(args []string) []string {
+	masked := make([]string, len(args))
+	copy(masked, args)
+	for i, a := range masked {
+		var nameValue, prefix string
+		if strings.HasPrefix(a, "-param=") || strings.HasPrefix(a, "--param=") {
+			prefix, nameValue, _ = strings.Cut(a, "=")
+			prefix += "="
+		} else if i > 0 && (masked[i-1] == "-param" || masked[i-1] == "--param") {
+			nameValue = a
+		} else {
+			continue
+		}
+		if name, _, found := strings.Cut(nameValue, "="); found && mi.isSensitiveParam(name) {
+			masked[i] = prefix + name + "=" + maskedValue
+		}
+	}
+	return masked
+}
+
+// maskInputConfig returns a copy of ic in which the values of sensitive parameters are masked, to be used for logging purposes
+func (mi *ModularInput) maskInputConfig(ic *inputConfig) inputConfig {
+	masked := *ic
+	masked.Stanzas = make([]Stanza, len(ic.Stanzas))
+	for i, s := range ic.Stanzas {
+		masked.Stanzas[i] = mi.maskStanza(s)
+	}
+	return masked
+}
+
+// maskValidationConfig returns a copy of vc in which the values of sensitive parameters are masked, to be used for logging purposes
+func (mi *ModularInput) maskValidationConfig(vc *validationConfig) validationConfig {
+	masked := *vc
+	masked.Items = make([]Stanza, len(vc.Items))
+	for i, s := range vc.Items {
+		masked.Items[i] = mi.maskStanza(s)
+	}
+	return masked
+}
+
 // RegisterNewParam adds a NEW argument to the modular input.
+// The values of sensitive arguments, such as passwords or API keys, are masked within the logs.
 // The argument is additionally returned for further processing, if needed.
-func (mi *ModularInput) RegisterNewParam(name, title, description, defaultValue, dataType, validation string, requiredOnCreate, requiredOnEdit bool) (*InputArg, error) {
+func (mi *ModularInput) RegisterNewParam(name, title, description, defaultValue, dataType, validation string, requiredOnCreate, requiredOnEdit, sensitive bool) (*InputArg, error) {
 	if name == "" {
 		return nil, fmt.Errorf("invalid modular input argument defined: 'name' cannot be empty")
 	}
@@ -215,12 +464,70 @@ func (mi *ModularInput) RegisterNewParam(name, title, description, defaultValue,
 		Validation:       validation,
 		RequiredOnCreate: requiredOnCreate,
 		RequiredOnEdit:   requiredOnEdit,
+		Sensitive:        sensitive,
+	}
+	if mi.envOverrideEnabled {
+		arg.EnvVarOverride = mi.envVarName(name)
 	}
 
 	mi.Args = append(mi.Args, arg)
 	return &arg, nil
 }
 
+// InputArgConfig defines an argument of the modular input, as registered by RegisterParamsFromMap.
+// Fields have the same meaning as the parameters of RegisterNewParam.
+type InputArgConfig struct {
+	Title            string
+	Description      string
+	DefaultValue     string
+	DataType         string
+	Validation       string
+	RequiredOnCreate bool
+	RequiredOnEdit   bool
+	Sensitive        bool
+}
+
+// RegisterParamsFromMap registers one argument for each entry of params, the map key being the name of the argument.
+// Arguments are registered sorted by name, since map iteration order is random.
+// Contrary to RegisterNewParam, registration does not stop at the first failure: all the valid arguments are registered
+// and a *utils.MultiError listing all failures is returned.
+func (mi *ModularInput) RegisterParamsFromMap(params map[string]InputArgConfig) error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := &utils.MultiError{Context: "registerParamsFromMap"}
+	for _, name := range names {
+		p := params[name]
+		_, err := mi.RegisterNewParam(name, p.Title, p.Description, p.DefaultValue, p.DataType, p.Validation, p.RequiredOnCreate, p.RequiredOnEdit, p.Sensitive)
+		errs.Add(err)
+	}
+	return errs.ErrorOrNil()
+}
+
+// RegisterGlobalParam adds an already defined global parameter to the modular input.
+// Along with RegisterNewGlobalParam, this has the same signature as the corresponding AlertAction method, so that
+// packages providing both an alert action and a modular input can share the definitions of their global parameters.
+func (mi *ModularInput) RegisterGlobalParam(p *alertactions.Param) error {
+	if p == nil {
+		return utils.NewErrInvalidParam("registerGlobalParam", nil, "parameter cannot be nil")
+	}
+	// check if the parameter is already present
+	// return error in case it is already there
+	if _, err := mi.GetGlobalParam(p.Name); err == nil {
+		return utils.NewErrInvalidParam("registerGlobalParam", nil, "parameter with name '%s' already existing", p.Name)
+	}
+	if mi.globalParams == nil {
+		mi.globalParams = make([]*alertactions.Param, 0, 1)
+	}
+	if mi.envOverrideEnabled {
+		p.SetEnvVarOverride(mi.envVarName(p.Name))
+	}
+	mi.globalParams = append(mi.globalParams, p)
+	return nil
+}
+
 // RegisterNewGlobalParam adds a new parameter to the alert action.
 // The argument is additionally returned for further processing, if needed.
 func (mi *ModularInput) RegisterNewGlobalParam(configFile, stanza, name, title, description, defaultValue string, required bool) (*alertactions.Param, error) {
@@ -238,6 +545,9 @@ func (mi *ModularInput) RegisterNewGlobalParam(configFile, stanza, name, title,
 	if mi.globalParams == nil {
 		mi.globalParams = make([]*alertactions.Param, 0, 1)
 	}
+	if mi.envOverrideEnabled {
+		p.SetEnvVarOverride(mi.envVarName(p.Name))
+	}
 	mi.globalParams = append(mi.globalParams, p)
 	return p, nil
 }
@@ -257,74 +567,93 @@ func (aa *ModularInput) GetGlobalParam(name string) (*alertactions.Param, error)
 func (mi *ModularInput) RegisterValidationFunc(f ValidationFunc) {
 	mi.useExternalValidation = true
 	mi.validate = f
+	mi.validateAll = false
 }
 
-// Log writes a log so that it can be read by Splunk without being interpreted as an actual event generated by the script
-// Argument 'message' can use formatting markers as fmt.Sprintf. Aditional arguments 'a' will be provided to fmt.Sprintf
-func (mi *ModularInput) Log(level string, message string, a ...interface{}) (err error) {
-	level = strings.ToUpper(level)
-	if level == "WARNING" {
-		// Typical error, just manage it...
-		level = "WARN"
-	}
-	if level != "DEBUG" && level != "INFO" && level != "WARN" && level != "ERROR" && level != "FATAL" {
-		fmt.Fprintf(os.Stderr, "ERROR - ModularInput.Log invoked with invalid level parameter. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'\n", level)
-		return fmt.Errorf("ModularInput.Log: invalid value of 'level' provided. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'", level)
-	}
-	if level != "DEBUG" || (level == "DEBUG" && mi.debug) {
-		// do not do anything if debug is not enabled
-		t := time.Now().Round(time.Millisecond)
-		if mi.internalLogEvent != nil {
-			mi.internalLogEvent.Time = t
-			// prefix the message with timestamp and log_level
-			message = "[" + t.Format("2006-01-02 15:04:05.000 -0700") + "] " + level + " run_id=" + mi.runID + " - " + message
-			//time.Format uses a string with such parameters to define the output format: Mon Jan 2 15:04:05 -0700 MST 2006
-			mi.internalLogEvent.Data = fmt.Sprintf(message, a...)
-			// using writeOut() to skip counting the events, as we do not want to count the internal logs...
-			mi.internalLogEvent.writeOut()
-		} else {
-			// XML-based logging has not yet been activated: using STDERR instead
-			message = "ModularInput " + mi.StanzaName + ": " + level + " run_id=" + mi.runID + " - " + message + "\n"
-			_, err = fmt.Fprintf(os.Stderr, message, a...)
+// WriteToSplunk outputs a generated event in the format accepted by Splunk
+// Returns an error if anything went wrong
+// If a rate limit has been configured with SetRateLimit, the call blocks until the event can be written.
+// The function can be used concurrently, however every call acquires a lock on the output:
+// use WriteToSplunkBatch or an EventBuffer when emitting many events.
+func (mi *ModularInput) WriteToSplunk(se *SplunkEvent) error {
+	if xmlStr, err := se.xml(); err != nil {
+		return err
+	} else {
+		if err = mi.waitForRateLimit(1); err != nil {
+			return err
 		}
+		mi.writeMu.Lock()
+		defer mi.writeMu.Unlock()
+		// increase the counter of the generated events
+		mi.countDataEvents(1)
+		mi.countDataEventsByStanzaName(se.Stanza, 1)
+		_, err = io.WriteString(mi.getStdout(), xmlStr)
+		return err
 	}
-	return err
 }
 
-// logPlain forces a plain-text write to STDERR. This is useful to force the log to appear within splunk's splunkd.log,
-// same as the ones indicating the start of the run.
-// For info related to the arguments, see Log()
-func (mi *ModularInput) logPlain(level string, message string, a ...interface{}) (err error) {
-	level = strings.ToUpper(level)
-	if level == "WARNING" {
-		// Typical error, just manage it...
-		level = "WARN"
+// WriteToSplunkBatch outputs a set of generated events in the format accepted by Splunk.
+// The XML payloads of all the events are generated first and written out with a single call,
+// acquiring the lock on the output only once.
+// If any of the events cannot be converted to XML, nothing is written and an error is returned.
+func (mi *ModularInput) WriteToSplunkBatch(events []*SplunkEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	for i, se := range events {
+		if se == nil {
+			return utils.NewErrInvalidParam("WriteToSplunkBatch", nil, "event at position %d is nil", i)
+		}
+		xmlStr, err := se.xml()
+		if err != nil {
+			return fmt.Errorf("WriteToSplunkBatch: event at position %d: %w", i, err)
+		}
+		sb.WriteString(xmlStr)
 	}
-	if level != "DEBUG" && level != "INFO" && level != "WARN" && level != "ERROR" && level != "FATAL" {
-		fmt.Fprintf(os.Stderr, "ERROR - ModularInput.Log invoked with invalid level parameter. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'\n", level)
-		return fmt.Errorf("ModularInput.Log: invalid value of 'level' provided. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'", level)
+	if err := mi.waitForRateLimit(len(events)); err != nil {
+		return fmt.Errorf("WriteToSplunkBatch: %w", err)
 	}
-	// do not do anything if debug is not enabled
-	if level != "DEBUG" || (level == "DEBUG" && mi.debug) {
-		message = "ModularInput " + mi.StanzaName + ": " + level + " run_id=" + mi.runID + " - " + message + "\n"
-		_, err = fmt.Fprintf(os.Stderr, message, a...)
+	mi.writeMu.Lock()
+	defer mi.writeMu.Unlock()
+	mi.countDataEvents(int64(len(events)))
+	if mi.cntDataEventsByStanzaName != nil {
+		for _, se := range events {
+			mi.countDataEventsByStanzaName(se.Stanza, 1)
+		}
 	}
+	_, err := io.WriteString(mi.getStdout(), sb.String())
 	return err
 }
 
-// WriteToSplunk outputs a generated event in the format accepted by Splunk
-// Returns the number of bytes written, an error if anything went wrong
-// This function IS NOT concurrency safe!
-func (mi *ModularInput) WriteToSplunk(se *SplunkEvent) error {
-	if xmlStr, err := se.xml(); err != nil {
-		return err
-	} else {
-		// increase the counter of the generated events
-		mi.cntDataEventsGeneratedbyStanza++
-		mi.cntDataEventsGeneratedTotal++
-		_, err = os.Stdout.WriteString(xmlStr)
-		return err
+// countDataEvents increases the counters of the generated data events by n
+func (mi *ModularInput) countDataEvents(n int64) {
+	atomic.AddInt64(&mi.cntDataEventsGeneratedbyStanza, n)
+	atomic.AddInt64(&mi.cntDataEventsGeneratedTotal, n)
+}
+
+// countDataEventsByStanzaName increases the counter of the data events generated by stanza 'name', if per-stanza counters are being tracked.
+// Must be called while holding writeMu.
+func (mi *ModularInput) countDataEventsByStanzaName(name string, n int64) {
+	if mi.cntDataEventsByStanzaName != nil {
+		mi.cntDataEventsByStanzaName[name] += n
+	}
+}
+
+// getStdout returns the writer where the XML stream of events is written: the stdout provided to Run, or os.Stdout.
+func (mi *ModularInput) getStdout() io.Writer {
+	if mi.stdout == nil {
+		return os.Stdout
+	}
+	return mi.stdout
+}
+
+// getStderr returns the writer where plain text messages are written: the stderr provided to Run, or os.Stderr.
+func (mi *ModularInput) getStderr() io.Writer {
+	if mi.stderr == nil {
+		return os.Stderr
 	}
+	return mi.stderr
 }
 
 // SetDefaultSourcetype configures a sourcetype to be used if none has been received from the run-time configurations.
@@ -352,34 +681,40 @@ func (mi *ModularInput) GetDefaultIndex() string {
 }
 
 // NewDefaultEvent provides a template for the SplunkEvent to be used to log actual data to be imported to Splunk
-func (mi *ModularInput) NewDefaultEvent(stanza *Stanza) (ev *SplunkEvent) {
+// If a pool is provided, the event is taken from it and should be returned to it with EventPool.Put once written.
+// The attributes configured within the stanza override the defaults of the pool.
+func (mi *ModularInput) NewDefaultEvent(stanza *Stanza, pool ...*EventPool) (ev *SplunkEvent) {
+	if len(pool) > 0 && pool[0] != nil {
+		ev = pool[0].Get()
+	} else {
+		ev = &SplunkEvent{
+			Time:     time.Now(),
+			Unbroken: false,
+			Done:     false,
+		}
+	}
 	if stanza != nil {
 		//mi.Log("DEBUG", fmt.Sprintf("NewDefaultEvent: stanza is NOT nil. %v", stanza.Params))
 		// NOT specifying Data intentionally
-
-		ev = &SplunkEvent{
-			// Anything can be overridded by the actual script
-			Time:       time.Now(),
-			Stanza:     stanza.Name,
-			SourceType: stanza.Sourcetype(),
-			Index:      stanza.Index(),
-			Host:       stanza.Host(),
-			Source:     stanza.Source(),
-			Unbroken:   false,
-			Done:       false,
+		// Anything can be overridded by the actual script
+		ev.Stanza = stanza.Name
+		if v := stanza.Sourcetype(); v != "" {
+			ev.SourceType = v
 		}
-		if stanza.Sourcetype() == "" {
-			ev.SourceType = mi.defaultSourcetype
+		if v := stanza.Index(); v != "" {
+			ev.Index = v
 		}
-	} else {
-		// If no configurations are present, we basically just return a generic event
-		ev = &SplunkEvent{
-			Time:       time.Now(),
-			SourceType: mi.defaultSourcetype,
-			Unbroken:   false,
-			Done:       false,
+		if v := stanza.Host(); v != "" {
+			ev.Host = v
+		}
+		if v := stanza.Source(); v != "" {
+			ev.Source = v
 		}
 	}
+	if ev.SourceType == "" {
+		// If no configurations are present, we basically just return a generic event
+		ev.SourceType = mi.defaultSourcetype
+	}
 	return ev
 }
 
@@ -451,11 +786,19 @@ func (mi *ModularInput) printHelp() {
 // Run is the main function that starts the actual processing.
 // It reads the command-line parameters and performs the correct actions.
 func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
-	mi.Log("DEBUG", "ModularInput.Run started. Cmd-line parameters: '%s'", strings.Join(args, " "))
+	// set interfaces to outside world
+	mi.stdin = stdin
+	mi.stdout = stdout
+	mi.stderr = stderr
+	mi.Log("DEBUG", "ModularInput.Run started. Cmd-line parameters: '%s'", strings.Join(mi.maskArgs(args), " "))
 
 	// configure standard command line parameters
 	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
 
+	// ctx is cancelled when Splunk stops the modular input
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
 	schemePtr := flags.Bool("scheme", false, "Prints out the XML scheme definition. This is what Splunk does when starting up. See Splunk documentation.")
 	validatePtr := flags.Bool("validate-arguments", false, "Validates the parameters provided on STDIN in XML format. This is what Splunk does when starting the modular input if 'external-validation' is set to true in 'inputs.conf'. See Splunk documentation")
 	interactivePtr := flags.Bool("interactive", false, "Interactively ask for parameter values and start a local execution. Useful for development and debugging only.")
@@ -464,6 +807,13 @@ func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Wr
 	getDocuPtr := flags.Bool("get-documentation", false, "Print out markdown-formatted documentation for the alert")
 	getExamplePtr := flags.Bool("get-example", false, "Print an example of inputs.conf configuration for this modular input and exit")
 	getRunTimeConfPtr := flags.Bool("get-runtime-conf-example", false, fmt.Sprintf("Interactively ask for parameter values and generates an XML-based configuration, as Splunk would send to your modular input. You can use this as 'cat conf.json > %s'.", args[0]))
+	configFilePtr := flags.String("config-file", "", "Start execution reading the XML-based configuration from the specified file instead of STDIN. Use '-' to read from STDIN. Useful for development and debugging only.")
+	testStanzaPtr := flags.Bool("test-stanza", false, fmt.Sprintf("Start a local execution on a stanza named '%s://test', built using the values provided with --param. Useful for development and debugging only.", mi.StanzaName))
+	var testParams stanzaParamFlags
+	flags.Var(&testParams, "param", "Parameter for --test-stanza and --benchmark in format name=value. Can be repeated.")
+	benchmarkPtr := flags.Bool("benchmark", false, "Measure the throughput of the streaming function on synthetic stanzas, discarding the generated events. Statistics are printed to STDERR.")
+	numEventsPtr := flags.Int("num-events", 10000, "Minimum number of events to be generated during --benchmark")
+	numStanzasPtr := flags.Int("num-stanzas", 1, "Number of synthetic stanzas used during --benchmark")
 
 	//debugPtr := flags.Bool("debug", false, "Activates debug mode, useful only during development")
 	//getRunTimeConfPtr := flags.Bool("get-runtime-conf-example", false, fmt.Sprintf("Interactively ask for parameter values and generates a JSON-based configuration, as Splunk would send to your alert. You can use this as 'cat conf.json > %s -execute'.", args[0]))
@@ -475,26 +825,36 @@ func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Wr
 		return err
 	}
 
-	if len(args) == 1 {
+	if len(args) == 1 || *configFilePtr != "" {
 		// no-command line flag. This signal actual execution of the modular input
-
-		// Read XML configs from STDIN
+		// --config-file allows reading the XML configs from a file instead of STDIN, during development.
+		var ic *inputConfig
+		var err error
+		if *configFilePtr != "" {
+			ic, err = getInputConfigFromFile(*configFilePtr, stdin)
+		} else {
+			// Read XML configs from STDIN
+			ic, err = getInputConfigFromXML(stdin)
+		}
 		// Populates infos about the configuration Stanzas
-		if ic, err := getInputConfigFromXML(stdin); err != nil {
-			mi.Log("FATAL", "Errow when loading execution configuration XML from StdIn: %s", err.Error())
+		if err != nil {
+			mi.Log("FATAL", "Errow when loading execution configuration XML: %s", err.Error())
 			return err
 		} else {
-			mi.Log("DEBUG", "Loaded input configurations: %+v", ic)
+			mi.Log("DEBUG", "Loaded input configurations: %+v", mi.maskInputConfig(ic))
 			mi.hostname = ic.Hostname
 			mi.uri = ic.URI
 			mi.sessionKey = ic.SessionKey
 			mi.checkpointDir = ic.CheckpointDir
 			mi.stanzas = ic.Stanzas
 		}
-		return mi.runStreaming()
+		return mi.runStreaming(ctx)
 	} else if *schemePtr {
 		// print a XML definition of the parameters accepted by this modular input
 		mi.Log("DEBUG", "starting --scheme action")
+		for _, warning := range mi.ValidateScheme() {
+			mi.Log("WARN", "Scheme validation: %s", warning)
+		}
 		if schemeXml, err := mi.getXMLScheme(); err != nil {
 			mi.Log("FATAL", "Error during scheme generation. %s", err.Error())
 			return err
@@ -510,14 +870,21 @@ func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Wr
 		} else {
 			// Assign the loaded configuration to the private vars
 			// of the modularinput itself
-			mi.Log("DEBUG", "Loaded validation configurations: %+v", vc)
+			mi.Log("DEBUG", "Loaded validation configurations: %+v", mi.maskValidationConfig(vc))
 			mi.hostname = vc.Hostname
 			mi.uri = vc.URI
 			mi.sessionKey = vc.SessionKey
 			mi.checkpointDir = vc.CheckpointDir
-			mi.stanzas = []Stanza{vc.Item}
+			mi.stanzas = vc.Items
 		}
 		return mi.runValidation()
+	} else if *benchmarkPtr {
+		return mi.runBenchmark(ctx, *numEventsPtr, *numStanzasPtr, testParams)
+	} else if *testStanzaPtr {
+		stanza := getTestStanza(mi, testParams)
+		mi.Log("DEBUG", "Provided test stanza: %+v", mi.maskStanza(stanza))
+		mi.stanzas = []Stanza{stanza}
+		return mi.runStreaming(ctx)
 	} else if *interactivePtr || *getRunTimeConfPtr {
 		var ic *inputConfig
 		var conf []byte
@@ -527,7 +894,7 @@ func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Wr
 			mi.Log("FATAL", "Errow when preparing execution configuration interactively: %s", err.Error())
 			return err
 		} else {
-			mi.Log("DEBUG", "Provided input configurations: %+v", ic)
+			mi.Log("DEBUG", "Provided input configurations: %+v", mi.maskInputConfig(ic))
 			mi.hostname = ic.Hostname
 			mi.uri = ic.URI
 			mi.sessionKey = ic.SessionKey
@@ -535,7 +902,7 @@ func (mi *ModularInput) Run(args []string, stdin io.Reader, stdout, stderr io.Wr
 			mi.stanzas = ic.Stanzas
 		}
 		if *interactivePtr {
-			return mi.runStreaming()
+			return mi.runStreaming(ctx)
 		} else {
 			if conf, err = xml.MarshalIndent(ic, "", "  "); err != nil {
 				mi.Log("FATAL", "Error when marshaling configuration to XML: %s", err.Error())
@@ -575,9 +942,7 @@ You can use the following configuration by:
 
 // runStreaming executes the data generation function configured within ModularInput mi
 // on the input configurations provided as XML on stdin
-func (mi *ModularInput) runStreaming() (err error) {
-	// these two vars are used to track the duration of the overall streaming function
-	var duration time.Duration
+func (mi *ModularInput) runStreaming(ctx context.Context) (err error) {
 	mi.Log("DEBUG", "Starting 'runStreaming' function")
 	if !mi.useSingleInstance && mi.stream == nil {
 		mi.Log("FATAL", "No streaming function specified")
@@ -587,11 +952,50 @@ func (mi *ModularInput) runStreaming() (err error) {
 		mi.Log("FATAL", "No streaming function specified for single-instance mode")
 		return fmt.Errorf("FATAL: no streaming function specified for single-instance mode")
 	}
+	mi.applyEnvOverrides(mi.stanzas)
+	mi.rateLimitCtx = ctx
 
-	streamingStartTime := time.Now()
+	header, footer := mi.getStreamWrapper()
+	if header != "" {
+		fmt.Fprintln(mi.getStdout(), header) // Setup the XML streaming mode
+	}
+	if footer != "" {
+		defer fmt.Fprintln(mi.getStdout(), footer) // close XML streaming mode when returning
+	}
+
+	if mi.autoRerunInterval <= 0 {
+		return mi.runStreamingOnce(ctx)
+	}
+	return mi.runStreamingLoop(ctx)
+}
 
-	fmt.Println("<stream>")        // Setup the XML streaming mode
-	defer fmt.Println("</stream>") // close XML streaming mode when returning
+// runStreamingLoop executes the streaming function repeatedly, waiting for mi.autoRerunInterval between executions.
+// The loop stops as soon as an execution fails, or when ctx is cancelled.
+func (mi *ModularInput) runStreamingLoop(ctx context.Context) error {
+	for iteration := 1; ; iteration++ {
+		if err := mi.runStreamingOnce(ctx); err != nil {
+			return err
+		}
+		mi.Log("DEBUG", "Auto-rerun: iteration=%d completed, waiting interval_s=%.03f", iteration, mi.autoRerunInterval.Seconds())
+		select {
+		case <-ctx.Done():
+			mi.Log("INFO", "Auto-rerun: execution cancelled, stopping after iteration=%d", iteration)
+			return nil
+		case <-time.After(mi.autoRerunInterval):
+		}
+		// each iteration is tracked with its own run id
+		mi.runID = uuid.New().String()[0:8]
+		mi.cntDataEventsGeneratedbyStanza = 0
+		mi.cntDataEventsGeneratedTotal = 0
+	}
+}
+
+// runStreamingOnce executes the streaming function once on the input configurations.
+// The surrounding <stream> tags are written by runStreaming.
+func (mi *ModularInput) runStreamingOnce(ctx context.Context) (err error) {
+	// these two vars are used to track the duration of the overall streaming function
+	var duration time.Duration
+	streamingStartTime := time.Now()
 
 	if mi.useSingleInstance {
 		mi.setupEventBasedInternalLoggingSingleInstance()
@@ -599,7 +1003,7 @@ func (mi *ModularInput) runStreaming() (err error) {
 		startTime := time.Now()
 
 		if len(mi.stanzas) > 0 {
-			err = mi.streamSingleInstance(mi, mi.stanzas)
+			err = mi.waitForStreaming(ctx, func() error { return mi.streamSingleInstance(ctx, mi, mi.stanzas) })
 		}
 
 		duration = time.Since(startTime)
@@ -620,7 +1024,7 @@ func (mi *ModularInput) runStreaming() (err error) {
 		mi.setupEventBasedInternalLogging(&stanza)
 		mi.Log("INFO", `Starting streaming for stanza="%s"`, stanza.Name)
 
-		err = mi.stream(mi, stanza)
+		err = mi.waitForStreaming(ctx, func() error { return mi.stream(ctx, mi, stanza) })
 
 		duration = time.Since(streamingStartTime)
 		if err != nil {
@@ -634,10 +1038,33 @@ func (mi *ModularInput) runStreaming() (err error) {
 	return err
 }
 
+// waitForStreaming executes the streaming function fn and waits for it to return.
+// If ctx is cancelled and a shutdown timeout has been configured, fn is given that much time to return before an error is returned.
+func (mi *ModularInput) waitForStreaming(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	if mi.shutdownTimeout <= 0 {
+		return <-done
+	}
+	mi.Log("INFO", "Execution cancelled, waiting up to timeout_s=%.03f for the streaming function to return", mi.shutdownTimeout.Seconds())
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(mi.shutdownTimeout):
+		return fmt.Errorf("streaming function did not return within the shutdown timeout of %s: %w", mi.shutdownTimeout, ctx.Err())
+	}
+}
+
 // runValidation executes the validation function configured within ModularInput mi
 // on the validation configuration provided as XML on stdin
 func (mi *ModularInput) runValidation() error {
 	mi.Log("DEBUG", `Starting argument validation`)
+	mi.applyEnvOverrides(mi.stanzas)
 
 	if !mi.useExternalValidation {
 		mi.Log("WARN", "Invoked with --validate-arguments command-line arguments but configured to NOT use external validation. Skipping it.")
@@ -648,15 +1075,36 @@ func (mi *ModularInput) runValidation() error {
 		return nil
 	}
 
-	if err := mi.validate(mi, mi.stanzas[0]); err != nil {
-		mi.Log("ERROR", `Validation of parameters for stanza="%s" status=failed error="%s"`, mi.stanzas[0].Name, err.Error())
+	if len(mi.stanzas) == 0 {
+		err := fmt.Errorf("no stanza to be validated was provided")
+		mi.Log("ERROR", "Validation of parameters status=failed error=\"%s\"", err.Error())
+		fmt.Fprintf(mi.getStdout(), "%s\n", err.Error())
+		return err
+	}
+	if mi.validateAll {
+		_, err := mi.ValidateAll(mi.stanzas)
+		if err != nil {
+			fmt.Fprintf(mi.getStdout(), "%s\n", err.Error())
+		}
+		return err
+	}
+
+	if err := mi.validateStanza(mi.stanzas[0]); err != nil {
 		// Splunk specification requires to write the validation errors on STDOUT
 		// See: https://docs.splunk.com/Documentation/SplunkCloud/8.1.2011/AdvancedDev/ModInputsScripts#Create_a_modular_input_script
-		fmt.Printf("%s\n", err.Error())
+		fmt.Fprintf(mi.getStdout(), "%s\n", err.Error())
 		return err
 	}
+	return nil
+}
 
-	mi.Log("INFO", `Validation of input parameters for stanza="%s" status=succeeded`, mi.stanzas[0].Name)
+// validateStanza executes the validation function on stanza, logging its outcome
+func (mi *ModularInput) validateStanza(stanza Stanza) error {
+	if err := mi.validate(mi, stanza); err != nil {
+		mi.Log("ERROR", `Validation of parameters for stanza="%s" status=failed error="%s"`, stanza.Name, err.Error())
+		return err
+	}
+	mi.Log("INFO", `Validation of input parameters for stanza="%s" status=succeeded`, stanza.Name)
 	return nil
 }
 
@@ -668,6 +1116,7 @@ func (mi *ModularInput) setupEventBasedInternalLogging(stanza *Stanza) {
 	if stanza != nil {
 		inputSourcetype := "modinput:" + stanza.Scheme()
 		mi.logPlain("INFO", `Starting execution of stanza="%s". Logging related internal data as 'index=_internal sourcetype="%s" source="%s"'`, stanza.Name, inputSourcetype, stanza.Name)
+		mi.writeMu.Lock()
 		mi.internalLogEvent = &SplunkEvent{
 			// NOT specifying Data and Host intentionally
 			Time:       time.Now(),
@@ -678,6 +1127,7 @@ func (mi *ModularInput) setupEventBasedInternalLogging(stanza *Stanza) {
 			Unbroken:   false,
 			Done:       false,
 		}
+		mi.writeMu.Unlock()
 	} else {
 		mi.logPlain("FATAL", "Function setupEventBasedInternalLogging() called without a stanza being specified, this is an error within the library. Interrupting execution.")
 		panic("Library error: function setupEventBasedInternalLogging() called without a stanza being specified.")
@@ -689,6 +1139,7 @@ func (mi *ModularInput) setupEventBasedInternalLogging(stanza *Stanza) {
 func (mi *ModularInput) setupEventBasedInternalLoggingSingleInstance() {
 	inputSourcetype := "modinput:" + mi.StanzaName
 	mi.logPlain("INFO", `Starting single-instance execution. Logging internal data as 'index=_internal sourcetype="%s"'`, inputSourcetype)
+	mi.writeMu.Lock()
 	mi.internalLogEvent = &SplunkEvent{
 		// NOT specifying Data and Host intentionally
 		Time:       time.Now(),
@@ -699,6 +1150,7 @@ func (mi *ModularInput) setupEventBasedInternalLoggingSingleInstance() {
 		Unbroken:   false,
 		Done:       false,
 	}
+	mi.writeMu.Unlock()
 }
 
 func (mi *ModularInput) getLoggingSourcetype() string {
@@ -708,6 +1160,19 @@ func (mi *ModularInput) getLoggingSourcetype() string {
 	return "modinput:" + mi.defaultSourcetype
 }
 
+// ValidateScheme checks the definition of the arguments of the modular input for unusual configurations, which are
+// accepted by Splunk but are often a mistake. It returns a list of warnings, which are also logged when generating the scheme with --scheme.
+// Warnings do not prevent the execution of the modular input.
+func (mi *ModularInput) ValidateScheme() []string {
+	warnings := make([]string, 0)
+	for _, arg := range mi.Args {
+		if !arg.RequiredOnCreate && arg.RequiredOnEdit {
+			warnings = append(warnings, fmt.Sprintf(`argument "%s" is required on edit but not on create: inputs created without it cannot be edited without providing it`, arg.Name))
+		}
+	}
+	return warnings
+}
+
 // getXMLScheme returns a string containing a XML-based description
 // of the configuration parameters accepted by the modular input
 // The XML format is documented at: https://docs.splunk.com/Documentation/Splunk/8.1.2/AdvancedDev/ModInputsScripts#Define_a_scheme_for_introspection
@@ -0,0 +1,145 @@
+package modinputs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+/*
+This file contains the implementation of the --benchmark command-line mode,
+used to measure the throughput of the streaming function without having to deploy the modular input within splunk.
+*/
+
+// countingWriter is a writer which counts the bytes written into the underlying writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// benchmarkResult collects the measurements of a benchmark run
+type benchmarkResult struct {
+	events    int64
+	bytes     int64
+	duration  time.Duration
+	latencies []time.Duration
+}
+
+// percentile returns the p-th percentile (0 < p <= 100) of the latencies measured for the invocations of the streaming function
+func (br *benchmarkResult) percentile(p float64) time.Duration {
+	if len(br.latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(br.latencies))
+	copy(sorted, br.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted))*p/100.0+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// String returns a human-readable summary of the benchmark
+func (br *benchmarkResult) String() string {
+	secs := br.duration.Seconds()
+	if secs == 0 {
+		secs = 1e-9
+	}
+	return fmt.Sprintf("Benchmark results: invocations=%d events=%d bytes=%d duration_s=%.03f events_per_s=%.01f bytes_per_s=%.01f latency_p50=%s latency_p95=%s latency_p99=%s",
+		len(br.latencies), br.events, br.bytes, br.duration.Seconds(),
+		float64(br.events)/secs, float64(br.bytes)/secs,
+		br.percentile(50), br.percentile(95), br.percentile(99))
+}
+
+// getBenchmarkStanzas builds numStanzas synthetic stanzas named '<stanzaName>://benchmark-<n>'.
+// Their parameters are the defaults of the registered arguments, overridden by the provided params.
+func getBenchmarkStanzas(mi *ModularInput, numStanzas int, params stanzaParamFlags) []Stanza {
+	stanzas := make([]Stanza, numStanzas)
+	for i := range stanzas {
+		stanzaParams := make([]Param, 0, len(mi.Args)+len(params))
+		for _, arg := range mi.Args {
+			if arg.DefaultValue != "" {
+				stanzaParams = append(stanzaParams, Param{Name: arg.Name, Value: arg.DefaultValue})
+			}
+		}
+		// Stanza.Param() returns the first matching parameter: overrides must come first
+		stanzaParams = append(append([]Param{}, params...), stanzaParams...)
+		stanzas[i] = Stanza{
+			Name:   fmt.Sprintf("%s://benchmark-%d", mi.StanzaName, i+1),
+			Params: stanzaParams,
+		}
+	}
+	return stanzas
+}
+
+// runBenchmark invokes the streaming function on numStanzas synthetic stanzas until at least numEvents events have been generated.
+// Generated events are discarded, statistics about throughput and latency are printed to stderr.
+// The benchmark stops early when ctx is cancelled, which is also provided to the streaming function.
+func (mi *ModularInput) runBenchmark(ctx context.Context, numEvents, numStanzas int, params stanzaParamFlags) error {
+	if numEvents <= 0 || numStanzas <= 0 {
+		return fmt.Errorf("benchmark: --num-events and --num-stanzas must be greater than 0")
+	}
+	if !mi.useSingleInstance && mi.stream == nil {
+		return fmt.Errorf("benchmark: no streaming function specified")
+	}
+	if mi.useSingleInstance && mi.streamSingleInstance == nil {
+		return fmt.Errorf("benchmark: no streaming function specified for single-instance mode")
+	}
+
+	stderr := mi.getStderr()
+	cw := &countingWriter{w: io.Discard}
+	prevStdout := mi.stdout
+	mi.stdout = cw
+	defer func() { mi.stdout = prevStdout }()
+	mi.stanzas = getBenchmarkStanzas(mi, numStanzas, params)
+	res := &benchmarkResult{latencies: make([]time.Duration, 0)}
+
+	fmt.Fprintf(stderr, "Starting benchmark of '%s' with num_events=%d num_stanzas=%d\n", mi.StanzaName, numEvents, numStanzas)
+	start := time.Now()
+	for atomic.LoadInt64(&mi.cntDataEventsGeneratedTotal) < int64(numEvents) {
+		if ctx.Err() != nil {
+			fmt.Fprintln(stderr, "Benchmark stopped: execution cancelled")
+			break
+		}
+		before := atomic.LoadInt64(&mi.cntDataEventsGeneratedTotal)
+		if mi.useSingleInstance {
+			invocationStart := time.Now()
+			if err := mi.streamSingleInstance(ctx, mi, mi.stanzas); err != nil {
+				return fmt.Errorf("benchmark: %w", err)
+			}
+			res.latencies = append(res.latencies, time.Since(invocationStart))
+		} else {
+			for _, stanza := range mi.stanzas {
+				invocationStart := time.Now()
+				atomic.StoreInt64(&mi.cntDataEventsGeneratedbyStanza, 0)
+				if err := mi.stream(ctx, mi, stanza); err != nil {
+					return fmt.Errorf("benchmark: stanza '%s': %w", stanza.Name, err)
+				}
+				res.latencies = append(res.latencies, time.Since(invocationStart))
+			}
+		}
+		if atomic.LoadInt64(&mi.cntDataEventsGeneratedTotal) == before {
+			fmt.Fprintln(stderr, "Benchmark stopped: the streaming function did not generate any event")
+			break
+		}
+	}
+	res.duration = time.Since(start)
+	res.events = atomic.LoadInt64(&mi.cntDataEventsGeneratedTotal)
+	res.bytes = cw.n
+
+	fmt.Fprintln(stderr, res.String())
+	return nil
+}
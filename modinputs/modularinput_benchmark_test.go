@@ -0,0 +1,62 @@
+package modinputs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBenchmark(t *testing.T) {
+	mi := &ModularInput{StanzaName: "teststanzaname"}
+	mi.RegisterNewParam("msg", "Message", "message to be emitted", "hello", ArgDataTypeStr, "", false, false, false)
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error {
+		for i := 0; i < 10; i++ {
+			ev := mi.NewDefaultEvent(&s)
+			ev.Data = s.Param("msg")
+			if err := mi.WriteToSplunk(ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	stderr := new(strings.Builder)
+	mi.stderr = stderr
+	stdout := new(strings.Builder)
+	mi.stdout = stdout
+
+	if err := mi.runBenchmark(context.Background(), 25, 2, nil); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	// 2 stanzas x 10 events per invocation: 2 rounds are needed to reach 25 events
+	if mi.cntDataEventsGeneratedTotal != 40 {
+		t.Errorf("runBenchmark generated a wrong number of events. expected=40 got=%d", mi.cntDataEventsGeneratedTotal)
+	}
+	if !strings.Contains(stderr.String(), "events=40") || !strings.Contains(stderr.String(), "latency_p99=") {
+		t.Errorf("runBenchmark did not print the expected statistics: %s", stderr.String())
+	}
+	if mi.stdout != stdout || stdout.Len() != 0 {
+		t.Errorf("runBenchmark did not restore the output of the modular input")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mi.cntDataEventsGeneratedTotal = 0
+	if err := mi.runBenchmark(ctx, 25, 2, nil); err != nil {
+		t.Error(err)
+	}
+	if mi.cntDataEventsGeneratedTotal != 0 || !strings.Contains(stderr.String(), "execution cancelled") {
+		t.Errorf("runBenchmark did not stop once the context got cancelled. events=%d", mi.cntDataEventsGeneratedTotal)
+	}
+}
+
+func TestBenchmarkPercentile(t *testing.T) {
+	br := benchmarkResult{}
+	for i := 1; i <= 100; i++ {
+		br.latencies = append(br.latencies, time.Duration(i)*time.Millisecond)
+	}
+	if br.percentile(50) != 50*time.Millisecond || br.percentile(99) != 99*time.Millisecond {
+		t.Errorf("percentile returned wrong values. p50=%s p99=%s", br.percentile(50), br.percentile(99))
+	}
+}
@@ -0,0 +1,52 @@
+package modinputs
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envVarSanitizer matches the characters which cannot be part of the name of an environment variable
+var envVarSanitizer = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// EnableEnvOverrideForAll allows overriding the values of all the arguments and global parameters of the modular input
+// through environment variables called PREFIX_PARAMNAME, e.g. MYINPUT_API_URL for prefix 'myinput' and parameter 'api_url'.
+// This is meant for modular inputs running within containers, which can then be configured without modifying inputs.conf.
+// Characters not allowed within environment variable names are replaced by '_'. If prefix is empty, the variable is called PARAMNAME.
+// The override also applies to parameters registered after calling this function.
+// The generated inputs.conf and inputs.conf.spec mention the name of the environment variables.
+func (mi *ModularInput) EnableEnvOverrideForAll(prefix string) {
+	mi.envOverrideEnabled = true
+	mi.envOverridePrefix = strings.TrimSuffix(prefix, "_")
+	for i := range mi.Args {
+		mi.Args[i].EnvVarOverride = mi.envVarName(mi.Args[i].Name)
+	}
+	for _, p := range mi.globalParams {
+		p.SetEnvVarOverride(mi.envVarName(p.Name))
+	}
+}
+
+// envVarName returns the name of the environment variable overriding the parameter called name
+func (mi *ModularInput) envVarName(name string) string {
+	if mi.envOverridePrefix != "" {
+		name = mi.envOverridePrefix + "_" + name
+	}
+	return strings.ToUpper(envVarSanitizer.ReplaceAllString(name, "_"))
+}
+
+// applyEnvOverrides replaces the values of the parameters of stanzas with the ones of the environment variables configured for the arguments, if set
+func (mi *ModularInput) applyEnvOverrides(stanzas []Stanza) {
+	for _, arg := range mi.Args {
+		if arg.EnvVarOverride == "" {
+			continue
+		}
+		v, found := os.LookupEnv(arg.EnvVarOverride)
+		if !found {
+			continue
+		}
+		for i := range stanzas {
+			mi.Log("DEBUG", `stanza="%s" parameter "%s" overridden by environment variable %s`, stanzas[i].Name, arg.Name, arg.EnvVarOverride)
+			stanzas[i].setParam(arg.Name, v)
+		}
+	}
+}
@@ -36,7 +36,7 @@ func (mi *ModularInput) generateExampleConf() string {
 	for _, arg := range mi.Args {
 		fmt.Fprintf(&sb, "# %s - %s\n", arg.Title, arg.Description)
 		if arg.DefaultValue != "" {
-			fmt.Fprintf(&sb, "# Default value: %s\n", arg.DefaultValue)
+			fmt.Fprintf(&sb, "# Default value: %s\n", arg.maskedDefaultValue())
 		}
 		fmt.Fprintf(&sb, "%s = <%s>\n", arg.Name, arg.DataType)
 	}
@@ -112,7 +112,19 @@ func (mi *ModularInput) generateDocumentation() string {
 
 %s
 
-`, mi.Title, mi.Description)
+Stanza: `+"`%s://<name>`"+`
+
+| Parameter | Title | Required on create | Default value |
+|-----------|-------|--------------------|---------------|
+`, mi.Title, mi.Description, mi.StanzaName)
+	for _, arg := range mi.Args {
+		defaultValue := "`" + arg.DefaultValue + "`"
+		if arg.Sensitive && arg.DefaultValue != "" {
+			defaultValue = "(sensitive — not shown)"
+		}
+		fmt.Fprintf(buf, "| `%s` | %s | %t | %s |\n", arg.Name, arg.Title, arg.RequiredOnCreate, defaultValue)
+	}
+	fmt.Fprintln(buf, "")
 	if mi.Documentation != "" {
 		fmt.Fprintln(buf, mi.Documentation)
 	}
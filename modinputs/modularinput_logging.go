@@ -0,0 +1,252 @@
+package modinputs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LevelFatal is the slog level used for FATAL messages, which are more severe than slog.LevelError
+const LevelFatal = slog.Level(12)
+
+// SetLogger configures handler to receive all the logs of the modular input, instead of the built-in handlers.
+// Every record contains the attributes run_id and stanza. DEBUG records are only provided if debug mode is enabled.
+// Use SetLogger(nil) to restore the built-in handlers: a slog.TextHandler on stderr when running at a terminal,
+// NewSplunkEventHandler otherwise.
+func (mi *ModularInput) SetLogger(handler slog.Handler) {
+	mi.logHandler = handler
+}
+
+// Log writes a log so that it can be read by Splunk without being interpreted as an actual event generated by the script
+// Argument 'message' can use formatting markers as fmt.Sprintf. Aditional arguments 'a' will be provided to fmt.Sprintf
+func (mi *ModularInput) Log(level string, message string, a ...interface{}) (err error) {
+	l, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	return mi.logRecord(mi.getLogHandler(false), l, fmt.Sprintf(message, a...), nil)
+}
+
+// LogAttrs writes a structured log, whose attributes are provided to the configured slog handler. See SetLogger.
+func (mi *ModularInput) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) error {
+	return mi.logRecord(mi.getLogHandler(false), level, msg, attrs)
+}
+
+// logPlain forces a plain-text write to STDERR. This is useful to force the log to appear within splunk's splunkd.log,
+// same as the ones indicating the start of the run.
+// For info related to the arguments, see Log()
+func (mi *ModularInput) logPlain(level string, message string, a ...interface{}) (err error) {
+	l, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+	return mi.logRecord(mi.getLogHandler(true), l, fmt.Sprintf(message, a...), nil)
+}
+
+// getLogHandler returns the handler configured with SetLogger or, if none, the built-in one.
+// If plain is true, the built-in handler always writes on stderr, even after event-based internal logging has been set up.
+func (mi *ModularInput) getLogHandler(plain bool) slog.Handler {
+	if mi.logHandler != nil {
+		return mi.logHandler
+	}
+	if isAtTerminal {
+		mi.terminalLogHandlerOnce.Do(func() {
+			mi.terminalLogHandler = slog.NewTextHandler(stderrWriter{mi}, &slog.HandlerOptions{Level: debugLeveler{mi}, ReplaceAttr: replaceLevelAttr})
+		})
+		return mi.terminalLogHandler
+	}
+	return &splunkEventHandler{mi: mi, plain: plain}
+}
+
+// stderrWriter writes on the stderr of the modular input while holding writeMu, as the other writers of stderr do
+type stderrWriter struct {
+	mi *ModularInput
+}
+
+func (w stderrWriter) Write(p []byte) (int, error) {
+	w.mi.writeMu.Lock()
+	defer w.mi.writeMu.Unlock()
+	return w.mi.getStderr().Write(p)
+}
+
+// logRecord provides handler with a record containing msg, the run_id and stanza attributes and attrs.
+// Nothing is done for DEBUG records if debug mode is not enabled.
+func (mi *ModularInput) logRecord(handler slog.Handler, level slog.Level, msg string, attrs []slog.Attr) error {
+	if level < slog.LevelInfo && !mi.debug {
+		return nil
+	}
+	ctx := context.Background()
+	if !handler.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	r.AddAttrs(slog.String("run_id", mi.runID), slog.String("stanza", mi.getLoggingStanza()))
+	r.AddAttrs(attrs...)
+	return handler.Handle(ctx, r)
+}
+
+// getLoggingStanza returns the name of the stanza being processed, if event-based internal logging has been set up, or the name of the modular input
+func (mi *ModularInput) getLoggingStanza() string {
+	mi.writeMu.Lock()
+	defer mi.writeMu.Unlock()
+	if mi.internalLogEvent != nil {
+		return mi.internalLogEvent.Stanza
+	}
+	return mi.StanzaName
+}
+
+// parseLogLevel converts one of DEBUG, INFO, WARN (or WARNING), ERROR, FATAL into a slog level
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "INFO":
+		return slog.LevelInfo, nil
+	case "WARN", "WARNING":
+		return slog.LevelWarn, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	}
+	fmt.Fprintf(os.Stderr, "ERROR - ModularInput.Log invoked with invalid level parameter. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'\n", level)
+	return 0, fmt.Errorf("ModularInput.Log: invalid value of 'level' provided. Accepted: DEBUG, INFO, WARN, ERROR, FATAL. Provided: '%s'", level)
+}
+
+// levelName returns the name used by splunk for level
+func levelName(level slog.Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "FATAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARN"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	}
+	return "DEBUG"
+}
+
+// replaceLevelAttr is used with slog.HandlerOptions to name the levels as splunk does, e.g. FATAL instead of ERROR+4
+func replaceLevelAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(l))
+		}
+	}
+	return a
+}
+
+// debugLeveler enables DEBUG records only while the debug mode of the modular input is enabled
+type debugLeveler struct {
+	mi *ModularInput
+}
+
+func (d debugLeveler) Level() slog.Level {
+	if d.mi.debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// splunkEventHandler is the slog handler returned by NewSplunkEventHandler
+type splunkEventHandler struct {
+	mi *ModularInput
+	// if true, records are always written on stderr
+	plain bool
+	// attrs are the attributes provided with WithAttrs, already formatted
+	attrs string
+	// groupPrefix is prepended to the keys of the attributes, after WithGroup has been used
+	groupPrefix string
+}
+
+// NewSplunkEventHandler returns a slog handler writing each record as a SplunkEvent to index=_internal, once
+// the modular input starts streaming, or as plain text on stderr until then.
+// The attributes of the record are serialized within the data of the event as key=value pairs, following the message.
+func NewSplunkEventHandler(mi *ModularInput) slog.Handler {
+	return &splunkEventHandler{mi: mi}
+}
+
+func (h *splunkEventHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || h.mi.debug
+}
+
+func (h *splunkEventHandler) Handle(_ context.Context, r slog.Record) (err error) {
+	var sb strings.Builder
+	sb.WriteString(levelName(r.Level))
+	sb.WriteString(" - ")
+	sb.WriteString(r.Message)
+	sb.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		appendAttr(&sb, h.groupPrefix, a)
+		return true
+	})
+
+	// writeMu serializes both the XML and the stderr outputs, as concurrent stanzas log at the same time and the writers need not be thread-safe
+	h.mi.writeMu.Lock()
+	defer h.mi.writeMu.Unlock()
+	if !h.plain && h.mi.internalLogEvent != nil {
+		t := r.Time.Round(time.Millisecond)
+		h.mi.internalLogEvent.Time = t
+		// prefix the message with timestamp
+		//time.Format uses a string with such parameters to define the output format: Mon Jan 2 15:04:05 -0700 MST 2006
+		h.mi.internalLogEvent.Data = "[" + t.Format("2006-01-02 15:04:05.000 -0700") + "] " + sb.String()
+		// using writeOut() to skip counting the events, as we do not want to count the internal logs...
+		_, err = h.mi.internalLogEvent.writeOut(h.mi.getStdout())
+		return err
+	}
+	// XML-based logging has not yet been activated: using STDERR instead
+	_, err = fmt.Fprint(h.mi.getStderr(), "ModularInput "+h.mi.StanzaName+": "+sb.String()+"\n")
+	return err
+}
+
+func (h *splunkEventHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var sb strings.Builder
+	for _, a := range attrs {
+		appendAttr(&sb, h.groupPrefix, a)
+	}
+	h2 := *h
+	h2.attrs += sb.String()
+	return &h2
+}
+
+func (h *splunkEventHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groupPrefix += name + "."
+	return &h2
+}
+
+// appendAttr writes a as ' key=value' into sb. Attributes of groups are written with keys 'group.key'
+func appendAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			appendAttr(sb, prefix, ga)
+		}
+		return
+	}
+	var v string
+	if a.Value.Kind() == slog.KindTime {
+		v = a.Value.Time().Format(time.RFC3339Nano)
+	} else {
+		v = a.Value.String()
+	}
+	if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+		v = strconv.Quote(v)
+	}
+	sb.WriteString(" " + prefix + a.Key + "=" + v)
+}
@@ -0,0 +1,106 @@
+package modinputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogAttrsCustomHandler(t *testing.T) {
+	mi, _ := New("structured", "Structured", "")
+	buf := &bytes.Buffer{}
+	mi.SetLogger(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mi.LogAttrs(slog.LevelWarn, "quota reached", slog.Int("used", 10), slog.String("account", "acme"))
+	mi.Log("DEBUG", "not shown, debug is disabled")
+	mi.Log("info", "fetched %d items", 3)
+	if err := mi.Log("VERBOSE", "invalid level"); err == nil {
+		t.Error("Log accepted an invalid level")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d:\n%s", len(lines), buf.String())
+	}
+	rec := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec["level"] != "WARN" || rec["msg"] != "quota reached" || rec["used"] != float64(10) || rec["account"] != "acme" ||
+		rec["run_id"] != mi.GetRunId() || rec["stanza"] != "structured" {
+		t.Errorf("wrong structured record: %v", rec)
+	}
+	if !strings.Contains(lines[1], `"msg":"fetched 3 items"`) {
+		t.Errorf("Log did not format the message: %s", lines[1])
+	}
+}
+
+func TestSplunkEventHandler(t *testing.T) {
+	mi, _ := New("structured", "Structured", "")
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	mi.stdout, mi.stderr = stdout, stderr
+	logger := slog.New(NewSplunkEventHandler(mi)).With("account", "acme corp")
+
+	logger.Info("before streaming", slog.Group("req", slog.Int("n", 1)))
+	if s := stderr.String(); !strings.HasPrefix(s, "ModularInput structured: INFO - before streaming") || !strings.Contains(s, `account="acme corp" req.n=1`) {
+		t.Errorf("wrong plain-text log on stderr: '%s'", s)
+	}
+
+	stanza := Stanza{Name: "structured://one"}
+	mi.setupEventBasedInternalLogging(&stanza)
+	stdout.Reset()
+	mi.LogAttrs(LevelFatal, "giving up", slog.Bool("retry", false))
+	s := stdout.String()
+	for _, expected := range []string{"<index>_internal</index>", "FATAL - giving up", "run_id=" + mi.GetRunId(), "stanza=structured://one", "retry=false"} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("event written to index=_internal does not contain '%s': %s", expected, s)
+		}
+	}
+}
+
+func TestLogAtTerminal(t *testing.T) {
+	defer func(v bool) { isAtTerminal = v }(isAtTerminal)
+	isAtTerminal = true
+
+	mi, _ := New("structured", "Structured", "")
+	stderr := &strings.Builder{}
+	mi.stderr = stderr
+	mi.EnableDebug()
+	mi.Log("DEBUG", "details")
+	mi.Log("FATAL", "stopping")
+	s := stderr.String()
+	if !strings.Contains(s, "level=DEBUG msg=details run_id="+mi.GetRunId()+" stanza=structured") || !strings.Contains(s, "level=FATAL msg=stopping") {
+		t.Errorf("wrong text logs at terminal: '%s'", s)
+	}
+}
+
+func TestLogAtTerminalConcurrent(t *testing.T) {
+	defer func(v bool) { isAtTerminal = v }(isAtTerminal)
+	isAtTerminal = true
+
+	mi, _ := New("structured", "Structured", "")
+	stderr := &strings.Builder{}
+	mi.stderr = stderr
+	if mi.getLogHandler(false) != mi.getLogHandler(true) {
+		t.Error("getLogHandler created a new handler at each invocation")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			mi.Log("INFO", "message %d", i)
+			// plain logs are written on stderr by the event handler, sharing the same lock
+			(&splunkEventHandler{mi: mi, plain: true}).Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "plain", 0))
+		}(i)
+	}
+	wg.Wait()
+	if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); len(lines) != 20 {
+		t.Errorf("concurrent logs were not written as separate lines. expected=20 got=%d", len(lines))
+	}
+}
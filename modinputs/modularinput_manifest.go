@@ -0,0 +1,22 @@
+package modinputs
+
+import "github.com/prigio/splunk-go-sdk/alertactions"
+
+// SetAppManifestConfig configures the settings used by GenerateAppManifest
+func (mi *ModularInput) SetAppManifestConfig(cfg alertactions.AppManifestConfig) {
+	mi.appManifestConfig = cfg
+}
+
+// GenerateAppManifest returns the JSON-formatted app.manifest of the app appName containing the modular input, as required for Splunkbase packaging.
+// If description is empty, the description of the modular input is used. The titles of the registered arguments are appended to it.
+// Further settings such as the license can be configured with SetAppManifestConfig.
+func (mi *ModularInput) GenerateAppManifest(appName, version, author, description string) string {
+	if description == "" {
+		description = mi.Description
+	}
+	titles := make([]string, 0, len(mi.Args))
+	for _, arg := range mi.Args {
+		titles = append(titles, arg.Title)
+	}
+	return mi.appManifestConfig.Generate(appName, version, author, mi.Title, description, titles, "_search_heads", "_forwarders")
+}
@@ -0,0 +1,118 @@
+package modinputs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiterStats provides statistics about the rate limiter configured with SetRateLimit
+type RateLimiterStats struct {
+	// Limit is the maximum number of events written per second. 0 if rate limiting is not active
+	Limit float64
+	// Burst is the maximum number of events written at once
+	Burst int
+	// EventsWritten is the number of events which went through the rate limiter
+	EventsWritten int64
+	// EventsDelayed is the number of events which had to wait before being written
+	EventsDelayed int64
+	// EventsDropped is the number of events which were not written, as the streaming function got cancelled while waiting
+	EventsDropped int64
+	// CurrentRate is the average number of events written per second since the first event went through the rate limiter
+	CurrentRate float64
+}
+
+// rateLimitCounters tracks the statistics of the rate limiter. Fields are accessed atomically
+type rateLimitCounters struct {
+	written, delayed, dropped int64
+	// start is the time, as unix nanoseconds, when the first event went through the rate limiter
+	start int64
+}
+
+// SetRateLimit limits the number of events written per second by WriteToSplunk and WriteToSplunkBatch, to avoid overwhelming the indexers.
+// When the limit is exceeded, writing blocks until the events can be written, or until the streaming function gets cancelled.
+// The limit is shared by all the goroutines writing events, e.g. when processing stanzas concurrently in single-instance mode.
+// Unless configured with SetBurstLimit, the burst size is eventsPerSecond, rounded up. Use 0 to disable rate limiting.
+func (mi *ModularInput) SetRateLimit(eventsPerSecond float64) error {
+	if eventsPerSecond < 0 || math.IsNaN(eventsPerSecond) || math.IsInf(eventsPerSecond, 0) {
+		return utils.NewErrInvalidParam("SetRateLimit", nil, "'eventsPerSecond' must be a positive number, or 0 to disable rate limiting. provided: %f", eventsPerSecond)
+	}
+	if eventsPerSecond == 0 {
+		mi.rateLimiter = nil
+		return nil
+	}
+	burst := mi.rateLimitBurst
+	if burst == 0 {
+		burst = int(math.Ceil(eventsPerSecond))
+	}
+	mi.rateLimiter = rate.NewLimiter(rate.Limit(eventsPerSecond), burst)
+	return nil
+}
+
+// SetBurstLimit configures the maximum number of events which can be written at once without waiting, when a rate limit is configured with SetRateLimit.
+func (mi *ModularInput) SetBurstLimit(burst int) error {
+	if burst < 1 {
+		return utils.NewErrInvalidParam("SetBurstLimit", nil, "'burst' must be at least 1. provided: %d", burst)
+	}
+	mi.rateLimitBurst = burst
+	if mi.rateLimiter != nil {
+		mi.rateLimiter.SetBurst(burst)
+	}
+	return nil
+}
+
+// GetRateLimiterStats returns statistics about the rate limiter configured with SetRateLimit
+func (mi *ModularInput) GetRateLimiterStats() RateLimiterStats {
+	stats := RateLimiterStats{
+		EventsWritten: atomic.LoadInt64(&mi.rateLimitStats.written),
+		EventsDelayed: atomic.LoadInt64(&mi.rateLimitStats.delayed),
+		EventsDropped: atomic.LoadInt64(&mi.rateLimitStats.dropped),
+	}
+	if mi.rateLimiter != nil {
+		stats.Limit = float64(mi.rateLimiter.Limit())
+		stats.Burst = mi.rateLimiter.Burst()
+	}
+	if start := atomic.LoadInt64(&mi.rateLimitStats.start); start > 0 {
+		if elapsed := time.Since(time.Unix(0, start)).Seconds(); elapsed > 0 {
+			stats.CurrentRate = float64(stats.EventsWritten) / elapsed
+		}
+	}
+	return stats
+}
+
+// waitForRateLimit blocks until n events can be written according to the configured rate limit.
+// It returns an error if the streaming function gets cancelled meanwhile.
+func (mi *ModularInput) waitForRateLimit(n int) error {
+	lim := mi.rateLimiter
+	if lim == nil {
+		return nil
+	}
+	atomic.CompareAndSwapInt64(&mi.rateLimitStats.start, 0, time.Now().UnixNano())
+	if !lim.AllowN(time.Now(), n) {
+		mi.Log("DEBUG", "rate limiting active: waiting for token")
+		atomic.AddInt64(&mi.rateLimitStats.delayed, int64(n))
+		ctx := mi.rateLimitCtx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		// WaitN fails if n exceeds the burst size, so large batches wait for at most burst events at a time
+		for remaining := n; remaining > 0; {
+			chunk := remaining
+			if b := lim.Burst(); chunk > b {
+				chunk = b
+			}
+			if err := lim.WaitN(ctx, chunk); err != nil {
+				atomic.AddInt64(&mi.rateLimitStats.dropped, int64(n))
+				return fmt.Errorf("rate limiting: %w", err)
+			}
+			remaining -= chunk
+		}
+	}
+	atomic.AddInt64(&mi.rateLimitStats.written, int64(n))
+	return nil
+}
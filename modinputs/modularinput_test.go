@@ -1,8 +1,21 @@
 package modinputs
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/alertactions"
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
 func TestAddArgument(t *testing.T) {
@@ -23,7 +36,7 @@ func TestAddArgument(t *testing.T) {
 	}
 
 	for i, v := range cases {
-		mi.RegisterNewParam(v.name, v.title, v.description, v.dataType, v.defaultValue, v.validation, v.requiredOnCreate, v.requireOnEdit)
+		mi.RegisterNewParam(v.name, v.title, v.description, v.dataType, v.defaultValue, v.validation, v.requiredOnCreate, v.requireOnEdit, false)
 		if len(mi.Args) != i+1 {
 			t.Errorf("Argument %s not added", v.name)
 		}
@@ -46,7 +59,7 @@ func TestSchemeXML(t *testing.T) {
 		useExternalValidation: false,
 		useSingleInstance:     false,
 	}
-	mi.RegisterNewParam("one", "Param one", "Test parameter one, of string type, without validation", "", ArgDataTypeStr, "", true, true)
+	mi.RegisterNewParam("one", "Param one", "Test parameter one, of string type, without validation", "", ArgDataTypeStr, "", true, true, false)
 
 	// when modifying this, you need to pay attention that the editor
 	// may want to substitute spaces with tabs, thus causing tests to fail.
@@ -123,3 +136,350 @@ func TestEvent(t *testing.T) {
 	}
 
 }
+
+func TestGenerateDocumentation(t *testing.T) {
+	mi := &ModularInput{
+		StanzaName:  "teststanzaname",
+		Title:       "Test Scheme",
+		Description: "This is the description of the test scheme",
+	}
+	mi.RegisterNewParam("one", "Param one", "Test parameter one", "default1", ArgDataTypeStr, "", true, true, false)
+	mi.SetDocumentation("Some custom documentation")
+
+	doc := mi.generateDocumentation()
+	for _, expected := range []string{"`teststanzaname://<name>`", "| `one` | Param one | true | `default1` |", "Some custom documentation"} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("generateDocumentation() did not contain '%s'. Generated:\n%s", expected, doc)
+		}
+	}
+}
+
+func TestAutoRerun(t *testing.T) {
+	mi, _ := New("test-rerun", "Test rerun", "")
+	mi.stdout = new(strings.Builder)
+	mi.stderr = new(strings.Builder)
+	mi.stanzas = []Stanza{{Name: "test-rerun://one"}}
+	if err := mi.SetAutoRerun(-time.Second); err == nil {
+		t.Error("SetAutoRerun accepted a negative interval")
+	}
+	if err := mi.SetAutoRerun(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runIDs := make(map[string]bool)
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error {
+		runIDs[mi.GetRunId()] = true
+		if len(runIDs) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if err := mi.runStreamingLoop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(runIDs) != 3 {
+		t.Errorf("auto-rerun did not stop after the context was cancelled or did not update the run id. expected=3 distinct run ids, got=%d", len(runIDs))
+	}
+
+	iterations := 0
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error {
+		iterations++
+		return fmt.Errorf("failure")
+	})
+	if err := mi.runStreamingLoop(context.Background()); err == nil || iterations != 1 {
+		t.Errorf("auto-rerun did not stop after a failed execution. iterations=%d err=%v", iterations, err)
+	}
+}
+
+func TestStreamWrapper(t *testing.T) {
+	mi, _ := New("test-wrapper", "Test wrapper", "")
+	mi.stderr = new(strings.Builder)
+	mi.stanzas = []Stanza{{Name: "test-wrapper://one"}}
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error { return nil })
+
+	stdout := new(strings.Builder)
+	mi.stdout = stdout
+	if err := mi.runStreaming(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// internal logs are written as events within the stream
+	if out := stdout.String(); !strings.HasPrefix(out, "<stream>\n<event") || !strings.HasSuffix(out, "</event>\n</stream>\n") {
+		t.Errorf("wrong default stream wrapper: '%s'", stdout.String())
+	}
+
+	stdout.Reset()
+	mi.SetStreamWrapper("", "")
+	if err := mi.runStreaming(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out := stdout.String(); strings.Contains(out, "<stream>") || !strings.HasPrefix(out, "<event") || !strings.HasSuffix(out, "</event>\n") {
+		t.Errorf("stream wrapper not suppressed: '%s'", stdout.String())
+	}
+
+	stdout.Reset()
+	mi.SetStreamWrapper("<events>", "</events>")
+	if err := mi.runStreaming(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if out := stdout.String(); !strings.HasPrefix(out, "<events>\n<event") || !strings.HasSuffix(out, "</event>\n</events>\n") {
+		t.Errorf("custom stream wrapper not used: '%s'", stdout.String())
+	}
+}
+
+func TestValidateScheme(t *testing.T) {
+	mi, _ := New("test-scheme", "Test scheme", "")
+	mi.RegisterNewParam("usual", "Usual", "", "", ArgDataTypeStr, "", true, true, false)
+	if warnings := mi.ValidateScheme(); len(warnings) != 0 {
+		t.Errorf("ValidateScheme returned unexpected warnings: %v", warnings)
+	}
+	mi.RegisterNewParam("unusual", "Unusual", "", "", ArgDataTypeStr, "", false, true, false)
+	if warnings := mi.ValidateScheme(); len(warnings) != 1 || !strings.Contains(warnings[0], `"unusual"`) {
+		t.Errorf("ValidateScheme did not warn about argument required on edit only: %v", warnings)
+	}
+}
+
+func TestRegisterParamsFromMap(t *testing.T) {
+	mi, _ := New("test-params-map", "Test params map", "")
+	err := mi.RegisterParamsFromMap(map[string]InputArgConfig{
+		"url":     {Title: "URL", DataType: ArgDataTypeStr, RequiredOnCreate: true},
+		"timeout": {Title: "Timeout", DataType: ArgDataTypeNumber, DefaultValue: "10"},
+		"broken":  {Title: "", DataType: ArgDataTypeStr},
+		"wrong":   {Title: "Wrong", DataType: "int"},
+	})
+	var multiErr *utils.MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 2 {
+		t.Errorf("RegisterParamsFromMap did not return a MultiError with 2 errors: %v", err)
+	}
+	if names := mi.GetParamNames(); strings.Join(names, ",") != "timeout,url" {
+		t.Errorf("RegisterParamsFromMap registered wrong arguments: %v", names)
+	}
+}
+
+func TestRegisterGlobalParam(t *testing.T) {
+	shared, err := alertactions.NewGlobalParam("my_app", "settings", "api_url", "API URL", "", "https://localhost", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aa, _ := alertactions.New("test-shared", "Test shared", "", "")
+	mi, _ := New("test-shared", "Test shared", "")
+	if err = aa.RegisterGlobalParam(shared); err != nil {
+		t.Fatal(err)
+	}
+	if err = mi.RegisterGlobalParam(shared); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := mi.GetGlobalParam("api_url"); err != nil || p != shared {
+		t.Errorf("RegisterGlobalParam did not register the shared parameter. err=%v", err)
+	}
+	if err = mi.RegisterGlobalParam(shared); err == nil {
+		t.Error("RegisterGlobalParam accepted a duplicate parameter")
+	}
+}
+
+func TestStreamingCancellation(t *testing.T) {
+	mi, _ := New("test-cancel", "Test cancel", "")
+	mi.stderr = new(strings.Builder)
+	mi.stanzas = []Stanza{{Name: "test-cancel://one"}}
+	stdout := new(strings.Builder)
+	mi.stdout = stdout
+
+	// a streaming function observing the context returns as soon as it gets cancelled
+	mi.RegisterStreamingFuncWithContext(func(ctx context.Context, mi *ModularInput, s Stanza) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := mi.runStreaming(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("runStreaming did not return the cancellation error: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("runStreaming did not return promptly after cancellation")
+	}
+	if !strings.HasSuffix(stdout.String(), "</stream>\n") {
+		t.Errorf("runStreaming did not close the stream after cancellation: '%s'", stdout.String())
+	}
+
+	// a streaming function ignoring the context is abandoned after the shutdown timeout
+	block := make(chan struct{})
+	defer close(block)
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error {
+		<-block
+		return nil
+	})
+	if err := mi.SetShutdownTimeout(20 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := mi.runStreaming(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("runStreaming did not fail after the shutdown timeout: %v", err)
+	}
+	if !strings.HasSuffix(stdout.String(), "</stream>\n") {
+		t.Errorf("runStreaming did not close the stream after the shutdown timeout: '%s'", stdout.String())
+	}
+}
+
+func TestSensitiveParamMasking(t *testing.T) {
+	mi := &ModularInput{StanzaName: "sensitive", Title: "Sensitive", Description: "Test sensitive params"}
+	mi.RegisterNewParam("user", "User", "", "", ArgDataTypeStr, "", true, true, false)
+	mi.RegisterNewParam("api_key", "API key", "", "", ArgDataTypeStr, "", true, true, true)
+	mi.RegisterStreamingFunc(func(mi *ModularInput, s Stanza) error { return nil })
+	mi.RegisterValidationFunc(func(mi *ModularInput, s Stanza) error { return nil })
+	mi.EnableDebug()
+
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	if err := mi.Run([]string{"sensitive", "--test-stanza", "--param", "user=bob", "--param", "api_key=s3cr3t"}, strings.NewReader(""), stdout, stderr); err != nil {
+		t.Fatalf("Run returned an error: %s", err.Error())
+	}
+	validation := `<items><server_host>h</server_host><item name="sensitive"><param name="user">bob</param><param name="api_key">s3cr3t</param></item></items>`
+	mi.internalLogEvent = nil
+	if err := mi.Run([]string{"sensitive", "--validate-arguments"}, strings.NewReader(validation), stdout, stderr); err != nil {
+		t.Fatalf("Run with --validate-arguments returned an error: %s", err.Error())
+	}
+	logs := stderr.String() + stdout.String()
+	if strings.Contains(logs, "s3cr3t") {
+		t.Errorf("the value of a sensitive parameter was logged in plaintext:\n%s", logs)
+	}
+	if strings.Count(logs, maskedValue) != 3 || !strings.Contains(logs, "bob") {
+		t.Errorf("the values of the parameters were not logged as expected:\n%s", logs)
+	}
+
+	for name, conf := range map[string]string{"inputs.conf": mi.generateInputsConf(), "inputs.conf.spec": mi.generateInputsSpec()} {
+		if strings.Count(conf, sensitiveComment) != 1 {
+			t.Errorf("generated %s does not mark exactly one parameter as sensitive:\n%s", name, conf)
+		}
+	}
+
+	// the default values of sensitive parameters are never written within generated files
+	mi.RegisterNewParam("token", "Token", "", "d3fault-t0ken", ArgDataTypeStr, "", false, false, true)
+	generated := map[string]string{
+		"inputs.conf":      mi.generateInputsConf(),
+		"inputs.conf.spec": mi.generateInputsSpec(),
+		"example conf":     mi.generateExampleConf(),
+		"documentation":    mi.generateDocumentation(),
+	}
+	for name, out := range generated {
+		if strings.Contains(out, "d3fault-t0ken") {
+			t.Errorf("generated %s exposes the default value of a sensitive parameter:\n%s", name, out)
+		}
+	}
+	if !strings.Contains(generated["inputs.conf"], "token = <sensitive>") || !strings.Contains(generated["documentation"], "(sensitive — not shown)") {
+		t.Errorf("the default value of the sensitive parameter was not masked:\n%s\n%s", generated["inputs.conf"], generated["documentation"])
+	}
+}
+
+func TestEnvOverrideForAll(t *testing.T) {
+	mi, _ := New("test-env", "Test env", "")
+	mi.stderr = new(strings.Builder)
+	if _, err := mi.RegisterNewParam("api_url", "API URL", "", "https://localhost", "string", "", false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	mi.EnableEnvOverrideForAll("my-input_")
+	if _, err := mi.RegisterNewParam("max_items", "Max items", "", "10", "number", "", false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	shared, err := mi.RegisterNewGlobalParam("my_app", "settings", "region", "Region", "", "eu", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mi.Args[0].EnvVarOverride != "MY_INPUT_API_URL" || mi.Args[1].EnvVarOverride != "MY_INPUT_MAX_ITEMS" || shared.GetEnvVarOverride() != "MY_INPUT_REGION" {
+		t.Errorf("wrong environment variable names: %s, %s, %s", mi.Args[0].EnvVarOverride, mi.Args[1].EnvVarOverride, shared.GetEnvVarOverride())
+	}
+
+	t.Setenv("MY_INPUT_API_URL", "https://from-env.example.com")
+	t.Setenv("MY_INPUT_REGION", "us")
+	stanzas := []Stanza{{Name: "test-env://one", Params: []Param{{Name: "api_url", Value: "https://example.com"}}}}
+	mi.applyEnvOverrides(stanzas)
+	if v := stanzas[0].Param("api_url"); v != "https://from-env.example.com" {
+		t.Errorf("stanza parameter was not overridden. value=%s", v)
+	}
+	if v := stanzas[0].Param("max_items"); v != "" {
+		t.Errorf("stanza parameter overridden by an unset environment variable. value=%s", v)
+	}
+	if shared.GetValue() != "us" {
+		t.Errorf("global parameter was not overridden. value=%s", shared.GetValue())
+	}
+	if spec := mi.generateInputsSpec(); !strings.Contains(spec, "MY_INPUT_MAX_ITEMS") {
+		t.Errorf("inputs.conf.spec does not document the environment variables: %s", spec)
+	}
+	if conf := mi.generateInputsConf(); !strings.Contains(conf, "MY_INPUT_API_URL") {
+		t.Errorf("inputs.conf does not document the environment variables: %s", conf)
+	}
+}
+
+func TestGenerateAppManifest(t *testing.T) {
+	mi, _ := New("test-manifest", "Test manifest", "Collects tickets.")
+	if _, err := mi.RegisterNewParam("api_url", "API URL", "", "", "string", "", false, false, false); err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Info struct {
+			Description string `json:"description"`
+			License     struct {
+				Type string `json:"type"`
+			} `json:"license"`
+		} `json:"info"`
+		TargetWorkloads []string `json:"targetWorkloads"`
+	}
+	generated := mi.GenerateAppManifest("TA-tickets", "1.0.0", "Jane Doe", "")
+	if err := json.Unmarshal([]byte(generated), &manifest); err != nil {
+		t.Fatalf("invalid JSON generated: %s\n%s", err.Error(), generated)
+	}
+	if manifest.Info.Description != "Collects tickets. Configurable parameters: API URL." || manifest.Info.License.Type == "" || len(manifest.TargetWorkloads) != 2 {
+		t.Errorf("wrong manifest generated: %s", generated)
+	}
+}
+
+func TestGetSplunkServiceWithHealthCheck(t *testing.T) {
+	var mu sync.Mutex
+	validKey := "from-splunk"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/services/auth/login" {
+			body, _ := io.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			if form.Get("password") != "changeme" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			validKey = "refreshed"
+			fmt.Fprint(w, `{"sessionKey":"refreshed"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Splunk "+validKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"entry":[{"name":"context","content":{"username":"svc"}}]}`)
+	}))
+	defer srv.Close()
+	mi, _ := New("test-health", "Test health", "")
+	mi.stderr = new(strings.Builder)
+	mi.uri, mi.sessionKey = srv.URL, "from-splunk"
+
+	ss, err := mi.GetSplunkServiceWithHealthCheck()
+	if err != nil || ss.GetSessionKey() != "from-splunk" {
+		t.Fatalf("GetSplunkServiceWithHealthCheck failed with a valid session. err=%v", err)
+	}
+	// the session key provided by splunk expires
+	mu.Lock()
+	validKey = "expired"
+	mu.Unlock()
+	if _, err := mi.GetSplunkServiceWithHealthCheck(); err == nil {
+		t.Error("GetSplunkServiceWithHealthCheck did not fail for an expired session without credentials")
+	}
+	mi.SetSplunkServiceCredentials("svc", "changeme")
+	if ss, err = mi.GetSplunkServiceWithHealthCheck(); err != nil || ss.GetSessionKey() != "refreshed" {
+		t.Fatalf("GetSplunkServiceWithHealthCheck did not refresh the session. err=%v", err)
+	}
+	if err := ss.Ping(); err != nil {
+		t.Errorf("refreshed session is not valid: %s", err.Error())
+	}
+}
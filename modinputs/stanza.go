@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Stanza represents the configuration for a modular input found in inputs.conf
@@ -61,6 +65,208 @@ func (s *Stanza) Param(name string) string {
 	return ""
 }
 
+// setParam sets the value of the parameter with the specified name, adding it if not present
+func (s *Stanza) setParam(name, value string) {
+	for i, p := range s.Params {
+		if strings.ToLower(p.Name) == name {
+			s.Params[i].Value = value
+			return
+		}
+	}
+	s.Params = append(s.Params, Param{Name: name, Value: value})
+}
+
+// ErrParamNotFound is returned by the typed ParamAs* functions when the requested parameter
+// is not defined within the stanza, or is defined with an empty value
+type ErrParamNotFound struct {
+	Stanza string
+	Name   string
+}
+
+func (e *ErrParamNotFound) Error() string {
+	return fmt.Sprintf("stanza %s: parameter '%s' not found", e.Stanza, e.Name)
+}
+
+// ErrParamConversion is returned by the typed ParamAs* functions when the value of the
+// requested parameter cannot be converted to the requested type
+type ErrParamConversion struct {
+	Stanza string
+	Name   string
+	Value  string
+	Type   string
+	Err    error
+}
+
+func (e *ErrParamConversion) Error() string {
+	msg := fmt.Sprintf("stanza %s: cannot convert value '%s' of parameter '%s' to %s", e.Stanza, e.Value, e.Name, e.Type)
+	if e.Err != nil {
+		msg = msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ErrParamConversion) Unwrap() error {
+	return e.Err
+}
+
+// paramValue returns the trimmed value of the parameter with the specified name,
+// or an *ErrParamNotFound if the parameter is missing or empty
+func (s *Stanza) paramValue(name string) (string, error) {
+	for _, p := range s.Params {
+		if strings.ToLower(p.Name) == name {
+			if v := strings.TrimSpace(p.Value); v != "" {
+				return v, nil
+			}
+			break
+		}
+	}
+	return "", &ErrParamNotFound{Stanza: s.Name, Name: name}
+}
+
+// ParamAsBool returns the value of the parameter with the specified name as a boolean.
+// "1", "true", "yes", "on" are considered true and "0", "false", "no", "off" false (case-insensitive).
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion for any other value.
+func (s *Stanza) ParamAsBool(name string) (bool, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(v) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "bool"}
+}
+
+// ParamAsInt returns the value of the parameter with the specified name as an integer.
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion if it is not an integer.
+func (s *Stanza) ParamAsInt(name string) (int64, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "int", Err: err}
+	}
+	return i, nil
+}
+
+// ParamAsFloat64 returns the value of the parameter with the specified name as a floating point number.
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion if it is not a number.
+func (s *Stanza) ParamAsFloat64(name string) (float64, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "float64", Err: err}
+	}
+	return f, nil
+}
+
+// ParamAsDuration returns the value of the parameter with the specified name as a time.Duration.
+// Go duration strings such as "30s" or "1h30m" are accepted, as well as plain integers which are
+// interpreted as a number of seconds, following the convention of the 'interval' setting of inputs.conf.
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion if it is not a duration.
+func (s *Stanza) ParamAsDuration(name string) (time.Duration, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return 0, err
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "duration", Err: err}
+	}
+	return d, nil
+}
+
+// ParamAsURL returns the value of the parameter with the specified name as a URL, which must have both a scheme and a host,
+// such as "https://api.example.com/v1".
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion if it is not such a URL.
+func (s *Stanza) ParamAsURL(name string) (*url.URL, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "URL", Err: err}
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "URL", Err: fmt.Errorf("scheme and host are required")}
+	}
+	return u, nil
+}
+
+// ParamAsRegexp returns the value of the parameter with the specified name compiled as a regular expression.
+// Returns *ErrParamNotFound if the parameter is missing or empty, *ErrParamConversion if it is not a valid regular expression.
+func (s *Stanza) ParamAsRegexp(name string) (*regexp.Regexp, error) {
+	v, err := s.paramValue(name)
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexp.Compile(v)
+	if err != nil {
+		return nil, &ErrParamConversion{Stanza: s.Name, Name: name, Value: v, Type: "regexp", Err: err}
+	}
+	return re, nil
+}
+
+// ParamAsBoolOr returns the value of the parameter as a boolean, or defaultVal if the parameter is missing or invalid
+func (s *Stanza) ParamAsBoolOr(name string, defaultVal bool) bool {
+	if b, err := s.ParamAsBool(name); err == nil {
+		return b
+	}
+	return defaultVal
+}
+
+// ParamAsIntOr returns the value of the parameter as an integer, or defaultVal if the parameter is missing or invalid
+func (s *Stanza) ParamAsIntOr(name string, defaultVal int64) int64 {
+	if i, err := s.ParamAsInt(name); err == nil {
+		return i
+	}
+	return defaultVal
+}
+
+// ParamAsFloat64Or returns the value of the parameter as a floating point number, or defaultVal if the parameter is missing or invalid
+func (s *Stanza) ParamAsFloat64Or(name string, defaultVal float64) float64 {
+	if f, err := s.ParamAsFloat64(name); err == nil {
+		return f
+	}
+	return defaultVal
+}
+
+// ParamAsDurationOr returns the value of the parameter as a time.Duration, or defaultVal if the parameter is missing or invalid
+func (s *Stanza) ParamAsDurationOr(name string, defaultVal time.Duration) time.Duration {
+	if d, err := s.ParamAsDuration(name); err == nil {
+		return d
+	}
+	return defaultVal
+}
+
+// ParamAsURLOr returns the value of the parameter as a URL, or fallback if the parameter is missing or invalid
+func (s *Stanza) ParamAsURLOr(name string, fallback *url.URL) *url.URL {
+	if u, err := s.ParamAsURL(name); err == nil {
+		return u
+	}
+	return fallback
+}
+
+// ParamAsRegexpOr returns the value of the parameter as a regular expression, or fallback if the parameter is missing or invalid
+func (s *Stanza) ParamAsRegexpOr(name string, fallback *regexp.Regexp) *regexp.Regexp {
+	if re, err := s.ParamAsRegexp(name); err == nil {
+		return re
+	}
+	return fallback
+}
+
 // ParamAsCSVList scans the stanza s parameters to find the param with the specified name; it then:
 // - splits its value on commas ','
 // - trims emtpy spaces from the resulting values
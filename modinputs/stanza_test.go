@@ -1,7 +1,11 @@
 package modinputs
 
 import (
+	"errors"
+	"net/url"
+	"regexp"
 	"testing"
+	"time"
 )
 
 func TestParameterRetrieval(t *testing.T) {
@@ -77,3 +81,136 @@ func TestParamCSV(t *testing.T) {
 	}
 
 }
+
+func TestParamTyped(t *testing.T) {
+	s := &Stanza{
+		Name: "teststz://t1",
+		Params: []Param{
+			{Name: "b_true", Value: "Yes"},
+			{Name: "b_on", Value: " on "},
+			{Name: "b_false", Value: "0"},
+			{Name: "b_bad", Value: "maybe"},
+			{Name: "i", Value: "-42"},
+			{Name: "i_bad", Value: "4x2"},
+			{Name: "f", Value: "3.5"},
+			{Name: "d_go", Value: "1m30s"},
+			{Name: "d_secs", Value: "60"},
+			{Name: "d_bad", Value: "soon"},
+			{Name: "empty", Value: ""},
+		},
+	}
+
+	var notFound *ErrParamNotFound
+	var conversion *ErrParamConversion
+
+	if b, err := s.ParamAsBool("b_true"); err != nil || !b {
+		t.Errorf("ParamAsBool: expected true for 'Yes'. got=%v err=%v", b, err)
+	}
+	if b, err := s.ParamAsBool("b_on"); err != nil || !b {
+		t.Errorf("ParamAsBool: expected true for ' on '. got=%v err=%v", b, err)
+	}
+	if b, err := s.ParamAsBool("b_false"); err != nil || b {
+		t.Errorf("ParamAsBool: expected false for '0'. got=%v err=%v", b, err)
+	}
+	if _, err := s.ParamAsBool("b_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsBool: expected ErrParamConversion for 'maybe'. got=%v", err)
+	}
+	if _, err := s.ParamAsBool("missing"); !errors.As(err, &notFound) {
+		t.Errorf("ParamAsBool: expected ErrParamNotFound for a missing parameter. got=%v", err)
+	}
+	if _, err := s.ParamAsBool("empty"); !errors.As(err, &notFound) {
+		t.Errorf("ParamAsBool: expected ErrParamNotFound for an empty parameter. got=%v", err)
+	}
+
+	if i, err := s.ParamAsInt("i"); err != nil || i != -42 {
+		t.Errorf("ParamAsInt: expected -42. got=%d err=%v", i, err)
+	}
+	if _, err := s.ParamAsInt("i_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsInt: expected ErrParamConversion for '4x2'. got=%v", err)
+	}
+	if _, err := s.ParamAsInt("empty"); !errors.As(err, &notFound) {
+		t.Errorf("ParamAsInt: expected ErrParamNotFound for an empty parameter. got=%v", err)
+	}
+
+	if f, err := s.ParamAsFloat64("f"); err != nil || f != 3.5 {
+		t.Errorf("ParamAsFloat64: expected 3.5. got=%f err=%v", f, err)
+	}
+	if _, err := s.ParamAsFloat64("b_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsFloat64: expected ErrParamConversion for 'maybe'. got=%v", err)
+	}
+
+	if d, err := s.ParamAsDuration("d_go"); err != nil || d != 90*time.Second {
+		t.Errorf("ParamAsDuration: expected 1m30s. got=%s err=%v", d, err)
+	}
+	if d, err := s.ParamAsDuration("d_secs"); err != nil || d != time.Minute {
+		t.Errorf("ParamAsDuration: expected plain integers to be seconds. got=%s err=%v", d, err)
+	}
+	if _, err := s.ParamAsDuration("d_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsDuration: expected ErrParamConversion for 'soon'. got=%v", err)
+	}
+	if _, err := s.ParamAsDuration("missing"); !errors.As(err, &notFound) {
+		t.Errorf("ParamAsDuration: expected ErrParamNotFound for a missing parameter. got=%v", err)
+	}
+
+	if !s.ParamAsBoolOr("missing", true) || s.ParamAsBoolOr("b_false", true) {
+		t.Error("ParamAsBoolOr did not return the expected values")
+	}
+	if s.ParamAsIntOr("i_bad", 7) != 7 || s.ParamAsIntOr("i", 7) != -42 {
+		t.Error("ParamAsIntOr did not return the expected values")
+	}
+	if s.ParamAsFloat64Or("empty", 1.5) != 1.5 || s.ParamAsFloat64Or("f", 1.5) != 3.5 {
+		t.Error("ParamAsFloat64Or did not return the expected values")
+	}
+	if s.ParamAsDurationOr("d_bad", time.Second) != time.Second || s.ParamAsDurationOr("d_secs", time.Second) != time.Minute {
+		t.Error("ParamAsDurationOr did not return the expected values")
+	}
+}
+
+func TestParamURLAndRegexp(t *testing.T) {
+	s := &Stanza{
+		Name: "teststz://t1",
+		Params: []Param{
+			{Name: "url", Value: " https://api.example.com:8443/v1?x=1 "},
+			{Name: "url_no_host", Value: "api.example.com/v1"},
+			{Name: "url_bad", Value: "http://[::1"},
+			{Name: "re", Value: `^user-\d+$`},
+			{Name: "re_bad", Value: `user-(\d+`},
+			{Name: "empty", Value: ""},
+		},
+	}
+
+	var notFound *ErrParamNotFound
+	var conversion *ErrParamConversion
+
+	if u, err := s.ParamAsURL("url"); err != nil || u.Scheme != "https" || u.Host != "api.example.com:8443" || u.Path != "/v1" {
+		t.Errorf("ParamAsURL: wrong URL returned. got=%v err=%v", u, err)
+	}
+	if u, err := s.ParamAsURL("url_no_host"); !errors.As(err, &conversion) || u != nil {
+		t.Errorf("ParamAsURL: expected ErrParamConversion for a URL without scheme and host. got=%v", err)
+	}
+	if _, err := s.ParamAsURL("url_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsURL: expected ErrParamConversion for an unparseable URL. got=%v", err)
+	}
+	if u, err := s.ParamAsURL("empty"); !errors.As(err, &notFound) || u != nil {
+		t.Errorf("ParamAsURL: expected ErrParamNotFound for an empty parameter. got=%v", err)
+	}
+
+	if re, err := s.ParamAsRegexp("re"); err != nil || !re.MatchString("user-42") || re.MatchString("user-x") {
+		t.Errorf("ParamAsRegexp: wrong regular expression returned. got=%v err=%v", re, err)
+	}
+	if _, err := s.ParamAsRegexp("re_bad"); !errors.As(err, &conversion) {
+		t.Errorf("ParamAsRegexp: expected ErrParamConversion for an invalid regular expression. got=%v", err)
+	}
+	if re, err := s.ParamAsRegexp("missing"); !errors.As(err, &notFound) || re != nil {
+		t.Errorf("ParamAsRegexp: expected ErrParamNotFound for a missing parameter. got=%v", err)
+	}
+
+	fallbackURL, _ := url.Parse("https://localhost")
+	fallbackRe := regexp.MustCompile(".*")
+	if s.ParamAsURLOr("url_no_host", fallbackURL) != fallbackURL || s.ParamAsURLOr("empty", nil) != nil || s.ParamAsURLOr("url", fallbackURL).Host != "api.example.com:8443" {
+		t.Error("ParamAsURLOr did not return the expected values")
+	}
+	if s.ParamAsRegexpOr("re_bad", fallbackRe) != fallbackRe || s.ParamAsRegexpOr("re", fallbackRe).String() != `^user-\d+$` {
+		t.Error("ParamAsRegexpOr did not return the expected values")
+	}
+}
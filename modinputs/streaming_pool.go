@@ -0,0 +1,163 @@
+package modinputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// RegisterStreamingFuncSingleInstanceWithPool registers a streaming function to be executed, in single-instance mode,
+// on each of the configuration stanzas provided by Splunk at run time.
+// Stanzas are processed concurrently, with at most maxConcurrency executions of f running at the same time.
+// The errors returned for the single stanzas are joined together, without interrupting the processing of the other stanzas.
+// Once the execution is cancelled, e.g. by SIGTERM, the stanzas which have not been started yet are skipped.
+// Use RegisterStreamingFuncSingleInstanceWithPoolContext for a streaming function which needs to be notified of cancellation.
+func (mi *ModularInput) RegisterStreamingFuncSingleInstanceWithPool(f StreamingFunc, maxConcurrency int) error {
+	if f == nil {
+		return mi.RegisterStreamingFuncSingleInstanceWithPoolContext(nil, maxConcurrency)
+	}
+	return mi.RegisterStreamingFuncSingleInstanceWithPoolContext(func(_ context.Context, mi *ModularInput, s Stanza) error {
+		return f(mi, s)
+	}, maxConcurrency)
+}
+
+// RegisterStreamingFuncSingleInstanceWithPoolContext is the same as RegisterStreamingFuncSingleInstanceWithPool,
+// for a streaming function receiving the context which is cancelled when the process receives SIGTERM or SIGINT.
+func (mi *ModularInput) RegisterStreamingFuncSingleInstanceWithPoolContext(f StreamingFuncWithContext, maxConcurrency int) error {
+	if f == nil {
+		return utils.NewErrInvalidParam("registerStreamingFuncSingleInstanceWithPool", nil, "'f' cannot be nil")
+	}
+	if err := mi.SetMaxStanzaConcurrency(maxConcurrency); err != nil {
+		return err
+	}
+	return mi.RegisterStreamingFuncSingleInstanceWithContext(func(ctx context.Context, mi *ModularInput, stanzas []Stanza) error {
+		return mi.runStanzaPool(ctx, f, stanzas)
+	})
+}
+
+// SetMaxStanzaConcurrency configures the maximum number of stanzas processed at the same time
+// by a streaming function registered with RegisterStreamingFuncSingleInstanceWithPool,
+// or validated at the same time by a validation function registered with RegisterValidationFuncForAll.
+func (mi *ModularInput) SetMaxStanzaConcurrency(n int) error {
+	if n < 1 {
+		return utils.NewErrInvalidParam("setMaxStanzaConcurrency", nil, "'n' must be at least 1, provided: %d", n)
+	}
+	mi.maxStanzaConcurrency = n
+	return nil
+}
+
+// SetStanzaErrorHandler registers f to be notified when the streaming function registered with RegisterStreamingFuncSingleInstanceWithPool fails on a stanza.
+// f is executed within the goroutine processing the failed stanza, and the error is not returned by the pool anymore.
+// If no handler is set, the errors of all stanzas are joined together and returned once all stanzas have been processed.
+// Use LogAndContinueErrorHandler to simply log the failures.
+func (mi *ModularInput) SetStanzaErrorHandler(f func(stanza Stanza, err error)) {
+	mi.stanzaErrorHandler = f
+}
+
+// LogAndContinueErrorHandler returns a stanza error handler, to be used with SetStanzaErrorHandler, which logs the failure and lets the other stanzas continue.
+func LogAndContinueErrorHandler(mi *ModularInput) func(Stanza, error) {
+	return func(stanza Stanza, err error) {
+		mi.Log("ERROR", `stanza="%s" failed, continuing with the other stanzas. error="%s"`, stanza.Name, err.Error())
+	}
+}
+
+// PanicRecoveringStreamFunc wraps f so that a panic is returned as an error instead of crashing the whole modular input.
+// This is useful within the streaming pool, where a panic of one stanza would otherwise interrupt all the others.
+// Use PanicRecoveringStreamFuncWithContext for a streaming function registered with RegisterStreamingFuncSingleInstanceWithPoolContext.
+func PanicRecoveringStreamFunc(f StreamingFunc) StreamingFunc {
+	return func(mi *ModularInput, stanza Stanza) (err error) {
+		defer recoverStanzaPanic(stanza, &err)
+		return f(mi, stanza)
+	}
+}
+
+// PanicRecoveringStreamFuncWithContext is the same as PanicRecoveringStreamFunc, for a streaming function receiving a context.
+func PanicRecoveringStreamFuncWithContext(f StreamingFuncWithContext) StreamingFuncWithContext {
+	return func(ctx context.Context, mi *ModularInput, stanza Stanza) (err error) {
+		defer recoverStanzaPanic(stanza, &err)
+		return f(ctx, mi, stanza)
+	}
+}
+
+// recoverStanzaPanic is deferred by the panic-recovering streaming functions to turn a panic into the error of the stanza
+func recoverStanzaPanic(stanza Stanza, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic while streaming stanza %s: %v", stanza.Name, r)
+	}
+}
+
+// runStanzaPool executes f on every stanza, using a semaphore to limit the concurrent executions to mi.maxStanzaConcurrency.
+// No further stanza is started once ctx is cancelled: the returned error then wraps ctx.Err().
+func (mi *ModularInput) runStanzaPool(ctx context.Context, f StreamingFuncWithContext, stanzas []Stanza) error {
+	maxConcurrency := mi.maxStanzaConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	mi.writeMu.Lock()
+	mi.cntDataEventsByStanzaName = make(map[string]int64, len(stanzas))
+	mi.writeMu.Unlock()
+
+	var (
+		wg        sync.WaitGroup
+		errMu     sync.Mutex
+		errs      []error
+		remaining = int64(len(stanzas))
+		sem       = make(chan struct{}, maxConcurrency)
+		handler   = mi.stanzaErrorHandler
+	)
+	for i, stanza := range stanzas {
+		// once ctx is cancelled, the stanzas which have not been started yet are skipped
+		acquired := false
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+				acquired = true
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			if acquired {
+				<-sem
+			}
+			mi.Log("WARN", "execution cancelled, %d stanzas not started", len(stanzas)-i)
+			errMu.Lock()
+			errs = append(errs, fmt.Errorf("%d stanzas not started: %w", len(stanzas)-i, ctx.Err()))
+			errMu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(stanza Stanza) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			startTime := time.Now()
+			err := f(ctx, mi, stanza)
+			duration := time.Since(startTime)
+
+			mi.writeMu.Lock()
+			cnt := mi.cntDataEventsByStanzaName[stanza.Name]
+			mi.writeMu.Unlock()
+			left := atomic.AddInt64(&remaining, -1)
+			if err != nil {
+				mi.Log("ERROR", `stanza="%s" status=failed duration_s=%.03f cnt_events=%d remaining=%d error="%s"`, stanza.Name, duration.Seconds(), cnt, left, err.Error())
+				if handler != nil {
+					handler(stanza, err)
+					return
+				}
+				errMu.Lock()
+				errs = append(errs, fmt.Errorf("stanza %s: %w", stanza.Name, err))
+				errMu.Unlock()
+			} else {
+				mi.Log("INFO", `stanza="%s" status=done duration_s=%.03f cnt_events=%d remaining=%d`, stanza.Name, duration.Seconds(), cnt, left)
+			}
+		}(stanza)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+package modinputs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamingPool(t *testing.T) {
+	mi, _ := New("testpool", "Test pool", "")
+	if err := mi.RegisterStreamingFuncSingleInstanceWithPool(func(mi *ModularInput, s Stanza) error { return nil }, 2); err == nil {
+		t.Error("RegisterStreamingFuncSingleInstanceWithPool did not return an error when single-instance mode is not active")
+	}
+	mi.SetSingleInstanceExecution()
+	if err := mi.RegisterStreamingFuncSingleInstanceWithPool(func(mi *ModularInput, s Stanza) error { return nil }, 0); err == nil {
+		t.Error("RegisterStreamingFuncSingleInstanceWithPool accepted maxConcurrency=0")
+	}
+
+	var running, maxRunning, processed int64
+	err := mi.RegisterStreamingFuncSingleInstanceWithPool(func(mi *ModularInput, s Stanza) error {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&processed, 1)
+		if strings.HasSuffix(s.Name, "3") || strings.HasSuffix(s.Name, "7") {
+			return errors.New("failure of " + s.Name)
+		}
+		return nil
+	}, 3)
+	if err != nil {
+		t.Fatalf("RegisterStreamingFuncSingleInstanceWithPool returned an error: %s", err.Error())
+	}
+
+	stanzas := make([]Stanza, 10)
+	for i := range stanzas {
+		stanzas[i] = Stanza{Name: "testpool://input" + strconv.Itoa(i)}
+	}
+	err = mi.streamSingleInstance(context.Background(), mi, stanzas)
+
+	if processed != 10 {
+		t.Errorf("the pool did not process all stanzas. expected=10 got=%d", processed)
+	}
+	if maxRunning > 3 {
+		t.Errorf("the pool exceeded the maximum concurrency. expected<=3 got=%d", maxRunning)
+	}
+	if err == nil || !strings.Contains(err.Error(), "input3") || !strings.Contains(err.Error(), "input7") {
+		t.Errorf("the pool did not join the errors of the failed stanzas. got=%v", err)
+	}
+
+	// the limit can be changed after registration
+	maxRunning = 0
+	mi.SetMaxStanzaConcurrency(1)
+	mi.streamSingleInstance(context.Background(), mi, stanzas)
+	if maxRunning != 1 {
+		t.Errorf("SetMaxStanzaConcurrency did not limit the concurrency. expected=1 got=%d", maxRunning)
+	}
+}
+
+func TestStreamingPoolErrorHandler(t *testing.T) {
+	mi, _ := New("testpool", "Test pool", "")
+	mi.stderr = io.Discard
+	mi.SetSingleInstanceExecution()
+
+	var processed int64
+	err := mi.RegisterStreamingFuncSingleInstanceWithPool(PanicRecoveringStreamFunc(func(mi *ModularInput, s Stanza) error {
+		switch s.Name {
+		case "testpool://input2":
+			panic("unexpected nil")
+		case "testpool://input5":
+			return errors.New("failure of " + s.Name)
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt64(&processed, 1)
+		return nil
+	}), 3)
+	if err != nil {
+		t.Fatalf("RegisterStreamingFuncSingleInstanceWithPool returned an error: %s", err.Error())
+	}
+	var (
+		mu     sync.Mutex
+		failed = make(map[string]string)
+	)
+	logAndContinue := LogAndContinueErrorHandler(mi)
+	mi.SetStanzaErrorHandler(func(s Stanza, err error) {
+		mu.Lock()
+		failed[s.Name] = err.Error()
+		mu.Unlock()
+		logAndContinue(s, err)
+	})
+
+	stanzas := make([]Stanza, 8)
+	for i := range stanzas {
+		stanzas[i] = Stanza{Name: "testpool://input" + strconv.Itoa(i)}
+	}
+	if err := mi.streamSingleInstance(context.Background(), mi, stanzas); err != nil {
+		t.Errorf("the pool returned the errors handled by the stanza error handler: %s", err.Error())
+	}
+	if processed != 6 {
+		t.Errorf("the failing stanzas prevented the others from completing. expected=6 got=%d", processed)
+	}
+	if len(failed) != 2 || !strings.Contains(failed["testpool://input2"], "panic") || !strings.Contains(failed["testpool://input5"], "failure of") {
+		t.Errorf("the stanza error handler was not invoked for the failed stanzas. got=%v", failed)
+	}
+
+	// without handler, the errors are aggregated again
+	mi.SetStanzaErrorHandler(nil)
+	if err := mi.streamSingleInstance(context.Background(), mi, stanzas); err == nil || !strings.Contains(err.Error(), "input2") || !strings.Contains(err.Error(), "input5") {
+		t.Errorf("the pool did not join the errors of the failed stanzas. got=%v", err)
+	}
+}
+
+func TestPanicRecoveringStreamFuncWithContext(t *testing.T) {
+	mi, _ := New("testpool", "Test pool", "")
+	mi.stderr = io.Discard
+	mi.SetSingleInstanceExecution()
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	var processed int64
+	err := mi.RegisterStreamingFuncSingleInstanceWithPoolContext(PanicRecoveringStreamFuncWithContext(func(ctx context.Context, mi *ModularInput, s Stanza) error {
+		if ctx.Value(ctxKey{}) != "value" {
+			return errors.New("context not propagated to " + s.Name)
+		}
+		if s.Name == "testpool://input1" {
+			panic("unexpected nil")
+		}
+		atomic.AddInt64(&processed, 1)
+		return nil
+	}), 2)
+	if err != nil {
+		t.Fatalf("RegisterStreamingFuncSingleInstanceWithPoolContext returned an error: %s", err.Error())
+	}
+	stanzas := make([]Stanza, 4)
+	for i := range stanzas {
+		stanzas[i] = Stanza{Name: "testpool://input" + strconv.Itoa(i)}
+	}
+	err = mi.streamSingleInstance(ctx, mi, stanzas)
+	if processed != 3 {
+		t.Errorf("the panicking stanza prevented the others from completing. expected=3 got=%d", processed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "panic while streaming stanza testpool://input1") || strings.Contains(err.Error(), "not propagated") {
+		t.Errorf("the panic was not returned as the error of the stanza. got=%v", err)
+	}
+}
+
+func TestStreamingPoolCancelled(t *testing.T) {
+	mi, _ := New("testpool", "Test pool", "")
+	mi.stderr = io.Discard
+	mi.SetSingleInstanceExecution()
+
+	var started int64
+	err := mi.RegisterStreamingFuncSingleInstanceWithPoolContext(func(ctx context.Context, mi *ModularInput, s Stanza) error {
+		atomic.AddInt64(&started, 1)
+		<-ctx.Done()
+		return nil
+	}, 1)
+	if err != nil {
+		t.Fatalf("RegisterStreamingFuncSingleInstanceWithPoolContext returned an error: %s", err.Error())
+	}
+	stanzas := make([]Stanza, 5)
+	for i := range stanzas {
+		stanzas[i] = Stanza{Name: "testpool://input" + strconv.Itoa(i)}
+	}
+	// the pool is waiting for the semaphore to start the second stanza when ctx gets cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = mi.streamSingleInstance(ctx, mi, stanzas)
+	if started != 1 {
+		t.Errorf("the pool started stanzas after the context got cancelled. expected=1 got=%d", started)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "4 stanzas not started") {
+		t.Errorf("the pool did not report the stanzas which were not started. got=%v", err)
+	}
+
+	started = 0
+	if err := mi.streamSingleInstance(ctx, mi, stanzas); started != 0 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("the pool started stanzas with a cancelled context. started=%d err=%v", started, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package testutil provides a harness to write unit tests of modular inputs without a running Splunk instance.
+// The streaming function is executed on test stanzas, as Splunk would do, and the generated events are captured for inspection.
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prigio/splunk-go-sdk/modinputs"
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// TestModularInput wraps a ModularInput, providing the configuration stanzas which Splunk would provide
+// and capturing the events and logs generated by its streaming function.
+type TestModularInput struct {
+	*modinputs.ModularInput
+	// CheckpointDir is provided to the modular input as checkpoint directory.
+	// If empty, a temporary directory is created by the first call to RunStreaming. Tests can set it to t.TempDir().
+	CheckpointDir string
+
+	stanzas []xmlStanza
+	events  []*modinputs.SplunkEvent
+	logs    []string
+}
+
+// xmlStanza is the representation of a stanza within the XML configuration provided by Splunk on stdin
+type xmlStanza struct {
+	Name   string     `xml:"name,attr"`
+	Params []xmlParam `xml:"param"`
+}
+
+type xmlParam struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlInput struct {
+	XMLName       xml.Name    `xml:"input"`
+	Hostname      string      `xml:"server_host"`
+	URI           string      `xml:"server_uri"`
+	SessionKey    string      `xml:"session_key"`
+	CheckpointDir string      `xml:"checkpoint_dir"`
+	Stanzas       []xmlStanza `xml:"configuration>stanza"`
+}
+
+// xmlEvent is the representation of an event within the XML stream written by the modular input
+type xmlEvent struct {
+	Stanza     string    `xml:"stanza,attr"`
+	Unbroken   string    `xml:"unbroken,attr"`
+	Time       string    `xml:"time"`
+	SourceType string    `xml:"sourcetype"`
+	Index      string    `xml:"index"`
+	Host       string    `xml:"host"`
+	Source     string    `xml:"source"`
+	Data       string    `xml:"data"`
+	Done       *struct{} `xml:"done"`
+}
+
+// NewTestModularInput returns a harness for a new modular input called stanzaName.
+// The returned value can be configured as any ModularInput: register parameters, streaming and validation functions, etc.
+// It panics if stanzaName is empty.
+func NewTestModularInput(stanzaName string) *TestModularInput {
+	mi, err := modinputs.New(stanzaName, stanzaName, "")
+	if err != nil {
+		panic(fmt.Sprintf("testutil.NewTestModularInput: %s", err.Error()))
+	}
+	return &TestModularInput{ModularInput: mi}
+}
+
+// AddTestStanza adds a configuration stanza provided to the streaming function by RunStreaming.
+// If name does not contain a scheme, it is prefixed with '<stanzaName>://'.
+func (tmi *TestModularInput) AddTestStanza(name string, params map[string]string) *TestModularInput {
+	if !strings.Contains(name, "://") {
+		name = tmi.StanzaName + "://" + name
+	}
+	s := xmlStanza{Name: name}
+	names := make([]string, 0, len(params))
+	for n := range params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		s.Params = append(s.Params, xmlParam{Name: n, Value: params[n]})
+	}
+	tmi.stanzas = append(tmi.stanzas, s)
+	return tmi
+}
+
+// RunStreaming executes the registered streaming function on the test stanzas, as Splunk would do:
+// once for all stanzas in single-instance mode, once per stanza otherwise.
+// Events and logs generated by previous calls are discarded.
+func (tmi *TestModularInput) RunStreaming() error {
+	if len(tmi.stanzas) == 0 {
+		return utils.NewErrInvalidParam("runStreaming", nil, "no test stanzas have been added. Use AddTestStanza")
+	}
+	if tmi.CheckpointDir == "" {
+		dir, err := os.MkdirTemp("", "modinput-"+tmi.StanzaName+"-")
+		if err != nil {
+			return fmt.Errorf("runStreaming: %w", err)
+		}
+		tmi.CheckpointDir = dir
+	}
+	tmi.events, tmi.logs = nil, nil
+
+	batches := [][]xmlStanza{tmi.stanzas}
+	if !tmi.IsSingleInstance() {
+		batches = make([][]xmlStanza, len(tmi.stanzas))
+		for i, s := range tmi.stanzas {
+			batches[i] = []xmlStanza{s}
+		}
+	}
+	for _, stanzas := range batches {
+		conf, err := xml.Marshal(xmlInput{Hostname: "localhost", URI: "https://localhost:8089", CheckpointDir: tmi.CheckpointDir, Stanzas: stanzas})
+		if err != nil {
+			return fmt.Errorf("runStreaming: %w", err)
+		}
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		runErr := tmi.Run([]string{tmi.StanzaName}, bytes.NewReader(conf), stdout, stderr)
+		tmi.captureLogs(stderr)
+		if err := tmi.captureEvents(stdout); err != nil {
+			return fmt.Errorf("runStreaming: %w", err)
+		}
+		if runErr != nil {
+			return runErr
+		}
+	}
+	return nil
+}
+
+// captureLogs collects the plain-text log lines written on stderr
+func (tmi *TestModularInput) captureLogs(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			tmi.logs = append(tmi.logs, line)
+		}
+	}
+}
+
+// captureEvents parses the XML stream written on stdout. Internal logs, written as events to index=_internal, are collected as logs.
+func (tmi *TestModularInput) captureEvents(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	// the stream is a sequence of <event> elements, possibly surrounded by a custom header and footer
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("parsing of events failed: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "event" {
+			continue
+		}
+		xe := xmlEvent{}
+		if err := dec.DecodeElement(&xe, &start); err != nil {
+			return fmt.Errorf("parsing of events failed: %w", err)
+		}
+		if xe.Index == "_internal" && strings.HasPrefix(xe.SourceType, "modinput:") {
+			tmi.logs = append(tmi.logs, xe.Data)
+			continue
+		}
+		se := &modinputs.SplunkEvent{
+			Stanza:     xe.Stanza,
+			Unbroken:   xe.Unbroken == "1",
+			Done:       xe.Done != nil,
+			SourceType: xe.SourceType,
+			Index:      xe.Index,
+			Host:       xe.Host,
+			Source:     xe.Source,
+			Data:       xe.Data,
+		}
+		if xe.Time != "" {
+			epoch, err := strconv.ParseFloat(xe.Time, 64)
+			if err != nil {
+				return fmt.Errorf("parsing of events failed: invalid time '%s'. %w", xe.Time, err)
+			}
+			se.Time = utils.GetTimeFromEpoch(epoch)
+		}
+		tmi.events = append(tmi.events, se)
+	}
+}
+
+// CapturedEvents returns the events written by the streaming function during the last call to RunStreaming, excluding internal logs
+func (tmi *TestModularInput) CapturedEvents() []*modinputs.SplunkEvent {
+	return tmi.events
+}
+
+// CapturedLogs returns the log messages written during the last call to RunStreaming,
+// both as plain text on stderr and as events to index=_internal.
+func (tmi *TestModularInput) CapturedLogs() []string {
+	return tmi.logs
+}
+
+// AssertEventCount marks the test as failed if the last call to RunStreaming did not capture exactly expected events
+func (tmi *TestModularInput) AssertEventCount(t *testing.T, expected int) {
+	t.Helper()
+	if got := len(tmi.events); got != expected {
+		t.Errorf("modular input '%s' generated a wrong number of events. expected=%d got=%d", tmi.StanzaName, expected, got)
+	}
+}
@@ -0,0 +1,108 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prigio/splunk-go-sdk/modinputs"
+)
+
+// helloWorld emits 'count' events greeting the name configured within the stanza
+func helloWorld(mi *modinputs.ModularInput, stanza modinputs.Stanza) error {
+	count, err := stanza.ParamAsInt("count")
+	var notFound *modinputs.ErrParamNotFound
+	if errors.As(err, &notFound) {
+		count = 1
+	} else if err != nil {
+		return err
+	}
+	mi.Log("INFO", "Greeting name=%s count=%d", stanza.Param("name"), count)
+	for i := int64(0); i < count; i++ {
+		ev := mi.NewDefaultEvent(&stanza)
+		ev.Data = "Hello " + stanza.Param("name")
+		if err := mi.WriteToSplunk(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newHelloWorld() *TestModularInput {
+	tmi := NewTestModularInput("hello")
+	tmi.RegisterNewParam("name", "Name", "Who to greet", "world", modinputs.ArgDataTypeStr, "", false, false, false)
+	tmi.RegisterNewParam("count", "Count", "How many greetings", "1", modinputs.ArgDataTypeNumber, "", false, false, false)
+	tmi.RegisterStreamingFunc(helloWorld)
+	tmi.SetDefaultSourcetype("hello:world")
+	return tmi
+}
+
+func TestHelloWorld(t *testing.T) {
+	cases := []struct {
+		name     string
+		params   map[string]string
+		expected int
+		wantErr  bool
+	}{
+		{"defaults", map[string]string{"name": "world"}, 1, false},
+		{"many", map[string]string{"name": "gopher", "count": "3"}, 3, false},
+		{"none", map[string]string{"name": "nobody", "count": "0"}, 0, false},
+		{"invalid", map[string]string{"name": "gopher", "count": "three"}, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tmi := newHelloWorld()
+			tmi.CheckpointDir = t.TempDir()
+			err := tmi.AddTestStanza(c.name, c.params).RunStreaming()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("RunStreaming returned an unexpected error: %v", err)
+			}
+			tmi.AssertEventCount(t, c.expected)
+			for _, ev := range tmi.CapturedEvents() {
+				if ev.Data != "Hello "+c.params["name"] || ev.Stanza != "hello://"+c.name || ev.SourceType != "hello:world" || ev.Time.IsZero() {
+					t.Errorf("wrong event captured: %+v", ev)
+				}
+			}
+		})
+	}
+}
+
+func TestHelloWorldLogsAndStanzas(t *testing.T) {
+	tmi := newHelloWorld()
+	tmi.CheckpointDir = t.TempDir()
+	tmi.AddTestStanza("a", map[string]string{"name": "a", "count": "2"}).AddTestStanza("hello://b", map[string]string{"name": "b <&>"})
+
+	if err := tmi.RunStreaming(); err != nil {
+		t.Fatalf("RunStreaming returned an error: %s", err.Error())
+	}
+	// without single-instance mode, each stanza is processed by its own execution, as Splunk would do
+	tmi.AssertEventCount(t, 3)
+	if last := tmi.CapturedEvents()[2]; last.Stanza != "hello://b" || last.Data != "Hello b <&>" {
+		t.Errorf("the events of the second stanza were not captured correctly: %+v", last)
+	}
+	logs := strings.Join(tmi.CapturedLogs(), "\n")
+	for _, expected := range []string{"Greeting name=a count=2", "Greeting name=b <&> count=1", `status=succeeded for stanza="hello://b"`} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("log message '%s' was not captured. logs:\n%s", expected, logs)
+		}
+	}
+	if err := NewTestModularInput("empty").RunStreaming(); err == nil {
+		t.Error("RunStreaming did not return an error without test stanzas")
+	}
+}
+
+func ExampleTestModularInput() {
+	tmi := NewTestModularInput("hello")
+	tmi.RegisterStreamingFunc(helloWorld)
+	tmi.AddTestStanza("example", map[string]string{"name": "gopher", "count": "2"})
+	if err := tmi.RunStreaming(); err != nil {
+		fmt.Println(err)
+	}
+	for _, ev := range tmi.CapturedEvents() {
+		fmt.Println(ev.Stanza, ev.Data)
+	}
+	// Output:
+	// hello://example Hello gopher
+	// hello://example Hello gopher
+}
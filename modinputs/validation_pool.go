@@ -0,0 +1,60 @@
+package modinputs
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RegisterValidationFuncForAll registers a validation function to be executed on each of the stanzas provided
+// with the validation XML, instead of the first one only as done by RegisterValidationFunc.
+// Stanzas are validated concurrently, with at most as many executions of f running at the same time as configured
+// with SetMaxStanzaConcurrency. By default, all stanzas are validated at the same time.
+// The validation fails if any of the stanzas is not valid: the error lists the errors of each failed stanza.
+func (mi *ModularInput) RegisterValidationFuncForAll(f ValidationFunc) {
+	mi.RegisterValidationFunc(f)
+	mi.validateAll = true
+}
+
+// ValidateAll executes the registered validation function on all stanzas, logging the outcome of each of them.
+// It returns the errors of the single stanzas, having the same order as stanzas and being nil for valid ones,
+// along with a single error aggregating them, nil if all the stanzas are valid.
+// The validation function can use mi.Log from the concurrent executions: the log output is serialized.
+func (mi *ModularInput) ValidateAll(stanzas []Stanza) ([]error, error) {
+	if mi.validate == nil {
+		return nil, fmt.Errorf("validateAll: no validation function was registered")
+	}
+	maxConcurrency := mi.maxStanzaConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = len(stanzas)
+	}
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(stanzas))
+		sem  = make(chan struct{}, maxConcurrency)
+	)
+	for i, stanza := range stanzas {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, stanza Stanza) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// each goroutine writes its own item of errs, no lock is needed
+			errs[i] = mi.validateStanza(stanza)
+		}(i, stanza)
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("stanza %s: %w", stanzas[i].Name, err))
+		}
+	}
+	if len(failed) == 0 {
+		return errs, nil
+	}
+	return errs, fmt.Errorf("validation failed for %d of %d stanzas:\n%w", len(failed), len(stanzas), errors.Join(failed...))
+}
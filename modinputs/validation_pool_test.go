@@ -0,0 +1,71 @@
+package modinputs
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterValidationFuncForAll(t *testing.T) {
+	mi, _ := New("testvalidation", "Test validation", "")
+	var mu sync.Mutex
+	validated := map[string]bool{}
+	mi.RegisterValidationFuncForAll(func(mi *ModularInput, s Stanza) error {
+		mu.Lock()
+		validated[s.Name] = true
+		mu.Unlock()
+		// logging concurrently must be safe, even if stderr is not
+		mi.Log("INFO", `validating stanza="%s"`, s.Name)
+		if s.Param("port") == "" {
+			return errors.New("'port' cannot be empty")
+		}
+		return nil
+	})
+
+	validation := `<items>
+  <server_host>myHost</server_host>
+  <item name="tenant_a"><param name="port">8080</param></item>
+  <item name="tenant_b"><param name="port"></param></item>
+  <item name="tenant_c"><param name="port">9090</param></item>
+  <item name="tenant_d"></item>
+</items>`
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	err := mi.Run([]string{"testvalidation", "--validate-arguments"}, strings.NewReader(validation), stdout, stderr)
+	if err == nil {
+		t.Fatal("validation of invalid stanzas did not fail")
+	}
+	if len(validated) != 4 {
+		t.Errorf("not all the stanzas were validated: %v", validated)
+	}
+	for _, expected := range []string{"validation failed for 2 of 4 stanzas", "stanza tenant_b: 'port' cannot be empty", "stanza tenant_d: 'port' cannot be empty"} {
+		if !strings.Contains(err.Error(), expected) || !strings.Contains(stdout.String(), expected) {
+			t.Errorf("validation error does not contain '%s'. error=%s stdout=%s", expected, err.Error(), stdout.String())
+		}
+	}
+	if strings.Contains(err.Error(), "tenant_a") || strings.Contains(err.Error(), "tenant_c") {
+		t.Errorf("validation error reports valid stanzas: %s", err.Error())
+	}
+	logs := stderr.String()
+	if strings.Count(logs, "validating stanza=") != 4 || strings.Count(logs, "status=succeeded") != 2 || strings.Count(logs, "status=failed") != 2 {
+		t.Errorf("the outcome of each stanza was not logged:\n%s", logs)
+	}
+
+	// per-stanza errors are returned in the order of the stanzas
+	mi.SetMaxStanzaConcurrency(1)
+	errs, err := mi.ValidateAll([]Stanza{{Name: "x"}, {Name: "y", Params: []Param{{Name: "port", Value: "1"}}}})
+	if err == nil || len(errs) != 2 || errs[0] == nil || errs[1] != nil {
+		t.Errorf("wrong per-stanza errors returned: %v err=%v", errs, err)
+	}
+
+	// RegisterValidationFunc only validates the first stanza
+	validated = map[string]bool{}
+	mi.RegisterValidationFunc(mi.validate)
+	stdout.Reset()
+	if err := mi.Run([]string{"testvalidation", "--validate-arguments"}, strings.NewReader(validation), stdout, stderr); err != nil {
+		t.Errorf("validation of the first stanza failed: %s", err.Error())
+	}
+	if len(validated) != 1 || !validated["tenant_a"] {
+		t.Errorf("RegisterValidationFunc validated the wrong stanzas: %v", validated)
+	}
+}
@@ -1,5 +1,12 @@
 package splunkd
 
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
 // This file provides structs used to parse the JSON-formatted output of the Splunk REST API
 
 // See: https://docs.splunk.com/Documentation/Splunk/8.1.3/RESTREF/RESTprolog
@@ -8,7 +15,7 @@ type AppResource struct {
 	Label                      string `json:"label"`
 	Description                string `json:"description"`
 	Author                     string `json:"author"`
-	Version                    string `json:"username"`
+	Version                    string `json:"version"`
 	Configured                 bool   `json:"configured"`
 	Core                       bool   `json:"core"`
 	StateChangeRequiresRestart bool   `json:"state_change_requires_restart"`
@@ -19,6 +26,61 @@ type AppResource struct {
 	CheckForUpdates            bool   `json:"check_for_updates"`
 }
 
+// AppsCollection provides access to the apps installed on splunkd.
+// Exists can be used to verify that apps needed by an alert action or modular input are installed.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTapps#apps.2Flocal
 type AppsCollection struct {
 	collection[AppResource]
 }
+
+func NewAppsCollection(ss *Client) *AppsCollection {
+	var col = &AppsCollection{}
+	col.name = "apps"
+	col.path = "apps/local"
+	col.splunkd = ss
+	return col
+}
+
+// Install installs, or upgrades, the app packaged within filepath.
+// filepath is the path of the app package (.tgz, .spl) on the file system of the splunkd server, not of the client.
+func (col *AppsCollection) Install(filepath string) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("install: %w", err)
+	}
+	if filepath == "" {
+		return utils.NewErrInvalidParam(col.name+" install", nil, "'filepath' cannot be empty")
+	}
+	params := url.Values{}
+	params.Set("name", filepath)
+	params.Set("filename", "true")
+	params.Set("update", "true")
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", getUrl(col.path, ""), nil, []byte(params.Encode()), "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s install '%s': %w", col.name, filepath, err)
+	}
+	return nil
+}
+
+// Enable enables the app called name
+func (col *AppsCollection) Enable(name string) error {
+	return col.postAction(name, "enable")
+}
+
+// Disable disables the app called name
+func (col *AppsCollection) Disable(name string) error {
+	return col.postAction(name, "disable")
+}
+
+// postAction invokes one of the action endpoints available for apps, such as /enable or /disable
+func (col *AppsCollection) postAction(name, action string) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" "+action, nil, "'name' cannot be empty")
+	}
+	fullUrl := getUrl(col.path, name) + "/" + action
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s %s '%s': %w", col.name, action, name, err)
+	}
+	return nil
+}
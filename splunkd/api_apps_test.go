@@ -0,0 +1,79 @@
+package splunkd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAppsList(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+
+	apps := ss.GetApps()
+	entries, err := apps.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.Name == "search" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("app 'search' not returned by AppsCollection.List. got %d apps", len(entries))
+	}
+	if !apps.Exists("search") {
+		t.Error("AppsCollection.Exists returned false for app 'search'")
+	}
+	if apps.Exists("this-app-does-not-exist") {
+		t.Error("AppsCollection.Exists returned true for a non-existent app")
+	}
+}
+
+func TestAppsActions(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+form.Encode())
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET":
+			fmt.Fprint(w, `{"entry":[{"name":"myapp","content":{"label":"My App","version":"1.2.3","author":"me","visible":true,"disabled":false,"configured":true}}]}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	apps := ss.GetApps()
+
+	e, err := apps.Get("myapp")
+	if err != nil || e.Content.Version != "1.2.3" || e.Content.Label != "My App" || !e.Content.Visible || !e.Content.Configured {
+		t.Errorf("AppsCollection.Get returned wrong results. entry=%+v err=%v", e, err)
+	}
+	if !apps.Exists("myapp") || apps.Exists("missing") {
+		t.Error("AppsCollection.Exists returned wrong results")
+	}
+
+	requests = nil
+	apps.Install("/tmp/myapp.tgz")
+	apps.Disable("myapp")
+	apps.Enable("myapp")
+	apps.Update("myapp", &url.Values{"visible": {"false"}})
+	expected := []string{
+		"POST /services/apps/local?filename=true&name=%2Ftmp%2Fmyapp.tgz&update=true",
+		"POST /services/apps/local/myapp/disable?",
+		"POST /services/apps/local/myapp/enable?",
+		"POST /services/apps/local/myapp?visible=false",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("AppsCollection sent wrong requests.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(requests, "\n"))
+	}
+}
@@ -25,8 +25,11 @@ type ContextResource struct {
 // Info retrieves generic information about the Splunk instance the client is connected to
 // It caches such information locally, as this is not something which regularly varies
 func (ss *Client) AuthContext() (*ContextResource, error) {
-	if ss.authContext != nil {
-		return ss.authContext, nil
+	ss.authMu.RLock()
+	authContext := ss.authContext
+	ss.authMu.RUnlock()
+	if authContext != nil {
+		return authContext, nil
 	}
 
 	col := collection[ContextResource]{
@@ -35,14 +38,21 @@ func (ss *Client) AuthContext() (*ContextResource, error) {
 	}
 
 	// pathInfo represents this enpoint https://docs.splunk.com/Documentation/Splunk/8.1.3/RESTREF/RESTintrospect#server.2Finfo
-	if err := doSplunkdHttpRequest(ss, "GET", "/services/authentication/current-context", nil, nil, "", &col); err != nil {
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", "/services/authentication/current-context", nil, nil, "", &col); err != nil {
 		return nil, fmt.Errorf("auth-context list: %w", err)
 	}
 
-	ss.authContext = &col.Entries[0].Content
-	ss.authContext.LastLogin = time.Unix(ss.authContext.LastLoginEpoch, 0)
-
-	return ss.authContext, nil
+	if len(col.Entries) == 0 {
+		return nil, fmt.Errorf("auth-context list: no entry returned")
+	}
+	authContext = &col.Entries[0].Content
+	authContext.LastLogin = time.Unix(authContext.LastLoginEpoch, 0)
+
+	// the request is performed without holding the lock, as authenticating it requires authMu as well
+	ss.authMu.Lock()
+	ss.authContext = authContext
+	ss.authMu.Unlock()
+	return authContext, nil
 }
 
 // Can checks whether the logged-in user has the specified capability
@@ -87,7 +97,16 @@ func (ss *Client) Has(role string) (bool, error) {
 	return false, nil
 }
 
+// Username returns the name of the logged-in user.
+// The username provided to Login is returned directly; when the session was established with LoginWithToken
+// or LoginWithSessionKey, the username is retrieved through AuthContext.
 func (ss *Client) Username() (string, error) {
+	ss.authMu.RLock()
+	username := ss.username
+	ss.authMu.RUnlock()
+	if username != "" {
+		return username, nil
+	}
 	var cr *ContextResource
 	var err error
 
@@ -1,6 +1,10 @@
 package splunkd
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -24,3 +28,95 @@ func TestContext(t *testing.T) {
 		t.Errorf("Invalid Context value provided. %+v", cr)
 	}
 }
+
+func TestUsernameFromLogin(t *testing.T) {
+	var contextCalls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pathLogin {
+			fmt.Fprint(w, `{"sessionKey":"abc"}`)
+			return
+		}
+		contextCalls++
+		fmt.Fprint(w, `{"entry":[{"name":"tokenuser","content":{"username":"tokenuser"}}]}`)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	if err := ss.Login("admin", "secret", ""); err != nil {
+		t.Fatal(err)
+	}
+	contextCalls = 0
+	if u, err := ss.Username(); err != nil || u != "admin" || contextCalls != 0 {
+		t.Errorf("Username did not return the login username without API calls. username=%s calls=%d err=%v", u, contextCalls, err)
+	}
+
+	ss = &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	if err := ss.LoginWithToken("token"); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := ss.Username(); err != nil || u != "tokenuser" {
+		t.Errorf("Username did not fall back to AuthContext. username=%s err=%v", u, err)
+	}
+}
+
+func TestLoginWithTokenAuthMethod(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == pathLogin {
+			fmt.Fprint(w, `{"sessionKey":"abc"}`)
+			return
+		}
+		if r.URL.Path != "/services/authentication/current-context" || r.Header.Get("Authorization") != "Bearer mytoken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"entry":[{"name":"svcaccount","content":{"username":"svcaccount"}}]}`)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	if ss.AuthMethod() != "unauthenticated" {
+		t.Errorf("AuthMethod of a new client. expected=unauthenticated got=%s", ss.AuthMethod())
+	}
+	ss.Login("admin", "secret", "")
+	if ss.AuthMethod() != "session_key" {
+		t.Errorf("AuthMethod after Login. expected=session_key got=%s", ss.AuthMethod())
+	}
+	// the token replaces the session key obtained by Login
+	if err := ss.LoginWithToken("mytoken"); err != nil {
+		t.Fatalf("LoginWithToken returned an error: %s", err.Error())
+	}
+	if ss.AuthMethod() != "token" {
+		t.Errorf("AuthMethod after LoginWithToken. expected=token got=%s", ss.AuthMethod())
+	}
+	if cr, err := ss.AuthContext(); err != nil || cr.Username != "svcaccount" {
+		t.Errorf("token-authenticated client cannot access current-context. context=%+v err=%v", cr, err)
+	}
+}
+
+func TestAuthContextConcurrent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"entry":[{"name":"context","content":{"username":"tokenuser","roles":["user"]}}]}`)
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	if err := ss.LoginWithToken("token"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if u, err := ss.Username(); err != nil || u != "tokenuser" {
+				t.Errorf("Username returned wrong results. username=%s err=%v", u, err)
+			}
+			if has, err := ss.Has("user"); err != nil || !has {
+				t.Errorf("Has returned wrong results. has=%t err=%v", has, err)
+			}
+			// authenticating again resets the cached context
+			ss.LoginWithSessionKey("abc")
+		}()
+	}
+	wg.Wait()
+}
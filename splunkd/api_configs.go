@@ -3,8 +3,11 @@ package splunkd
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
 // This file provides structs used to parse the JSON-formatted output of the Splunk REST API
@@ -75,6 +78,19 @@ func (cr *ConfigResource) GetFloat(key string) (val float32, err error) {
 	}
 }
 
+// properties returns the settings of the stanza as strings.
+// Internal properties provided by the API, such as 'eai:acl', are not included.
+func (cr *ConfigResource) properties() map[string]string {
+	props := make(map[string]string)
+	for k := range *cr {
+		if strings.HasPrefix(k, "eai:") {
+			continue
+		}
+		props[k], _ = cr.GetString(k)
+	}
+	return props
+}
+
 // ConfigsCollection represents a generic configuration file as managed by the /services/configs/conf-<confFileName> endpoint.
 // You can manage config file stanzas through this endpoint.
 // This also supports custom configuration files defined with a custom SPEC file within etc/apps/<someapp>/README/<somefile>.conf.spec.
@@ -104,7 +120,7 @@ func NewConfigsCollectionNS(ss *Client, configFileName, owner, app string) *Conf
 	ns, _ := NewNamespace(owner, app, SplunkSharingApp)
 
 	col.name = "conf-" + configFileName
-	col.path = ns.GetServicesNSUrl() + "configs/conf-" + configFileName
+	col.path = getUrlNS(ns, "configs/conf-"+configFileName)
 	col.splunkd = ss
 	return col
 }
@@ -152,3 +168,93 @@ func (col *ConfigsCollection) GetConfigAsFloat(stanza, configName string) (float
 	}
 	return stanzaConf.GetFloat(configName)
 }
+
+// CopyStanza creates stanza dstStanza having the same properties as srcStanza.
+// Internal properties provided by the API, such as 'eai:acl', are not copied.
+// The destination is not created if the source cannot be read.
+func (col *ConfigsCollection) CopyStanza(srcStanza, dstStanza string) error {
+	if srcStanza == "" || dstStanza == "" {
+		return utils.NewErrInvalidParam(col.name+" copyStanza", nil, "'srcStanza' and 'dstStanza' cannot be empty")
+	}
+	src, err := col.GetStanza(srcStanza)
+	if err != nil {
+		return fmt.Errorf("%s copyStanza: cannot read source '%s'. %w", col.name, srcStanza, err)
+	}
+	params := url.Values{}
+	for k, v := range src.properties() {
+		params.Set(k, v)
+	}
+	if len(params) == 0 {
+		// CreateStanza requires at least one parameter
+		params.Set("disabled", "0")
+	}
+	if _, err = col.CreateStanza(dstStanza, &params); err != nil {
+		return fmt.Errorf("%s copyStanza: cannot create destination '%s'. %w", col.name, dstStanza, err)
+	}
+	return nil
+}
+
+// MoveStanza renames stanza src into dst, by copying it with CopyStanza and deleting src upon success.
+func (col *ConfigsCollection) MoveStanza(src, dst string) error {
+	if err := col.CopyStanza(src, dst); err != nil {
+		return fmt.Errorf("%s moveStanza: %w", col.name, err)
+	}
+	if err := col.Delete(src); err != nil {
+		return fmt.Errorf("%s moveStanza: '%s' was copied to '%s' but could not be deleted. %w", col.name, src, dst, err)
+	}
+	return nil
+}
+
+// Export returns all the stanzas of the configuration file, as a map of stanza names to their properties.
+// Internal properties provided by the API, such as 'eai:acl', are not exported.
+// The result can be provided to Import in order to restore the stanzas.
+func (col *ConfigsCollection) Export() (map[string]map[string]string, error) {
+	data := make(map[string]map[string]string)
+	err := col.forEach(url.Values{}, func(e *entry[ConfigResource]) error {
+		data[e.Name] = e.Content.properties()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s export: %w", col.name, err)
+	}
+	return data, nil
+}
+
+// Import creates the stanzas listed within data, as returned by Export.
+// Stanzas which already exist are updated if overwrite is true, and left untouched otherwise.
+// Stanzas are applied in the order of their names, and Import stops at the first stanza which cannot be created or updated.
+func (col *ConfigsCollection) Import(data map[string]map[string]string, overwrite bool) error {
+	// sorting the names makes the outcome of a failed import predictable
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		props := data[name]
+		if name == "" {
+			return utils.NewErrInvalidParam(col.name+" import", nil, "stanza names cannot be empty")
+		}
+		params := url.Values{}
+		for k, v := range props {
+			params.Set(k, v)
+		}
+		if col.Exists(name) {
+			if !overwrite || len(params) == 0 {
+				continue
+			}
+			if err := col.Update(name, &params); err != nil {
+				return fmt.Errorf("%s import: cannot update '%s'. %w", col.name, name, err)
+			}
+			continue
+		}
+		if len(params) == 0 {
+			// CreateStanza requires at least one parameter
+			params.Set("disabled", "0")
+		}
+		if _, err := col.CreateStanza(name, &params); err != nil {
+			return fmt.Errorf("%s import: cannot create '%s'. %w", col.name, name, err)
+		}
+	}
+	return nil
+}
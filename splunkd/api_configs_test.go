@@ -1,7 +1,12 @@
 package splunkd
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -286,3 +291,103 @@ func TestConfigCustom(t *testing.T) {
 	}
 }
 */
+
+func TestConfigsNSPath(t *testing.T) {
+	col := NewConfigsCollectionNS(nil, "props.conf", "admin", "search")
+	expected := "/servicesNS/admin/search/configs/conf-props"
+	if col.path != expected {
+		t.Errorf("NewConfigsCollectionNS built a wrong path. expected='%s' got='%s'", expected, col.path)
+	}
+	// a path already in a namespace must get its namespace replaced, not doubled
+	ns, _ := NewNamespace("nobody", "myapp", SplunkSharingApp)
+	expected = "/servicesNS/nobody/myapp/configs/conf-props"
+	if got := getUrlNS(ns, col.path); got != expected {
+		t.Errorf("getUrlNS built a wrong path. expected='%s' got='%s'", expected, got)
+	}
+}
+
+func TestConfigsMoveStanza(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+	src := "sourcetype-" + uuid.New().String()[0:5]
+	dst := src + "-moved"
+	propsCol := NewConfigsCollection(ss, "props")
+
+	params := url.Values{}
+	params.Set("SHOULD_LINEMERGE", "false")
+	if _, err := propsCol.CreateStanza(src, &params); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err := propsCol.MoveStanza(src, dst); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer propsCol.Delete(dst)
+	if propsCol.Exists(src) {
+		t.Errorf("MoveStanza did not delete source stanza '%s'", src)
+	}
+	if v, err := propsCol.GetConfigAsString(dst, "SHOULD_LINEMERGE"); err != nil || v != "false" {
+		t.Errorf("MoveStanza did not copy properties to '%s'. SHOULD_LINEMERGE='%s' err=%v", dst, v, err)
+	}
+}
+
+func TestConfigsExportImport(t *testing.T) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/conf-test"):
+			fmt.Fprint(w, `{"paging":{"total":2,"offset":0},"entry":[
+				{"name":"existing","content":{"key":"value","eai:acl":{"app":"search"}}},
+				{"name":"other","content":{"count":3}}]}`)
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/existing"):
+			fmt.Fprint(w, `{"entry":[{"name":"existing"}]}`)
+		case r.Method == "GET":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "POST":
+			// Create and Update do not set a content-type, so the body is parsed explicitly
+			body, _ := io.ReadAll(r.Body)
+			form, _ := url.ParseQuery(string(body))
+			posted = append(posted, r.URL.Path+"?"+form.Encode())
+			fmt.Fprint(w, `{"entry":[{"name":"created"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewConfigsCollection(ss, "test")
+	data, err := col.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 2 || data["existing"]["key"] != "value" || data["other"]["count"] != "3" {
+		t.Errorf("Export returned wrong data: %v", data)
+	}
+	if _, found := data["existing"]["eai:acl"]; found {
+		t.Errorf("Export returned internal properties: %v", data)
+	}
+
+	if err = col.Import(map[string]map[string]string{"existing": {"key": "new"}, "new": {"key": "value"}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || !strings.HasSuffix(posted[0], "/conf-test?key=value&name=new") {
+		t.Errorf("Import without overwrite sent wrong requests: %v", posted)
+	}
+	posted = nil
+	if err = col.Import(map[string]map[string]string{"existing": {"key": "new"}}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(posted) != 1 || !strings.HasSuffix(posted[0], "/conf-test/existing?key=new") {
+		t.Errorf("Import with overwrite sent wrong requests: %v", posted)
+	}
+	// stanzas are applied in the order of their names
+	posted = nil
+	if err = col.Import(map[string]map[string]string{"c": {"k": "v"}, "a": {"k": "v"}, "d": {"k": "v"}, "b": {"k": "v"}}, false); err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"a", "b", "c", "d"} {
+		if len(posted) != 4 || !strings.HasSuffix(posted[i], "name="+name) {
+			t.Errorf("Import did not apply the stanzas in the order of their names: %v", posted)
+			break
+		}
+	}
+}
@@ -1,7 +1,13 @@
 package splunkd
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
 // This file provides structs used to parse the JSON-formatted output of the Splunk REST API
@@ -65,3 +71,130 @@ func (col *CredentialsCollection) UpdateCredACL(user, realm string, acl AccessCo
 	entryId := urlEncodeCredential(user, realm)
 	return col.UpdateACL(entryId, acl)
 }
+
+// ForEachCredential invokes fn on every stored credential, retrieving them from splunkd one page at a time.
+// This avoids loading all credentials in memory, which is relevant for large credential stores.
+// Iteration stops at the first error returned by fn, which is then returned.
+func (col *CredentialsCollection) ForEachCredential(fn func(*entry[CredentialResource]) error) error {
+	return col.forEach(url.Values{}, fn)
+}
+
+// ListByRealm returns the credentials stored within the given realm.
+// Filtering is performed by splunkd through the 'search' parameter. Since splunkd also returns partial matches,
+// results are additionally filtered on the client side to only keep the exact realm.
+func (col *CredentialsCollection) ListByRealm(realm string) ([]*entry[CredentialResource], error) {
+	searchParams := url.Values{}
+	if realm != "" {
+		searchParams.Set("search", "realm="+realm)
+	}
+	creds := make([]*entry[CredentialResource], 0)
+	err := col.forEach(searchParams, func(e *entry[CredentialResource]) error {
+		if e.Content.Realm == realm {
+			creds = append(creds, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s listByRealm: %w", col.name, err)
+	}
+	return creds, nil
+}
+
+// RotatePassword replaces the password of a credential with newPassword, and verifies that splunkd stores it correctly.
+// If the verification fails, the previous password is restored and an error is returned.
+// If the credential already has newPassword, nothing is modified.
+func (col *CredentialsCollection) RotatePassword(user, realm, newPassword string) error {
+	if newPassword == "" {
+		return utils.NewErrInvalidParam(col.name+" rotatePassword", nil, "'newPassword' cannot be empty")
+	}
+	current, err := col.GetCred(user, realm)
+	if err != nil {
+		return fmt.Errorf("%s rotatePassword: %w", col.name, err)
+	}
+	oldPassword := current.Content.ClearPassword
+	if oldPassword == newPassword {
+		return nil
+	}
+	if err = col.UpdateCred(user, realm, newPassword); err != nil {
+		return fmt.Errorf("%s rotatePassword: %w", col.name, err)
+	}
+
+	verified, err := col.GetCred(user, realm)
+	if err == nil && verified.Content.ClearPassword == newPassword {
+		return nil
+	}
+	if err == nil {
+		err = errors.New("stored password does not match the new one")
+	}
+	if rollbackErr := col.UpdateCred(user, realm, oldPassword); rollbackErr != nil {
+		return fmt.Errorf("%s rotatePassword: verification of '%s' failed and the previous password could not be restored: %w", col.name, urlEncodeCredential(user, realm), errors.Join(err, rollbackErr))
+	}
+	return fmt.Errorf("%s rotatePassword: verification of '%s' failed, the previous password has been restored: %w", col.name, urlEncodeCredential(user, realm), err)
+}
+
+// EnsureCredExists creates a credential having password if it does not exist, or updates its password otherwise.
+// Calling it multiple times with the same arguments has the same effect as calling it once.
+func (col *CredentialsCollection) EnsureCredExists(user, realm, password string) error {
+	current, err := col.GetCred(user, realm)
+	if isNotFoundError(err) {
+		if _, err = col.CreateCred(user, realm, password); err != nil {
+			return fmt.Errorf("%s ensureCredExists: %w", col.name, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s ensureCredExists: %w", col.name, err)
+	}
+	if current.Content.ClearPassword == password {
+		return nil
+	}
+	if err = col.UpdateCred(user, realm, password); err != nil {
+		return fmt.Errorf("%s ensureCredExists: %w", col.name, err)
+	}
+	return nil
+}
+
+// CredentialWatcher reads the password of a credential every interval, and calls onChange with the new password whenever it changes.
+// This allows long-running processes to pick-up secrets rotated by other parties.
+// The credential must exist when starting the watcher. Errors raised while polling are ignored, and polling continues.
+// Polling goes on until stop is called or the context of the client is cancelled.
+func (col *CredentialsCollection) CredentialWatcher(interval time.Duration, user, realm string, onChange func(newPassword string)) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, utils.NewErrInvalidParam(col.name+" credentialWatcher", nil, "'interval' must be > 0")
+	}
+	if onChange == nil {
+		return nil, utils.NewErrInvalidParam(col.name+" credentialWatcher", nil, "'onChange' cannot be nil")
+	}
+	current, err := col.GetCred(user, realm)
+	if err != nil {
+		return nil, fmt.Errorf("%s credentialWatcher: %w", col.name, err)
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	go func(lastPassword string) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-col.splunkd.getContext().Done():
+				return
+			case <-ticker.C:
+			}
+			cred, err := col.GetCred(user, realm)
+			if err != nil || cred.Content.ClearPassword == lastPassword {
+				continue
+			}
+			select {
+			case <-done:
+				// do not notify changes after stop has been called
+				return
+			default:
+			}
+			lastPassword = cred.Content.ClearPassword
+			onChange(lastPassword)
+		}
+	}(current.Content.ClearPassword)
+	return func() { once.Do(func() { close(done) }) }, nil
+}
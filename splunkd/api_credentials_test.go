@@ -1,7 +1,15 @@
 package splunkd
 
 import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -195,3 +203,191 @@ func TestCredentialACL(t *testing.T) {
 		t.Errorf("Deletion of credential failed: %s", err.Error())
 	}
 }
+
+func TestCredentialForEach(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+
+	credentials := ss.GetCredentials()
+	all, err := credentials.List()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	cnt := 0
+	if err = credentials.ForEachCredential(func(e *entry[CredentialResource]) error {
+		cnt++
+		return nil
+	}); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if cnt != len(all) {
+		t.Errorf("ForEachCredential did not iterate over all credentials. expected=%d got=%d", len(all), cnt)
+	}
+}
+
+func TestCredentialListByRealm(t *testing.T) {
+	var gotSearch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSearch = r.URL.Query().Get("search")
+		// splunkd also returns partial matches of the realm
+		fmt.Fprint(w, `{"paging":{"total":2,"offset":0},"entry":[
+			{"name":"myrealm:user1:","content":{"realm":"myrealm","username":"user1"}},
+			{"name":"myrealm2:user2:","content":{"realm":"myrealm2","username":"user2"}}]}`)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	creds, err := NewCredentialsCollection(ss).ListByRealm("myrealm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSearch != "realm=myrealm" {
+		t.Errorf("ListByRealm did not filter through the API. search='%s'", gotSearch)
+	}
+	if len(creds) != 1 || creds[0].Content.Username != "user1" {
+		t.Errorf("ListByRealm returned wrong credentials: %+v", creds)
+	}
+}
+
+// fakePasswordStore emulates the storage/passwords endpoint of splunkd
+type fakePasswordStore struct {
+	mu        sync.Mutex
+	passwords map[string]string
+	updates   int
+	// if set, updates store this value instead of the provided password
+	corruptWith string
+}
+
+func (fs *fakePasswordStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	// the SDK does not set a content-type, so the form must be parsed explicitly
+	body, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+	id := strings.TrimPrefix(r.URL.Path, "/services/storage/passwords")
+	id = strings.TrimPrefix(id, "/")
+	if id == "" && r.Method == "POST" {
+		id = form.Get("realm") + ":" + form.Get("name") + ":"
+		if _, found := fs.passwords[id]; found {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		fs.passwords[id] = form.Get("password")
+	} else if _, found := fs.passwords[id]; !found {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"messages":[{"type":"ERROR","text":"Could not find object id=%s"}]}`, id)
+		return
+	} else if r.Method == "POST" {
+		fs.updates++
+		fs.passwords[id] = form.Get("password")
+		if fs.corruptWith != "" && form.Get("password") != "old-secret" {
+			fs.passwords[id] = fs.corruptWith
+		}
+	}
+	parts := strings.Split(id, ":")
+	fmt.Fprintf(w, `{"entry":[{"name":"%s","content":{"realm":"%s","username":"%s","clear_password":"%s"}}]}`, id, parts[0], parts[1], fs.passwords[id])
+}
+
+func (fs *fakePasswordStore) get(id string) string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.passwords[id]
+}
+
+func TestCredentialRotatePassword(t *testing.T) {
+	fs := &fakePasswordStore{passwords: map[string]string{"myrealm:svc:": "old-secret"}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	col := NewCredentialsCollection(&Client{baseUrl: srv.URL, httpClient: srv.Client()})
+
+	if err := col.RotatePassword("svc", "myrealm", "new-secret"); err != nil {
+		t.Fatalf("RotatePassword failed: %s", err.Error())
+	}
+	if fs.get("myrealm:svc:") != "new-secret" || fs.updates != 1 {
+		t.Errorf("password was not rotated. stored='%s' updates=%d", fs.get("myrealm:svc:"), fs.updates)
+	}
+	// rotating again to the same password does not perform any update
+	if err := col.RotatePassword("svc", "myrealm", "new-secret"); err != nil || fs.updates != 1 {
+		t.Errorf("RotatePassword is not idempotent. err=%v updates=%d", err, fs.updates)
+	}
+	if err := col.RotatePassword("svc", "myrealm", ""); err == nil {
+		t.Error("RotatePassword accepted an empty password")
+	}
+	if err := col.RotatePassword("missing", "myrealm", "x"); !isNotFoundError(err) {
+		t.Errorf("RotatePassword of a missing credential did not return a not-found error: %v", err)
+	}
+
+	// the stored value does not match: the previous password is restored
+	fs.passwords["myrealm:svc:"] = "old-secret"
+	fs.corruptWith = "garbled"
+	err := col.RotatePassword("svc", "myrealm", "newer-secret")
+	if err == nil || !strings.Contains(err.Error(), "previous password has been restored") {
+		t.Errorf("RotatePassword did not report the failed verification: %v", err)
+	}
+	if fs.get("myrealm:svc:") != "old-secret" {
+		t.Errorf("previous password was not restored. stored='%s'", fs.get("myrealm:svc:"))
+	}
+}
+
+func TestCredentialEnsureCredExists(t *testing.T) {
+	fs := &fakePasswordStore{passwords: map[string]string{}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	col := NewCredentialsCollection(&Client{baseUrl: srv.URL, httpClient: srv.Client()})
+
+	for i := 0; i < 2; i++ {
+		if err := col.EnsureCredExists("svc", "", "secret"); err != nil {
+			t.Fatalf("EnsureCredExists failed at call %d: %s", i+1, err.Error())
+		}
+	}
+	if fs.get(":svc:") != "secret" || fs.updates != 0 {
+		t.Errorf("credential was not created once. stored='%s' updates=%d", fs.get(":svc:"), fs.updates)
+	}
+	if err := col.EnsureCredExists("svc", "", "changed"); err != nil || fs.get(":svc:") != "changed" || fs.updates != 1 {
+		t.Errorf("existing credential was not updated. err=%v stored='%s' updates=%d", err, fs.get(":svc:"), fs.updates)
+	}
+}
+
+func TestCredentialWatcher(t *testing.T) {
+	fs := &fakePasswordStore{passwords: map[string]string{"myrealm:svc:": "v1"}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	col := NewCredentialsCollection(&Client{baseUrl: srv.URL, httpClient: srv.Client()})
+
+	if _, err := col.CredentialWatcher(time.Millisecond, "missing", "myrealm", func(string) {}); err == nil {
+		t.Error("CredentialWatcher started on a missing credential")
+	}
+	if _, err := col.CredentialWatcher(0, "svc", "myrealm", func(string) {}); err == nil {
+		t.Error("CredentialWatcher accepted a zero interval")
+	}
+
+	changes := make(chan string, 10)
+	stop, err := col.CredentialWatcher(5*time.Millisecond, "svc", "myrealm", func(p string) { changes <- p })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	fs.mu.Lock()
+	fs.passwords["myrealm:svc:"] = "v2"
+	fs.mu.Unlock()
+	select {
+	case p := <-changes:
+		if p != "v2" {
+			t.Errorf("onChange received a wrong password: '%s'", p)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("onChange was not called after the password changed")
+	}
+	stop()
+	stop()
+	fs.mu.Lock()
+	fs.passwords["myrealm:svc:"] = "v3"
+	fs.mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	select {
+	case p := <-changes:
+		t.Errorf("onChange was called after stop with '%s', or for an unchanged password", p)
+	default:
+	}
+}
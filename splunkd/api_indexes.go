@@ -0,0 +1,69 @@
+package splunkd
+
+import (
+	"fmt"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// IndexResource represents the most commonly used settings and statistics of an index.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTintrospect#data.2Findexes
+type IndexResource struct {
+	// DataType is either "event" or "metric"
+	DataType               string `json:"datatype"`
+	MaxTotalDataSizeMB     int64  `json:"maxTotalDataSizeMB"`
+	FrozenTimePeriodInSecs int64  `json:"frozenTimePeriodInSecs"`
+	TotalEventCount        int64  `json:"totalEventCount"`
+	CurrentDBSizeMB        int64  `json:"currentDBSizeMB"`
+	// MinTime and MaxTime are the timestamps of the oldest and newest events of the index. Empty if the index has no events
+	MinTime  string `json:"minTime"`
+	MaxTime  string `json:"maxTime"`
+	Disabled bool   `json:"disabled"`
+}
+
+type IndexesCollection struct {
+	collection[IndexResource]
+}
+
+func NewIndexesCollection(ss *Client) *IndexesCollection {
+	var col = &IndexesCollection{}
+	col.name = "indexes"
+	col.path = "data/indexes"
+	col.splunkd = ss
+	return col
+}
+
+// Enable enables the index called name
+func (col *IndexesCollection) Enable(name string) error {
+	return col.postAction(name, "enable")
+}
+
+// Disable disables the index called name
+func (col *IndexesCollection) Disable(name string) error {
+	return col.postAction(name, "disable")
+}
+
+// Clean would remove all the events of the index called name.
+// splunkd does not provide a REST endpoint to clean the data of an index: this always returns ErrOperationNotSupported.
+// Use the 'splunk clean eventdata -index <name>' CLI command on the indexers instead.
+func (col *IndexesCollection) Clean(name string) error {
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" clean", nil, "'name' cannot be empty")
+	}
+	return fmt.Errorf("%s clean '%s': %w. Use 'splunk clean eventdata' instead", col.name, name, ErrOperationNotSupported)
+}
+
+// postAction invokes one of the action endpoints available for indexes, such as /enable or /disable
+func (col *IndexesCollection) postAction(name, action string) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" "+action, nil, "'name' cannot be empty")
+	}
+	fullUrl := getUrl(col.path, name) + "/" + action
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s %s '%s': %w", col.name, action, name, err)
+	}
+	return nil
+}
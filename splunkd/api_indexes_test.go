@@ -0,0 +1,72 @@
+package splunkd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestIndexesList(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+
+	entries, err := ss.GetIndexes().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.Name == "_internal" {
+			found = true
+			if e.Content.DataType != "event" {
+				t.Errorf("index _internal has wrong datatype. expected=event got=%s", e.Content.DataType)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("index _internal not returned by IndexesCollection.List. got %d indexes", len(entries))
+	}
+}
+
+func TestIndexesActions(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/data/indexes"):
+			fmt.Fprint(w, `{"entry":[{"name":"testidx","content":{"datatype":"event","maxTotalDataSizeMB":500000,"frozenTimePeriodInSecs":188697600,"disabled":false}}]}`)
+		case r.Method == "GET":
+			fmt.Fprint(w, `{"entry":[{"name":"testidx","content":{"datatype":"metric","totalEventCount":42,"currentDBSizeMB":3,"minTime":"2023-11-14T22:13:20+0000","maxTime":"2023-11-15T22:13:20+0000","disabled":true}}]}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewIndexesCollection(ss)
+
+	e, err := col.Create("testidx", &url.Values{"datatype": {"event"}})
+	if err != nil || e.Content.MaxTotalDataSizeMB != 500000 || e.Content.FrozenTimePeriodInSecs != 188697600 {
+		t.Errorf("IndexesCollection.Create returned wrong results. entry=%+v err=%v", e, err)
+	}
+	e, err = col.Get("testidx")
+	if err != nil || e.Content.DataType != "metric" || e.Content.TotalEventCount != 42 || !e.Content.Disabled || e.Content.MinTime == "" {
+		t.Errorf("IndexesCollection.Get returned wrong results. entry=%+v err=%v", e, err)
+	}
+	requests = nil
+	if err := col.Disable("testidx"); err != nil {
+		t.Errorf("IndexesCollection.Disable returned an error: %s", err.Error())
+	}
+	if err := col.Enable("testidx"); err != nil {
+		t.Errorf("IndexesCollection.Enable returned an error: %s", err.Error())
+	}
+	if strings.Join(requests, ",") != "POST /services/data/indexes/testidx/disable,POST /services/data/indexes/testidx/enable" {
+		t.Errorf("IndexesCollection sent wrong requests: %v", requests)
+	}
+	if err := col.Clean("testidx"); !errors.Is(err, ErrOperationNotSupported) {
+		t.Errorf("IndexesCollection.Clean did not return ErrOperationNotSupported. got=%v", err)
+	}
+}
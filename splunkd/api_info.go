@@ -2,6 +2,7 @@ package splunkd
 
 import (
 	"fmt"
+	"time"
 )
 
 // This file provides structs used to parse the JSON-formatted output of the Splunk REST API
@@ -25,12 +26,16 @@ type InfoResource struct {
 }
 
 // Info retrieves generic information about the Splunk instance the client is connected to
-// It caches such information locally, as this is not something which regularly varies
+// It caches such information locally for the duration set with SetInfoCacheTTL (default 60s), as this is not something which regularly varies
 func (ss *Client) Info() (*InfoResource, error) {
-	if ss.info != nil {
+	if ss.info != nil && time.Since(ss.infoCachedAt) < ss.infoCacheTTL {
 		return ss.info, nil
 	}
+	return ss.RefreshInfo()
+}
 
+// RefreshInfo retrieves generic information about the Splunk instance, bypassing and updating the local cache
+func (ss *Client) RefreshInfo() (*InfoResource, error) {
 	col := collection[InfoResource]{
 		name: "info",
 		path: "server/info",
@@ -38,11 +43,26 @@ func (ss *Client) Info() (*InfoResource, error) {
 
 	// pathInfo represents this enpoint https://docs.splunk.com/Documentation/Splunk/8.1.3/RESTREF/RESTintrospect#server.2Finfo
 
-	err := doSplunkdHttpRequest(ss, "GET", "/services/server/info", nil, nil, "", &col)
+	err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", "/services/server/info", nil, nil, "", &col)
 	if err != nil {
 		return nil, fmt.Errorf("%s list: %w", col.name, err)
 	}
+	if len(col.Entries) == 0 {
+		return nil, fmt.Errorf("%s list: no entries returned", col.name)
+	}
 
 	ss.info = &col.Entries[0].Content
+	ss.infoCachedAt = time.Now()
 	return ss.info, nil
 }
+
+// SetInfoCacheTTL configures for how long the results of Info() are cached. A value of 0 disables caching.
+func (ss *Client) SetInfoCacheTTL(ttl time.Duration) {
+	ss.infoCacheTTL = ttl
+}
+
+// invalidateInfo clears the cached results of Info(), as these might differ after a new login
+func (ss *Client) invalidateInfo() {
+	ss.info = nil
+	ss.infoCachedAt = time.Time{}
+}
@@ -2,6 +2,7 @@ package splunkd
 
 import (
 	"testing"
+	"time"
 )
 
 func TestInfo(t *testing.T) {
@@ -17,3 +18,16 @@ func TestInfo(t *testing.T) {
 		t.Errorf("Invalid Info value provided. %+v", ir)
 	}
 }
+
+func TestInfoCache(t *testing.T) {
+	cached := &InfoResource{Build: "cached"}
+	// no httpClient is configured: any actual API call would fail
+	ss := &Client{info: cached, infoCachedAt: time.Now(), infoCacheTTL: time.Minute}
+	if ir, err := ss.Info(); err != nil || ir != cached {
+		t.Errorf("Info did not return the cached value. got=%+v err=%v", ir, err)
+	}
+	ss.invalidateInfo()
+	if ss.info != nil {
+		t.Errorf("invalidateInfo did not clear the cached value")
+	}
+}
@@ -99,7 +99,7 @@ func (e *entry[KVStoreCollResource]) Query(ss *Client, query, fields, sort strin
 		return utils.NewErrInvalidParam(ctx, nil, "'storeJSONResultInto' cannot be nil")
 	}
 
-	dataURL := strings.ReplaceAll(e.Links.List, "/collections/config/", "/collections/data/")
+	dataURL := e.dataURL()
 	queryParams := url.Values{}
 	queryParams.Set("query", query)
 	if sort != "" {
@@ -111,7 +111,7 @@ func (e *entry[KVStoreCollResource]) Query(ss *Client, query, fields, sort strin
 	if skip > 0 {
 		queryParams.Set("skip", strconv.FormatInt(int64(skip), 10))
 	}
-	if err := doSplunkdHttpRequest(ss, "GET", dataURL, &queryParams, nil, "", storeJSONResultInto); err != nil {
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", dataURL, &queryParams, nil, "", storeJSONResultInto); err != nil {
 		return fmt.Errorf("kvstore[%s] query: %w", e.Name, err)
 	}
 	return nil
@@ -125,14 +125,202 @@ func (e *entry[KVStoreCollResource]) Insert(ss *Client, jsondata string) (key st
 	if jsondata == "" {
 		return "", utils.NewErrInvalidParam(ctx, nil, "'jsondata' cannot be empty")
 	}
-	dataURL := strings.ReplaceAll(e.Links.List, "/collections/config/", "/collections/data/")
+	dataURL := e.dataURL()
 	dataRes := make(map[string]string, 0)
-	if err = doSplunkdHttpRequest(ss, "POST", dataURL, nil, []byte(jsondata), "application/json", &dataRes); err != nil {
+	if err = doSplunkdHttpRequest(ss.getContext(), ss, "POST", dataURL, nil, []byte(jsondata), "application/json", &dataRes); err != nil {
 		return "", fmt.Errorf("%s: %w", ctx, err)
 	}
 	return dataRes["_key"], nil
 }
 
+// kvStoreMaxDocumentsPerBatchSave is the default value of max_documents_per_batch_save within limits.conf
+const kvStoreMaxDocumentsPerBatchSave = 1000
+
+// InsertBatchAtomic saves multiple JSON-formatted records using the 'batch_save' endpoint, returning the keys of the saved records.
+// All records are validated to be JSON objects before any API call is made.
+// Records are sent in batches of at most 1000 documents (the Splunk default for max_documents_per_batch_save): if any batch fails,
+// the records saved by the previous batches are deleted, so that the collection is not left containing only part of the data.
+// Records cannot provide a '_key': batch_save would replace an existing record with the same key, which the rollback would then delete.
+// Use BatchUpsert to save records with known keys.
+func (e *entry[KVStoreCollResource]) InsertBatchAtomic(ss *Client, records []string) ([]string, error) {
+	ctx := fmt.Sprintf("kvstore[%s] insertBatchAtomic", e.Name)
+	if ss == nil {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'splunkService' cannot be nil")
+	}
+	if len(records) == 0 {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'records' cannot be empty")
+	}
+	docs := make([]json.RawMessage, 0, len(records))
+	for i, r := range records {
+		var tmp map[string]interface{}
+		if err := json.Unmarshal([]byte(r), &tmp); err != nil {
+			return nil, utils.NewErrInvalidParam(ctx, err, "record %d is not a valid JSON object", i)
+		}
+		if _, found := tmp["_key"]; found {
+			return nil, utils.NewErrInvalidParam(ctx, nil, "record %d provides a '_key', which is not supported by atomic inserts", i)
+		}
+		docs = append(docs, json.RawMessage(r))
+	}
+
+	dataURL := e.dataURL()
+	batchURL, _ := url.JoinPath(dataURL, "batch_save")
+	keys := make([]string, 0, len(records))
+	for start := 0; start < len(docs); start += kvStoreMaxDocumentsPerBatchSave {
+		end := start + kvStoreMaxDocumentsPerBatchSave
+		if end > len(docs) {
+			end = len(docs)
+		}
+		body, _ := json.Marshal(docs[start:end])
+		batchKeys := make([]string, 0, end-start)
+		if err := doSplunkdHttpRequest(ss.getContext(), ss, "POST", batchURL, nil, body, "application/json", &batchKeys); err != nil {
+			// batchKeys might have been partially parsed: roll those back as well
+			keys = append(keys, batchKeys...)
+			if rbErr := e.deleteKeys(ss, keys); rbErr != nil {
+				return nil, fmt.Errorf("%s: %w. Rollback failed: %s", ctx, err, rbErr.Error())
+			}
+			return nil, fmt.Errorf("%s: %w", ctx, err)
+		}
+		keys = append(keys, batchKeys...)
+	}
+	return keys, nil
+}
+
+// dataURL returns the url of the data endpoint of the collection: /storage/collections/data/<name>
+func (e *entry[KVStoreCollResource]) dataURL() string {
+	return strings.ReplaceAll(e.Links.List, "/collections/config/", "/collections/data/")
+}
+
+// BatchInsert saves records using the 'batch_save' endpoint, returning the keys of the saved records.
+// Records are sent in batches of at most 1000 documents (the Splunk default for max_documents_per_batch_save).
+// Contrary to InsertBatchAtomic, a failed batch does not roll back the previous ones: the keys of the records saved
+// before the failure are returned along with the error.
+func (e *entry[KVStoreCollResource]) BatchInsert(ss *Client, records []map[string]interface{}) ([]string, error) {
+	ctx := fmt.Sprintf("kvstore[%s] batchInsert", e.Name)
+	if ss == nil {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'splunkService' cannot be nil")
+	}
+	if len(records) == 0 {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'records' cannot be empty")
+	}
+	keys, err := e.batchSave(ss, records)
+	if err != nil {
+		return keys, fmt.Errorf("%s: %w", ctx, err)
+	}
+	return keys, nil
+}
+
+// BatchUpsert saves records using the 'batch_save' endpoint, returning their keys.
+// Every record must provide its '_key': records with an existing key are replaced, the others are created.
+func (e *entry[KVStoreCollResource]) BatchUpsert(ss *Client, records []map[string]interface{}) ([]string, error) {
+	ctx := fmt.Sprintf("kvstore[%s] batchUpsert", e.Name)
+	if ss == nil {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'splunkService' cannot be nil")
+	}
+	if len(records) == 0 {
+		return nil, utils.NewErrInvalidParam(ctx, nil, "'records' cannot be empty")
+	}
+	for i, r := range records {
+		if k, ok := r["_key"].(string); !ok || k == "" {
+			return nil, utils.NewErrInvalidParam(ctx, nil, "record %d does not provide a '_key'", i)
+		}
+	}
+	keys, err := e.batchSave(ss, records)
+	if err != nil {
+		return keys, fmt.Errorf("%s: %w", ctx, err)
+	}
+	return keys, nil
+}
+
+// Upsert saves record with the provided key, replacing the record if it already exists, creating it otherwise.
+// The 'batch_save' endpoint is used, since updating a record through its own url fails if the key does not exist.
+// record is not modified.
+func (e *entry[KVStoreCollResource]) Upsert(ss *Client, key string, record map[string]interface{}) error {
+	ctx := fmt.Sprintf("kvstore[%s] upsert", e.Name)
+	if ss == nil {
+		return utils.NewErrInvalidParam(ctx, nil, "'splunkService' cannot be nil")
+	}
+	if key == "" {
+		return utils.NewErrInvalidParam(ctx, nil, "'key' cannot be empty")
+	}
+	doc := make(map[string]interface{}, len(record)+1)
+	for k, v := range record {
+		doc[k] = v
+	}
+	doc["_key"] = key
+	if _, err := e.batchSave(ss, []map[string]interface{}{doc}); err != nil {
+		return fmt.Errorf("%s '%s': %w", ctx, key, err)
+	}
+	return nil
+}
+
+// batchSave posts records to the 'batch_save' endpoint in batches of at most kvStoreMaxDocumentsPerBatchSave documents,
+// returning the keys of the records saved until the first failure
+func (e *entry[KVStoreCollResource]) batchSave(ss *Client, records []map[string]interface{}) ([]string, error) {
+	batchURL, _ := url.JoinPath(e.dataURL(), "batch_save")
+	keys := make([]string, 0, len(records))
+	for start := 0; start < len(records); start += kvStoreMaxDocumentsPerBatchSave {
+		end := start + kvStoreMaxDocumentsPerBatchSave
+		if end > len(records) {
+			end = len(records)
+		}
+		body, err := json.Marshal(records[start:end])
+		if err != nil {
+			return keys, utils.NewErrInvalidParam("batchSave", err, "records %d-%d cannot be converted to JSON", start, end-1)
+		}
+		batchKeys := make([]string, 0, end-start)
+		if err := doSplunkdHttpRequest(ss.getContext(), ss, "POST", batchURL, nil, body, "application/json", &batchKeys); err != nil {
+			return keys, err
+		}
+		keys = append(keys, batchKeys...)
+	}
+	return keys, nil
+}
+
+// QueryDelete deletes the records matching query, returning how many records were deleted.
+// query uses the same syntax as Query, use "{}" to delete all records.
+// splunkd does not report how many records a deletion removed: the matching records are counted beforehand,
+// so the count can be inaccurate if the collection is concurrently modified.
+func (e *entry[KVStoreCollResource]) QueryDelete(ss *Client, query string) (int, error) {
+	ctx := fmt.Sprintf("kvstore[%s] queryDelete", e.Name)
+	if ss == nil {
+		return 0, utils.NewErrInvalidParam(ctx, nil, "'splunkService' cannot be nil")
+	}
+	if query == "" {
+		return 0, utils.NewErrInvalidParam(ctx, nil, "'query' cannot be empty. Provide \"{}\" to delete all documents")
+	}
+	matching := make([]map[string]interface{}, 0)
+	queryParams := url.Values{}
+	queryParams.Set("query", query)
+	queryParams.Set("fields", "_key")
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", e.dataURL(), &queryParams, nil, "", &matching); err != nil {
+		return 0, fmt.Errorf("%s: %w", ctx, err)
+	}
+	if len(matching) == 0 {
+		return 0, nil
+	}
+	queryParams.Del("fields")
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "DELETE", e.dataURL(), &queryParams, nil, "", &discardBody{}); err != nil {
+		return 0, fmt.Errorf("%s: %w", ctx, err)
+	}
+	return len(matching), nil
+}
+
+// deleteKeys deletes the records identified by the provided keys, attempting to delete all of them even in case of errors.
+func (e *entry[KVStoreCollResource]) deleteKeys(ss *Client, keys []string) error {
+	dataURL := e.dataURL()
+	failed := make([]string, 0)
+	for _, k := range keys {
+		keyURL, _ := url.JoinPath(dataURL, url.PathEscape(k))
+		if err := doSplunkdHttpRequest(ss.getContext(), ss, "DELETE", keyURL, nil, nil, "", &discardBody{}); err != nil {
+			failed = append(failed, k)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("kvstore[%s] could not delete keys: %s", e.Name, strings.Join(failed, ","))
+	}
+	return nil
+}
+
 // KVStoreCollCollection represents a collection of definitions of KV Store collections as managed by the /services/storage/collections/config endpoint.
 // This also supports custom configuration files defined with a custom SPEC file within etc/apps/<someapp>/README/<somefile>.conf.spec.
 // See: https://docs.splunk.com/Documentation/Splunk/9.0.5/RESTREF/RESTkvstore#storage.2Fcollections.2Fconfig.2F.7Bcollection.7D
@@ -1,6 +1,12 @@
 package splunkd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -91,3 +97,126 @@ func TestKVStoreDataManagement(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestKVStoreInsertBatchAtomicValidation(t *testing.T) {
+	e := entry[KVStoreCollResource]{Name: "test"}
+	// validation must fail before any API call is performed, so an unconnected client is enough
+	if _, err := e.InsertBatchAtomic(&Client{}, []string{`{"a":1}`, `{"b":`}); err == nil {
+		t.Errorf("InsertBatchAtomic did not fail with an invalid JSON record")
+	}
+	if _, err := e.InsertBatchAtomic(&Client{}, []string{`[1,2]`}); err == nil {
+		t.Errorf("InsertBatchAtomic did not fail with a record which is not a JSON object")
+	}
+	if _, err := e.InsertBatchAtomic(&Client{}, []string{`{"a":1}`, `{"_key":"existing","b":2}`}); err == nil {
+		t.Errorf("InsertBatchAtomic accepted a record providing a '_key', which a rollback would delete")
+	}
+}
+
+func TestKVStoreBatchUpsertQueryDelete(t *testing.T) {
+	var requests []string
+	var batchSizes []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.Query().Get("query")+"&"+r.URL.Query().Get("fields"))
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/batch_save"):
+			docs := []map[string]interface{}{}
+			json.Unmarshal(body, &docs)
+			batchSizes = append(batchSizes, len(docs))
+			keys := make([]string, len(docs))
+			for i, d := range docs {
+				if k, ok := d["_key"].(string); ok {
+					keys[i] = k
+				} else {
+					keys[i] = fmt.Sprintf("gen-%d", i)
+				}
+			}
+			json.NewEncoder(w).Encode(keys)
+		case r.Method == "GET":
+			fmt.Fprint(w, `[{"_key":"a"},{"_key":"b"}]`)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	e := entry[KVStoreCollResource]{Name: "test"}
+	e.Links.List = "/servicesNS/nobody/search/storage/collections/config/test"
+
+	records := make([]map[string]interface{}, kvStoreMaxDocumentsPerBatchSave+1)
+	for i := range records {
+		records[i] = map[string]interface{}{"n": i}
+	}
+	keys, err := e.BatchInsert(ss, records)
+	if err != nil || len(keys) != len(records) {
+		t.Fatalf("BatchInsert returned wrong results. keys=%d err=%v", len(keys), err)
+	}
+	if len(batchSizes) != 2 || batchSizes[0] != kvStoreMaxDocumentsPerBatchSave || batchSizes[1] != 1 {
+		t.Errorf("BatchInsert did not split the records into batches: %v", batchSizes)
+	}
+
+	if _, err := e.BatchUpsert(ss, []map[string]interface{}{{"_key": "a"}, {"n": 1}}); err == nil {
+		t.Error("BatchUpsert accepted a record without _key")
+	}
+	if keys, err := e.BatchUpsert(ss, []map[string]interface{}{{"_key": "a", "n": 0}, {"_key": "b", "n": 1}}); err != nil || strings.Join(keys, ",") != "a,b" {
+		t.Errorf("BatchUpsert returned wrong results. keys=%v err=%v", keys, err)
+	}
+	record := map[string]interface{}{"n": 2}
+	if err := e.Upsert(ss, "c", record); err != nil {
+		t.Errorf("Upsert returned an error: %s", err.Error())
+	}
+	if _, found := record["_key"]; found {
+		t.Error("Upsert modified the provided record")
+	}
+
+	requests = nil
+	if _, err := e.QueryDelete(ss, ""); err == nil {
+		t.Error("QueryDelete accepted an empty query")
+	}
+	cnt, err := e.QueryDelete(ss, `{"n":{"$lt":2}}`)
+	if err != nil || cnt != 2 {
+		t.Errorf("QueryDelete returned wrong results. cnt=%d err=%v", cnt, err)
+	}
+	expected := []string{
+		`GET /servicesNS/nobody/search/storage/collections/data/test?{"n":{"$lt":2}}&_key`,
+		`DELETE /servicesNS/nobody/search/storage/collections/data/test?{"n":{"$lt":2}}&`,
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("QueryDelete sent wrong requests.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(requests, "\n"))
+	}
+}
+
+func TestKVStoreBatchRoundTrip(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+
+	kvc := ss.GetKVStore()
+	collectionName := "test-collection-" + uuid.New().String()[0:8]
+	ns, _ := NewNamespace("nobody", "search", SplunkSharingApp)
+	kvce, err := kvc.CreateKVStoreColl(ns, collectionName, map[string]string{"n": KVStoreFieldTypeNumber}, nil, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kvc.DeleteEntry(kvce)
+
+	keys, err := kvce.BatchInsert(ss, []map[string]interface{}{{"n": 1}, {"n": 2}, {"n": 3}})
+	if err != nil || len(keys) != 3 {
+		t.Fatalf("BatchInsert returned wrong results. keys=%v err=%v", keys, err)
+	}
+	if err := kvce.Upsert(ss, keys[0], map[string]interface{}{"n": 10}); err != nil {
+		t.Errorf("Upsert of an existing key returned an error: %s", err.Error())
+	}
+	if _, err := kvce.BatchUpsert(ss, []map[string]interface{}{{"_key": "new-key", "n": 20}, {"_key": keys[1], "n": 30}}); err != nil {
+		t.Errorf("BatchUpsert returned an error: %s", err.Error())
+	}
+	data := make([]map[string]interface{}, 0)
+	if err := kvce.Query(ss, "{}", "", "", 0, 0, false, &data); err != nil || len(data) != 4 {
+		t.Errorf("the collection is expected to have 4 records. found=%d err=%v", len(data), err)
+	}
+	if cnt, err := kvce.QueryDelete(ss, `{"n":{"$gte":10}}`); err != nil || cnt != 3 {
+		t.Errorf("QueryDelete returned wrong results. cnt=%d err=%v", cnt, err)
+	}
+	data = data[:0]
+	if err := kvce.Query(ss, "{}", "", "", 0, 0, false, &data); err != nil || len(data) != 1 {
+		t.Errorf("the collection is expected to have 1 record after QueryDelete. found=%d err=%v", len(data), err)
+	}
+}
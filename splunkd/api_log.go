@@ -2,12 +2,34 @@ package splunkd
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net/url"
 	"os"
+	"regexp"
+	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
 )
 
-func (ss *Client) NewLogger(name string, flag int, index, host, source, sourcetype string) *log.Logger {
+// validIndexName matches index names accepted by splunk: lowercase letters, digits, underscores and dashes, not beginning with a dash.
+var validIndexName = regexp.MustCompile(`^[a-z0-9_][a-z0-9_-]*$`)
+
+// NewLogger returns a logger writing its messages into splunk using the services/receivers/simple endpoint.
+// Empty parameters get a default value. An error is returned if the parameters would be refused by splunkd,
+// as the resulting logger would otherwise silently fail to write any message.
+func (ss *Client) NewLogger(name string, flag int, index, host, source, sourcetype string) (*log.Logger, error) {
+	if ss == nil {
+		return nil, utils.NewErrInvalidParam("newLogger", nil, "'splunkService' cannot be nil")
+	}
+	if index != "" && !validIndexName.MatchString(index) {
+		return nil, utils.NewErrInvalidParam("newLogger", nil, "'index' must only contain lowercase letters, digits, underscores and dashes. provided: '%s'", index)
+	}
+	for paramName, v := range map[string]string{"host": host, "source": source, "sourcetype": sourcetype} {
+		if strings.ContainsAny(v, "\r\n") {
+			return nil, utils.NewErrInvalidParam("newLogger", nil, "'%s' cannot contain line breaks", paramName)
+		}
+	}
 	if index == "" {
 		index = "_internal"
 	}
@@ -39,8 +61,16 @@ func (ss *Client) NewLogger(name string, flag int, index, host, source, sourcety
 	if name != "" {
 		prefix = fmt.Sprintf("[%s] ", name)
 	}
+	if ss.loggerWriter != nil {
+		return log.New(ss.loggerWriter, prefix, flag), nil
+	}
+	return log.New(l, prefix, flag), nil
+}
 
-	return log.New(l, prefix, flag)
+// SetLoggerWriter redirects the output of all the loggers which will be created by NewLogger to w instead of splunkd.
+// This is useful in test environments, where no splunk instance is available. Provide nil to restore writing to splunkd.
+func (ss *Client) SetLoggerWriter(w io.Writer) {
+	ss.loggerWriter = w
 }
 
 // splunkdLogger is an object which can be used as a writer for GO's "log" package
@@ -60,7 +90,7 @@ type splunkdLogger struct {
 // Ref: https://pkg.go.dev/io#Writer
 func (l splunkdLogger) Write(p []byte) (n int, err error) {
 	lr := logResult{}
-	if err := doSplunkdHttpRequest(l.splunkd, "POST", "services/receivers/simple", &l.loggingParams, p, "", &lr); err != nil {
+	if err := doSplunkdHttpRequest(l.splunkd.getContext(), l.splunkd, "POST", "services/receivers/simple", &l.loggingParams, p, "", &lr); err != nil {
 		return 0, fmt.Errorf("splunk service logger[%s]: %w", l.name, err)
 	}
 	if lr.Bytes < len(p) {
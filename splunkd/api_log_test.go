@@ -2,18 +2,51 @@ package splunkd
 
 import (
 	"log"
+	"strings"
 	"testing"
 )
 
 func TestLogger(t *testing.T) {
 	ss := mustLoginToSplunk(t)
 
-	logger := ss.NewLogger("testLogger", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lmsgprefix, "main", "", "go-test", "go-test")
+	logger, err := ss.NewLogger("testLogger", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lmsgprefix, "main", "", "go-test", "go-test")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
 	t.Logf("Writing test messages into index=%s, sourcetype=%s\n", "main", "go-test")
 
 	logger.Println("test log message sent using log.Println")
 }
 
+func TestLoggerWriter(t *testing.T) {
+	ss := &Client{}
+	buf := new(strings.Builder)
+	ss.SetLoggerWriter(buf)
+	logger, err := ss.NewLogger("testLogger", 0, "main", "", "go-test", "go-test")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	logger.Println("test log message")
+	if buf.String() != "[testLogger] test log message\n" {
+		t.Errorf("NewLogger did not write to the configured writer. got='%s'", buf.String())
+	}
+}
+
+func TestLoggerInvalidParams(t *testing.T) {
+	ss := &Client{}
+	if _, err := ss.NewLogger("testLogger", 0, "Main Index", "", "go-test", "go-test"); err == nil {
+		t.Errorf("NewLogger did not return an error for an invalid index name")
+	}
+	if _, err := ss.NewLogger("testLogger", 0, "main", "", "go-test", "go\ntest"); err == nil {
+		t.Errorf("NewLogger did not return an error for a sourcetype containing a line break")
+	}
+	if _, err := ss.NewLogger("testLogger", 0, "_internal", "", "", ""); err != nil {
+		t.Errorf("NewLogger returned an error for valid parameters: %s", err.Error())
+	}
+}
+
 /*
 func TestLoggerInternal(t *testing.T) {
 	if ss, err = New(testing_endpoint, testing_insecureSkipVerify, testing_proxy); err != nil {
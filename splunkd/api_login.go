@@ -44,14 +44,14 @@ func (ss *Client) Login(username, password, passcode2FA string) error {
 
 	// Submit login form
 	lr := LoginResponse{}
-	if err = doSplunkdHttpRequest(ss, "POST", pathLogin, nil, []byte(loginParams.Encode()), "", &lr); err != nil {
+	if err = doSplunkdHttpRequest(ss.getContext(), ss, "POST", pathLogin, nil, []byte(loginParams.Encode()), "", &lr); err != nil {
 		return fmt.Errorf("login: %w", err)
 	}
 
 	// All is fine, store session key
 	// HTTP 200
 	// {"sessionKey":"FKPT2.......","message":"","code":""}
-	ss.sessionKey = lr.SessionKey
+	ss.setAuth(lr.SessionKey, "", username)
 
 	// retrieve authentication context information
 	ss.AuthContext()
@@ -59,11 +59,14 @@ func (ss *Client) Login(username, password, passcode2FA string) error {
 	return nil
 }
 
+// LoginWithToken authenticates all subsequent requests with the bearer token authToken,
+// replacing any session key previously obtained by the client.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/Security/UseAuthTokens
 func (ss *Client) LoginWithToken(authToken string) error {
 	if authToken == "" {
 		return utils.NewErrInvalidParam("loginWithToken", nil, "'authToken' cannot be empty")
 	}
-	ss.authToken = authToken
+	ss.setAuth("", authToken, "")
 	if _, err := ss.AuthContext(); err != nil {
 		return fmt.Errorf("loginWithToken: %w", err)
 	}
@@ -74,9 +77,83 @@ func (ss *Client) LoginWithSessionKey(sessionKey string) error {
 	if sessionKey == "" {
 		return utils.NewErrInvalidParam("loginWithSessionKey", nil, "'sessionKey' cannot be empty")
 	}
-	ss.sessionKey = sessionKey
+	ss.setAuth(sessionKey, "", "")
 	if _, err := ss.AuthContext(); err != nil {
 		return fmt.Errorf("loginWithSessionKey: %w", err)
 	}
 	return nil
 }
+
+// setAuth replaces the credentials used to authenticate the requests, and resets the information depending on them.
+// Requests being prepared concurrently use either the previous or the new credentials.
+func (ss *Client) setAuth(sessionKey, authToken, username string) {
+	ss.authMu.Lock()
+	ss.sessionKey = sessionKey
+	ss.authToken = authToken
+	ss.username = username
+	ss.authContext = nil
+	ss.authMu.Unlock()
+	ss.invalidateInfo()
+}
+
+// AuthMethod returns how the client authenticates its requests: "session_key", "token", or "unauthenticated"
+func (ss *Client) AuthMethod() string {
+	ss.authMu.RLock()
+	defer ss.authMu.RUnlock()
+	return ss.authMethod()
+}
+
+// authMethod implements AuthMethod. Must be called while holding authMu
+func (ss *Client) authMethod() string {
+	switch {
+	case ss.sessionKey != "":
+		return "session_key"
+	case ss.authToken != "":
+		return "token"
+	}
+	return "unauthenticated"
+}
+
+// IsAuthenticated returns true if the client has a session key or an authentication token.
+// This does not verify whether they are still valid: see Ping.
+func (ss *Client) IsAuthenticated() bool {
+	return ss.AuthMethod() != "unauthenticated"
+}
+
+// authorizationHeader returns the value of the Authorization header for the requests to splunkd, or "" if the client is not authenticated
+func (ss *Client) authorizationHeader() string {
+	ss.authMu.RLock()
+	defer ss.authMu.RUnlock()
+	switch ss.authMethod() {
+	case "session_key":
+		return "Splunk " + ss.sessionKey
+	case "token":
+		return "Bearer " + ss.authToken
+	}
+	return ""
+}
+
+// Ping performs a lightweight authenticated request to splunkd, returning nil if the session of the client is still valid.
+// If splunkd rejects the credentials of the client, e.g. as the session key expired, the returned error satisfies IsUnauthorized.
+func (ss *Client) Ping() error {
+	if !ss.IsAuthenticated() {
+		return fmt.Errorf("ping: client is not authenticated")
+	}
+	// count=0 avoids the transfer of the information of the server, which is not needed
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", "/services/server/info", &url.Values{"count": {"0"}}, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
+
+// RefreshSession authenticates again with username and password, e.g. after the session key of the client expired.
+// The session key is replaced only if the authentication succeeds: otherwise, the client keeps its previous credentials.
+// Requests can be performed concurrently while the session is being refreshed, and concurrent refreshes are serialized.
+func (ss *Client) RefreshSession(username, password string) error {
+	ss.refreshMu.Lock()
+	defer ss.refreshMu.Unlock()
+	if err := ss.Login(username, password, ""); err != nil {
+		return fmt.Errorf("refreshSession: %w", err)
+	}
+	return nil
+}
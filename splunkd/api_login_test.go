@@ -0,0 +1,96 @@
+package splunkd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSessionServer emulates the authentication of splunkd through session keys
+type fakeSessionServer struct {
+	mu        sync.Mutex
+	validKeys map[string]bool
+	logins    int
+}
+
+func (fs *fakeSessionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if r.URL.Path == pathLogin {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		if form.Get("username") != "admin" || form.Get("password") != "changeme" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"messages":[{"type":"WARN","code":"incorrect_username_or_password","text":"Login failed"}]}`)
+			return
+		}
+		fs.logins++
+		key := fmt.Sprintf("key%d", fs.logins)
+		fs.validKeys[key] = true
+		fmt.Fprintf(w, `{"sessionKey":"%s"}`, key)
+		return
+	}
+	if key, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Splunk "); !fs.validKeys[key] {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`)
+		return
+	}
+	fmt.Fprint(w, `{"entry":[{"name":"server-info","content":{"username":"admin"}}]}`)
+}
+
+func TestPingAndRefreshSession(t *testing.T) {
+	fs := &fakeSessionServer{validKeys: map[string]bool{"key0": true}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), retryPolicy: &RetryPolicy{MaxAttempts: 1}}
+	if ss.IsAuthenticated() || ss.Ping() == nil {
+		t.Error("unauthenticated client reported as authenticated")
+	}
+	ss.sessionKey = "key0"
+	if !ss.IsAuthenticated() {
+		t.Error("client having a session key reported as not authenticated")
+	}
+	if err := ss.Ping(); err != nil {
+		t.Errorf("Ping failed with a valid session: %s", err.Error())
+	}
+
+	// the session expires
+	fs.mu.Lock()
+	delete(fs.validKeys, "key0")
+	fs.mu.Unlock()
+	if err := ss.Ping(); err == nil || !IsUnauthorized(err) {
+		t.Errorf("Ping did not report the expired session. err=%v", err)
+	}
+	if err := ss.RefreshSession("admin", "wrong"); err == nil || ss.GetSessionKey() != "key0" {
+		t.Errorf("failed refresh modified the session key. key=%s err=%v", ss.GetSessionKey(), err)
+	}
+	if err := ss.RefreshSession("admin", "changeme"); err != nil {
+		t.Fatalf("RefreshSession failed: %s", err.Error())
+	}
+	if ss.GetSessionKey() != "key1" {
+		t.Errorf("session key was not replaced. key=%s", ss.GetSessionKey())
+	}
+
+	// requests can be performed while the session is being refreshed
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ss.Ping()
+		}()
+		go func() {
+			defer wg.Done()
+			ss.RefreshSession("admin", "changeme")
+		}()
+	}
+	wg.Wait()
+	if err := ss.Ping(); err != nil {
+		t.Errorf("Ping failed after the concurrent refreshes: %s", err.Error())
+	}
+}
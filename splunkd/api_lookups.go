@@ -0,0 +1,172 @@
+package splunkd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// LookupFileResource represents a CSV lookup file.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTknowledge#data.2Flookup-table-files
+type LookupFileResource struct {
+	// Path of the lookup file on the splunkd server
+	Path         string            `json:"eai:data"`
+	Size         int64             `json:"size"`
+	ModifiedTime string            `json:"updated"`
+	Permissions  AccessControlList `json:"eai:acl"`
+}
+
+// LookupFilesCollection manages the CSV lookup files of an app.
+// The REST API of splunkd does not provide the contents of lookup files, nor accepts them directly:
+// Upload and Download use the outputlookup and inputlookup search commands instead.
+type LookupFilesCollection struct {
+	collection[LookupFileResource]
+	app string
+}
+
+func NewLookupFilesCollection(ss *Client, app string) *LookupFilesCollection {
+	var col = &LookupFilesCollection{app: app}
+	ns, _ := NewNamespace("-", app, SplunkSharingApp)
+	col.name = "lookup-table-files"
+	col.path = getUrlNS(ns, "data/lookup-table-files")
+	col.splunkd = ss
+	return col
+}
+
+// searchNamespace returns the namespace used to run the searches reading and writing the lookup files of the app
+func (col *LookupFilesCollection) searchNamespace() *Namespace {
+	// jobs cannot be created with a wildcard owner
+	owner := col.splunkd.nameSpace.owner
+	if owner == "" || owner == "-" {
+		owner = col.splunkd.username
+	}
+	if owner == "" {
+		owner = "nobody"
+	}
+	ns, _ := NewNamespace(owner, col.app, SplunkSharingApp)
+	return ns
+}
+
+// Upload writes csvContent into the lookup file called name, replacing it if it already exists.
+// The first line of csvContent must contain the header with the field names.
+// This requires Splunk 9.0 or later, as the file is written using 'makeresults format=csv' and 'outputlookup'.
+func (col *LookupFilesCollection) Upload(name, csvContent string) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" upload", nil, "'name' cannot be empty")
+	}
+	if _, err := csv.NewReader(strings.NewReader(csvContent)).ReadAll(); err != nil {
+		return utils.NewErrInvalidParam(col.name+" upload", err, "'csvContent' is not a valid CSV")
+	}
+	if strings.TrimSpace(csvContent) == "" {
+		return utils.NewErrInvalidParam(col.name+" upload", nil, "'csvContent' must contain at least the header")
+	}
+	job, err := col.splunkd.newSearchJobNS(col.searchNamespace(), uploadLookupSearch(name, csvContent), nil)
+	if err != nil {
+		return fmt.Errorf("%s upload '%s': %w", col.name, name, err)
+	}
+	if err := job.Wait(col.splunkd.getContext()); err != nil {
+		return fmt.Errorf("%s upload '%s': %w", col.name, name, err)
+	}
+	return nil
+}
+
+// uploadLookupSearch returns the search writing csvContent into the lookup file called name
+func uploadLookupSearch(name, csvContent string) string {
+	return fmt.Sprintf(`| makeresults format=csv data=%s | fields - _time | outputlookup %s`, quoteSPL(csvContent), quoteSPL(name))
+}
+
+// quoteSPL returns s as a double-quoted string literal of the splunk search language
+func quoteSPL(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Download returns the contents of the lookup file called name, in CSV format
+func (col *LookupFilesCollection) Download(name string) (string, error) {
+	if err := col.isInitialized(); err != nil {
+		return "", fmt.Errorf("download: %w", err)
+	}
+	if name == "" {
+		return "", utils.NewErrInvalidParam(col.name+" download", nil, "'name' cannot be empty")
+	}
+	job, err := col.splunkd.newSearchJobNS(col.searchNamespace(), "| inputlookup "+quoteSPL(name), nil)
+	if err != nil {
+		return "", fmt.Errorf("%s download '%s': %w", col.name, name, err)
+	}
+	if err := job.Wait(col.splunkd.getContext()); err != nil {
+		return "", fmt.Errorf("%s download '%s': %w", col.name, name, err)
+	}
+	resp, err := col.splunkd.DoRawRequest("GET", job.path+"/results?output_mode=csv&count=0", nil, "")
+	if err != nil {
+		return "", fmt.Errorf("%s download '%s': %w", col.name, name, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("%s download '%s': %w", col.name, name, err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("%s download '%s': %w", col.name, name, newSplunkAPIError("GET", job.path+"/results", resp, body))
+	}
+	return string(body), nil
+}
+
+// Delete removes the lookup file called name
+func (col *LookupFilesCollection) Delete(name string) error {
+	e, err := col.Get(name)
+	if err != nil {
+		return fmt.Errorf("%s delete '%s': %w", col.name, name, err)
+	}
+	// the entry must be deleted within the namespace of its owner, which is provided by its own links
+	return col.DeleteEntry(e)
+}
+
+// TransformsLookupResource represents the definition of a lookup within transforms.conf
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTknowledge#data.2Ftransforms.2Flookups
+type TransformsLookupResource struct {
+	Filename string `json:"filename"`
+	// Collection is the name of the KVStore collection, for KVStore-based lookups
+	Collection   string `json:"collection"`
+	ExternalType string `json:"external_type"`
+	ExternalCmd  string `json:"external_cmd"`
+	FieldsList   string `json:"fields_list"`
+	MatchType    string `json:"match_type"`
+	MaxMatches   string `json:"max_matches"`
+	MinMatches   string `json:"min_matches"`
+	DefaultMatch string `json:"default_match"`
+}
+
+// TransformsLookupCollection manages the definitions of lookups, as configured within transforms.conf
+type TransformsLookupCollection struct {
+	collection[TransformsLookupResource]
+}
+
+func NewTransformsLookupCollection(ss *Client) *TransformsLookupCollection {
+	var col = &TransformsLookupCollection{}
+	col.name = "transforms-lookups"
+	col.path = "data/transforms/lookups"
+	col.splunkd = ss
+	return col
+}
+
+// Create defines a new lookup called name, based on the lookup file filename.
+// Further settings, such as fields_list or case_sensitive_match, can be provided with params.
+func (col *TransformsLookupCollection) Create(name, filename string, params *url.Values) (*entry[TransformsLookupResource], error) {
+	if filename == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'filename' cannot be empty")
+	}
+	tmpParams := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			tmpParams[k] = v
+		}
+	}
+	tmpParams.Set("filename", filename)
+	return col.collection.Create(name, &tmpParams)
+}
@@ -0,0 +1,151 @@
+package splunkd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestLookupFilesUploadDownload(t *testing.T) {
+	var searches []string
+	var lastResults string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/search/jobs"):
+			if r.URL.Path != "/servicesNS/admin/search/search/jobs" {
+				t.Errorf("search job created in a wrong namespace: %s", r.URL.Path)
+			}
+			searches = append(searches, form.Get("search"))
+			fmt.Fprint(w, `{"sid":"1700000000.2"}`)
+		case strings.HasSuffix(r.URL.Path, "/search/jobs/1700000000.2"):
+			fmt.Fprint(w, `{"entry":[{"name":"1700000000.2","content":{"sid":"1700000000.2","isDone":true,"dispatchState":"DONE"}}]}`)
+		case strings.HasSuffix(r.URL.Path, "/search/jobs/1700000000.2/results"):
+			lastResults = r.URL.RawQuery
+			fmt.Fprint(w, "user,comment\nalice,\"said \"\"hi\"\"\"\n")
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/data/lookup-table-files/users.csv"):
+			fmt.Fprint(w, `{"entry":[{"name":"users.csv","links":{"remove":"/servicesNS/admin/search/data/lookup-table-files/users.csv"},"content":{"eai:data":"/opt/splunk/etc/apps/search/lookups/users.csv","size":42,"updated":"2023-11-14T22:13:20+00:00"}}]}`)
+		case r.Method == "DELETE" && r.URL.Path == "/servicesNS/admin/search/data/lookup-table-files/users.csv":
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), username: "admin"}
+	col := NewLookupFilesCollection(ss, "search")
+
+	if err := col.Upload("users.csv", "a,\"b\n"); err == nil {
+		t.Error("LookupFilesCollection.Upload accepted an invalid CSV")
+	}
+	if err := col.Upload("users.csv", "user,comment\nalice,\"said \"\"hi\"\"\"\n"); err != nil {
+		t.Fatalf("LookupFilesCollection.Upload returned an error: %s", err.Error())
+	}
+	expected := `| makeresults format=csv data="user,comment` + "\n" + `alice,\"said \"\"hi\"\"\"` + "\n" + `" | fields - _time | outputlookup "users.csv"`
+	if len(searches) != 1 || searches[0] != expected {
+		t.Errorf("LookupFilesCollection.Upload ran a wrong search.\nexpected: %s\ngot:      %v", expected, searches)
+	}
+
+	content, err := col.Download("users.csv")
+	if err != nil {
+		t.Fatalf("LookupFilesCollection.Download returned an error: %s", err.Error())
+	}
+	if !strings.HasPrefix(content, "user,comment\n") || searches[1] != `| inputlookup "users.csv"` {
+		t.Errorf("LookupFilesCollection.Download returned wrong contents. search=%s content=%q", searches[1], content)
+	}
+	if q, _ := url.ParseQuery(lastResults); q.Get("output_mode") != "csv" || q.Get("count") != "0" {
+		t.Errorf("LookupFilesCollection.Download requested the results in a wrong format: %s", lastResults)
+	}
+
+	e, err := col.Get("users.csv")
+	if err != nil {
+		t.Fatalf("LookupFilesCollection.Get returned an error: %s", err.Error())
+	}
+	if e.Content.Size != 42 || e.Content.ModifiedTime == "" || !strings.HasSuffix(e.Content.Path, "users.csv") {
+		t.Errorf("LookupFilesCollection.Get returned a wrong entry: %+v", e.Content)
+	}
+	if err := col.Delete("users.csv"); err != nil {
+		t.Errorf("LookupFilesCollection.Delete returned an error: %s", err.Error())
+	}
+}
+
+func TestTransformsLookupCRUD(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+form.Encode())
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"entry":[{"name":"users","content":{"filename":"users.csv","fields_list":"user,comment","match_type":"WILDCARD(user)"}}]}`)
+		case "POST":
+			fmt.Fprintf(w, `{"entry":[{"name":"users","content":{"filename":"%s"}}]}`, form.Get("filename"))
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewTransformsLookupCollection(ss)
+
+	if _, err := col.Create("users", "", nil); err == nil {
+		t.Error("TransformsLookupCollection.Create accepted an empty filename")
+	}
+	e, err := col.Create("users", "users.csv", &url.Values{"match_type": {"WILDCARD(user)"}})
+	if err != nil || e.Content.Filename != "users.csv" {
+		t.Fatalf("TransformsLookupCollection.Create returned wrong results. entry=%+v err=%v", e, err)
+	}
+	if e, err = col.Get("users"); err != nil || e.Content.FieldsList != "user,comment" || e.Content.MatchType != "WILDCARD(user)" {
+		t.Errorf("TransformsLookupCollection.Get returned wrong results. entry=%+v err=%v", e, err)
+	}
+	if err := col.Update("users", &url.Values{"max_matches": {"1"}}); err != nil {
+		t.Errorf("TransformsLookupCollection.Update returned an error: %s", err.Error())
+	}
+	if err := col.Delete("users"); err != nil {
+		t.Errorf("TransformsLookupCollection.Delete returned an error: %s", err.Error())
+	}
+	expected := []string{
+		"POST /services/data/transforms/lookups?filename=users.csv&match_type=WILDCARD%28user%29&name=users",
+		"GET /services/data/transforms/lookups/users?",
+		"POST /services/data/transforms/lookups/users?max_matches=1",
+		"DELETE /services/data/transforms/lookups/users?",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("TransformsLookupCollection sent wrong requests.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(requests, "\n"))
+	}
+}
+
+func TestLookupsLifecycle(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+	files := NewLookupFilesCollection(ss, "search")
+	transforms := NewTransformsLookupCollection(ss)
+
+	if err := files.Upload("sdk_test_lookup.csv", "user,role\nalice,admin\n"); err != nil {
+		t.Fatalf("LookupFilesCollection.Upload returned an error: %s", err.Error())
+	}
+	defer files.Delete("sdk_test_lookup.csv")
+	if _, err := transforms.Create("sdk_test_lookup", "sdk_test_lookup.csv", nil); err != nil {
+		t.Fatalf("TransformsLookupCollection.Create returned an error: %s", err.Error())
+	}
+	defer transforms.Delete("sdk_test_lookup")
+
+	content, err := files.Download("sdk_test_lookup.csv")
+	if err != nil || !strings.Contains(content, "alice") {
+		t.Errorf("LookupFilesCollection.Download returned wrong contents. content=%q err=%v", content, err)
+	}
+	if err := files.Upload("sdk_test_lookup.csv", "user,role\nbob,user\n"); err != nil {
+		t.Fatalf("LookupFilesCollection.Upload of an existing lookup returned an error: %s", err.Error())
+	}
+	if content, err = files.Download("sdk_test_lookup.csv"); err != nil || !strings.Contains(content, "bob") || strings.Contains(content, "alice") {
+		t.Errorf("LookupFilesCollection.Upload did not replace the lookup. content=%q err=%v", content, err)
+	}
+	if err := transforms.Update("sdk_test_lookup", &url.Values{"max_matches": {"1"}}); err != nil {
+		t.Errorf("TransformsLookupCollection.Update returned an error: %s", err.Error())
+	}
+}
@@ -96,7 +96,7 @@ func (col *PropertiesCollection) CreateStanza(name string, properties *url.Value
 	//var discard *discardBody
 	var params url.Values = url.Values{}
 	params.Set("__stanza", name)
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", getUrl(col.path, ""), nil, []byte(params.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", getUrl(col.path, ""), nil, []byte(params.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
 		return fmt.Errorf("%s createStanza %s: %w", col.name, name, err)
 	}
 	return col.SetProperties(name, properties)
@@ -108,7 +108,7 @@ func (col *PropertiesCollection) DeleteStanza(stanza string) error {
 		return utils.NewErrInvalidParam(col.name+" deleteProperty", nil, "stanza cannot be empty")
 	}
 
-	if err := doSplunkdHttpRequest(col.splunkd, "DELETE", getUrl(col.path, stanza), nil, nil, "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "DELETE", getUrl(col.path, stanza), nil, nil, "", &discardBody{}); err != nil {
 		return fmt.Errorf("%s deleteStanza %s: %w", col.name, stanza, err)
 	}
 	return nil
@@ -123,7 +123,7 @@ func (col *PropertiesCollection) SetProperties(stanza string, properties *url.Va
 	if properties == nil || len(*properties) == 0 {
 		return nil
 	}
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", getUrl(col.path, stanza), nil, []byte(properties.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", getUrl(col.path, stanza), nil, []byte(properties.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
 		return fmt.Errorf("%s setProperties %s: %w", col.name, stanza, err)
 	}
 	return nil
@@ -140,7 +140,7 @@ func (col *PropertiesCollection) SetProperty(stanza, propertyName, value string)
 
 	var params url.Values = url.Values{}
 	params.Set(propertyName, value)
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", getUrl(col.path, stanza), nil, []byte(params.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", getUrl(col.path, stanza), nil, []byte(params.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
 		return fmt.Errorf("%s setProperty %s/%s: %w", col.name, stanza, propertyName, err)
 	}
 	return nil
@@ -159,3 +159,83 @@ func (col *PropertiesCollection) GetProperty(stanza, propertyName string) (strin
 	}
 	return props[propertyName], nil
 }
+
+// AppendToProperty adds value to the comma-separated list of values of a list-valued property,
+// such as 'REPORT-fields = extract1, extract2', without overwriting the values already present.
+// If value is already part of the list, the property is left untouched.
+func (col *PropertiesCollection) AppendToProperty(stanza, propertyName, value string) error {
+	if value == "" {
+		return utils.NewErrInvalidParam(col.name+" appendToProperty", nil, "value cannot be empty")
+	}
+	current, err := col.GetProperty(stanza, propertyName)
+	if err != nil {
+		return fmt.Errorf("%s appendToProperty: %w", col.name, err)
+	}
+	updated, changed := appendToListValue(current, value)
+	if !changed {
+		return nil
+	}
+	if err = col.SetProperty(stanza, propertyName, updated); err != nil {
+		return fmt.Errorf("%s appendToProperty: %w", col.name, err)
+	}
+	return nil
+}
+
+// RemoveFromProperty removes value from the comma-separated list of values of a list-valued property.
+// If value is not part of the list, the property is left untouched.
+func (col *PropertiesCollection) RemoveFromProperty(stanza, propertyName, value string) error {
+	if value == "" {
+		return utils.NewErrInvalidParam(col.name+" removeFromProperty", nil, "value cannot be empty")
+	}
+	current, err := col.GetProperty(stanza, propertyName)
+	if err != nil {
+		return fmt.Errorf("%s removeFromProperty: %w", col.name, err)
+	}
+	updated, changed := removeFromListValue(current, value)
+	if !changed {
+		return nil
+	}
+	if err = col.SetProperty(stanza, propertyName, updated); err != nil {
+		return fmt.Errorf("%s removeFromProperty: %w", col.name, err)
+	}
+	return nil
+}
+
+// splitListValue splits a comma-separated property value into its trimmed, non-empty items
+func splitListValue(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// appendToListValue returns list with value appended, and whether list was modified
+func appendToListValue(list, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	items := splitListValue(list)
+	for _, item := range items {
+		if item == value {
+			return list, false
+		}
+	}
+	return strings.Join(append(items, value), ", "), true
+}
+
+// removeFromListValue returns list without value, and whether list was modified
+func removeFromListValue(list, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	items := splitListValue(list)
+	kept := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(items) {
+		return list, false
+	}
+	return strings.Join(kept, ", "), true
+}
@@ -140,3 +140,21 @@ func TestPropertyList(t *testing.T) {
 	}
 
 }
+
+func TestPropertyListValues(t *testing.T) {
+	if got, changed := appendToListValue("", "extract1"); !changed || got != "extract1" {
+		t.Errorf("appendToListValue on empty list: got='%s' changed=%v", got, changed)
+	}
+	if got, changed := appendToListValue("extract1,extract2", "extract3"); !changed || got != "extract1, extract2, extract3" {
+		t.Errorf("appendToListValue: got='%s' changed=%v", got, changed)
+	}
+	if got, changed := appendToListValue("extract1, extract2", "extract2"); changed || got != "extract1, extract2" {
+		t.Errorf("appendToListValue with existing value: got='%s' changed=%v", got, changed)
+	}
+	if got, changed := removeFromListValue("extract1, extract2, extract3", "extract2"); !changed || got != "extract1, extract3" {
+		t.Errorf("removeFromListValue: got='%s' changed=%v", got, changed)
+	}
+	if got, changed := removeFromListValue("extract1", "extract2"); changed || got != "extract1" {
+		t.Errorf("removeFromListValue with missing value: got='%s' changed=%v", got, changed)
+	}
+}
@@ -0,0 +1,54 @@
+package splunkd
+
+import (
+	"net/url"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// RoleResource represents a role, which grants capabilities and access to indexes to the users it is assigned to
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authorization.2Froles
+type RoleResource struct {
+	Capabilities []string `json:"capabilities"`
+	// ImportedRoles are the roles whose capabilities and settings are inherited by the role
+	ImportedRoles []string `json:"imported_roles"`
+	// ImportedCapabilities are the capabilities inherited from ImportedRoles
+	ImportedCapabilities []string `json:"imported_capabilities"`
+	DefaultApp           string   `json:"defaultApp"`
+	SrchIndexesAllowed   []string `json:"srchIndexesAllowed"`
+	SrchIndexesDefault   []string `json:"srchIndexesDefault"`
+	SrchFilter           string   `json:"srchFilter"`
+	SrchTimeWin          int64    `json:"srchTimeWin"`
+	SrchJobsQuota        int64    `json:"srchJobsQuota"`
+	RtSrchJobsQuota      int64    `json:"rtSrchJobsQuota"`
+	SrchDiskQuota        int64    `json:"srchDiskQuota"`
+}
+
+// RolesCollection manages the roles defined within splunkd
+type RolesCollection struct {
+	collection[RoleResource]
+}
+
+func NewRolesCollection(ss *Client) *RolesCollection {
+	var col = &RolesCollection{}
+	col.name = "roles"
+	col.path = "authorization/roles"
+	col.splunkd = ss
+	return col
+}
+
+// Create defines a new role called name. params is optional and can contain settings such as 'imported_roles', 'capabilities' or 'srchIndexesAllowed'.
+// Multi-valued settings are provided by repeating the key, e.g. &url.Values{"capabilities": {"search", "list_inputs"}}.
+func (col *RolesCollection) Create(name string, params *url.Values) (*entry[RoleResource], error) {
+	if name == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'name' cannot be empty")
+	}
+	tmpParams := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			tmpParams[k] = v
+		}
+	}
+	tmpParams.Set("name", name)
+	return col.collection.Create(name, &tmpParams)
+}
@@ -0,0 +1,63 @@
+package splunkd
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRoles(t *testing.T) {
+	srv := httptest.NewServer(newFakeAccessStore())
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	roles := ss.GetRoles()
+	if roles != ss.GetRoles() {
+		t.Error("GetRoles did not return the cached collection")
+	}
+
+	if _, err := roles.Create("", nil); err == nil {
+		t.Error("Create accepted an empty name")
+	}
+	if _, err := roles.Create("bare", nil); err != nil {
+		t.Errorf("Create without params returned an error: %s", err.Error())
+	}
+	r, err := roles.Create("analyst", &url.Values{"imported_roles": {"user"}, "capabilities": {"list_inputs", "rest_properties_get"}, "srchIndexesAllowed": {"main"}})
+	if err != nil {
+		t.Fatalf("Create returned an error: %s", err.Error())
+	}
+	if r.Name != "analyst" || strings.Join(r.Content.Capabilities, ",") != "list_inputs,rest_properties_get" || strings.Join(r.Content.ImportedRoles, ",") != "user" {
+		t.Errorf("Create returned a wrong entry: %+v", r)
+	}
+
+	list, err := roles.List()
+	if err != nil || len(list) != 3 {
+		t.Fatalf("List returned wrong results. entries=%v err=%v", list, err)
+	}
+	if r, err := roles.Get("analyst"); err != nil || strings.Join(r.Content.SrchIndexesAllowed, ",") != "main" {
+		t.Errorf("Get returned wrong results. entry=%+v err=%v", r, err)
+	}
+
+	users := ss.GetUsers()
+	if _, err := users.CreateUser("jdoe", UserResource{Password: "changeme1", Roles: []string{"user"}}); err != nil {
+		t.Fatalf("CreateUser returned an error: %s", err.Error())
+	}
+	if err := users.AddRole("jdoe", "analyst"); err != nil {
+		t.Fatalf("AddRole returned an error: %s", err.Error())
+	}
+	if u, err := users.Get("jdoe"); err != nil || strings.Join(u.Content.Roles, ",") != "user,analyst" {
+		t.Errorf("created role was not assigned. entry=%+v err=%v", u, err)
+	}
+	if err := users.Delete("jdoe"); err != nil {
+		t.Errorf("Delete of user returned an error: %s", err.Error())
+	}
+
+	for _, name := range []string{"analyst", "bare"} {
+		if err := roles.Delete(name); err != nil {
+			t.Errorf("Delete returned an error: %s", err.Error())
+		}
+	}
+	if roles.Exists("analyst") {
+		t.Error("role was not deleted")
+	}
+}
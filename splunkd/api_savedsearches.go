@@ -0,0 +1,126 @@
+package splunkd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// SavedSearchResource represents the most commonly used settings of a saved search.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#saved.2Fsearches
+type SavedSearchResource struct {
+	Search       string `json:"search"`
+	Description  string `json:"description"`
+	CronSchedule string `json:"cron_schedule"`
+	IsScheduled  bool   `json:"is_scheduled"`
+	IsVisible    bool   `json:"is_visible"`
+	Disabled     bool   `json:"disabled"`
+	// Actions is the comma-separated list of alert actions triggered by the search
+	Actions          string `json:"actions"`
+	Alert_Type       string `json:"alert_type"`
+	Alert_Comparator string `json:"alert_comparator"`
+	// CountOfResults is the threshold on the count of results which, compared with Alert_Comparator, triggers the alert
+	CountOfResults    string `json:"alert_threshold"`
+	EarliestTime      string `json:"dispatch.earliest_time"`
+	LatestTime        string `json:"dispatch.latest_time"`
+	NextScheduledTime string `json:"next_scheduled_time"`
+}
+
+type SavedSearchesCollection struct {
+	collection[SavedSearchResource]
+}
+
+func NewSavedSearchesCollection(ss *Client) *SavedSearchesCollection {
+	var col = &SavedSearchesCollection{}
+	col.name = "saved-searches"
+	col.path = "saved/searches"
+	col.splunkd = ss
+	return col
+}
+
+// NewSavedSearchesCollectionNS returns a collection of the saved searches visible within the namespace of owner and app
+func NewSavedSearchesCollectionNS(ss *Client, owner, app string) *SavedSearchesCollection {
+	ns, _ := NewNamespace(owner, app, SplunkSharingApp)
+	return newSavedSearchesCollectionNS(ss, ns)
+}
+
+// newSavedSearchesCollectionNS returns a collection of the saved searches visible within namespace ns
+func newSavedSearchesCollectionNS(ss *Client, ns *Namespace) *SavedSearchesCollection {
+	var col = &SavedSearchesCollection{}
+	col.name = "saved-searches"
+	col.path = getUrlNS(ns, "saved/searches")
+	col.splunkd = ss
+	return col
+}
+
+// Create creates a new saved search called name, executing the search string.
+// Further settings, such as cron_schedule or actions, can be provided with params. If ns is nil, the search is created in the global context.
+func (col *SavedSearchesCollection) Create(ns *Namespace, name string, search string, params *url.Values) (*entry[SavedSearchResource], error) {
+	if search == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'search' cannot be empty")
+	}
+	tmpParams := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			tmpParams[k] = v
+		}
+	}
+	tmpParams.Set("search", search)
+	tmpParams.Set("name", name)
+	if ns != nil {
+		return col.collection.CreateNS(ns, name, &tmpParams)
+	}
+	return col.collection.Create(name, &tmpParams)
+}
+
+// Enable enables the saved search called name
+func (col *SavedSearchesCollection) Enable(name string) error {
+	return col.postAction(name, "enable")
+}
+
+// Disable disables the saved search called name
+func (col *SavedSearchesCollection) Disable(name string) error {
+	return col.postAction(name, "disable")
+}
+
+// postAction invokes one of the action endpoints available for saved searches, such as /enable or /disable
+func (col *SavedSearchesCollection) postAction(name, action string) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" "+action, nil, "'name' cannot be empty")
+	}
+	fullUrl := getUrl(col.path, name) + "/" + action
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s %s '%s': %w", col.name, action, name, err)
+	}
+	return nil
+}
+
+// Dispatch starts a new execution of the saved search called name and returns the sid of the resulting search job.
+// params can be used to override dispatch settings, e.g. dispatch.earliest_time, or to set tokens with args.<token>
+func (col *SavedSearchesCollection) Dispatch(name string, params *url.Values) (string, error) {
+	if err := col.isInitialized(); err != nil {
+		return "", fmt.Errorf("dispatch: %w", err)
+	}
+	if name == "" {
+		return "", utils.NewErrInvalidParam(col.name+" dispatch", nil, "'name' cannot be empty")
+	}
+	var body []byte
+	if params != nil {
+		body = []byte(params.Encode())
+	}
+	fullUrl := getUrl(col.path, name) + "/dispatch"
+	resp := struct {
+		Sid string `json:"sid"`
+	}{}
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, body, "", &resp); err != nil {
+		return "", fmt.Errorf("%s dispatch '%s': %w", col.name, name, err)
+	}
+	if resp.Sid == "" {
+		return "", fmt.Errorf("%s dispatch '%s': no sid returned by splunkd", col.name, name)
+	}
+	return resp.Sid, nil
+}
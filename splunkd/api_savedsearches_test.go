@@ -0,0 +1,109 @@
+package splunkd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSavedSearchesCRUD(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Create and Update do not set a content-type, so the body is parsed explicitly
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+form.Encode())
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/saved/searches"):
+			fmt.Fprint(w, `{"paging":{"total":1,"offset":0},"entry":[{"name":"my search","content":{"search":"index=main","is_scheduled":true}}]}`)
+		case r.Method == "GET":
+			fmt.Fprint(w, `{"entry":[{"name":"my search","content":{"search":"index=main","cron_schedule":"*/5 * * * *","is_scheduled":true,"is_visible":true,"actions":"email, webhook","alert_comparator":"greater than","alert_threshold":"10"}}]}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/dispatch"):
+			fmt.Fprint(w, `{"sid":"admin__admin__search__my_search_at_1700000000_1"}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/saved/searches"):
+			fmt.Fprintf(w, `{"entry":[{"name":"%s","content":{"search":"%s"}}]}`, form.Get("name"), form.Get("search"))
+		default:
+			fmt.Fprint(w, `{"entry":[]}`)
+		}
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewSavedSearchesCollectionNS(ss, "admin", "search")
+
+	if _, err := col.Create(nil, "my search", "", nil); err == nil {
+		t.Error("SavedSearchesCollection.Create accepted an empty search")
+	}
+	ns, _ := NewNamespace("admin", "search", SplunkSharingApp)
+	e, err := col.Create(ns, "my search", "index=main", &url.Values{"cron_schedule": {"*/5 * * * *"}})
+	if err != nil {
+		t.Fatalf("SavedSearchesCollection.Create returned an error: %s", err.Error())
+	}
+	if e.Name != "my search" || e.Content.Search != "index=main" {
+		t.Errorf("SavedSearchesCollection.Create returned a wrong entry: %+v", e)
+	}
+	if !strings.Contains(requests[0], "cron_schedule=") || !strings.Contains(requests[0], "search=index%3Dmain") {
+		t.Errorf("SavedSearchesCollection.Create sent a wrong request: %s", requests[0])
+	}
+
+	e, err = col.Get("my search")
+	if err != nil {
+		t.Fatalf("SavedSearchesCollection.Get returned an error: %s", err.Error())
+	}
+	if e.Content.CronSchedule != "*/5 * * * *" || !e.Content.IsScheduled || !e.Content.IsVisible || e.Content.Actions != "email, webhook" || e.Content.Alert_Comparator != "greater than" || e.Content.CountOfResults != "10" {
+		t.Errorf("SavedSearchesCollection.Get returned a wrong entry: %+v", e.Content)
+	}
+	if entries, err := col.List(); err != nil || len(entries) != 1 {
+		t.Errorf("SavedSearchesCollection.List returned wrong results. entries=%d err=%v", len(entries), err)
+	}
+
+	requests = nil
+	if err := col.Update("my search", &url.Values{"is_scheduled": {"0"}}); err != nil {
+		t.Errorf("SavedSearchesCollection.Update returned an error: %s", err.Error())
+	}
+	if err := col.Disable("my search"); err != nil {
+		t.Errorf("SavedSearchesCollection.Disable returned an error: %s", err.Error())
+	}
+	if err := col.Enable("my search"); err != nil {
+		t.Errorf("SavedSearchesCollection.Enable returned an error: %s", err.Error())
+	}
+	if err := col.Delete("my search"); err != nil {
+		t.Errorf("SavedSearchesCollection.Delete returned an error: %s", err.Error())
+	}
+	expected := []string{
+		"POST /servicesNS/admin/search/saved/searches/my search?is_scheduled=0",
+		"POST /servicesNS/admin/search/saved/searches/my search/disable?",
+		"POST /servicesNS/admin/search/saved/searches/my search/enable?",
+		"DELETE /servicesNS/admin/search/saved/searches/my search?",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("SavedSearchesCollection sent wrong requests.\nexpected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(requests, "\n"))
+	}
+
+	sid, err := col.Dispatch("my search", &url.Values{"dispatch.earliest_time": {"-1h"}})
+	if err != nil || sid != "admin__admin__search__my_search_at_1700000000_1" {
+		t.Errorf("SavedSearchesCollection.Dispatch returned wrong results. sid=%s err=%v", sid, err)
+	}
+}
+
+func TestGetSavedSearchesNamespace(t *testing.T) {
+	ss := &Client{}
+	ss.SetNamespace("nobody", "search", SplunkSharingApp)
+	searches := ss.GetSavedSearches()
+	if ss.GetSavedSearches() != searches {
+		t.Error("GetSavedSearches did not return the cached collection")
+	}
+	ss.SetNamespace("admin", "myapp", SplunkSharingUser)
+	if col := ss.GetSavedSearches(); col == searches || col.path != "/servicesNS/admin/myapp/saved/searches" {
+		t.Errorf("GetSavedSearches returned a stale collection after SetNamespace. path=%s", col.path)
+	}
+	ns, _ := NewNamespace("nobody", "search", SplunkSharingApp)
+	ss.SetNamespaceFromNS(*ns)
+	if ss.GetSavedSearches() != searches {
+		t.Error("GetSavedSearches did not return the cached collection of the namespace")
+	}
+}
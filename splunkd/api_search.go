@@ -0,0 +1,371 @@
+package splunkd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+const (
+	// interval between two checks of the status of a search job performed by SearchJob.Wait
+	searchJobPollInterval = 500 * time.Millisecond
+	// number of results retrieved with each request when paginating results and events of a search job
+	searchJobPageSize = 1000
+	// maximum duration of the request cancelling the job of OneShot
+	searchJobCancelTimeout = 10 * time.Second
+)
+
+// SearchJobResource represents the status of a search job.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D
+type SearchJobResource struct {
+	Sid           string  `json:"sid"`
+	DispatchState string  `json:"dispatchState"`
+	IsDone        bool    `json:"isDone"`
+	IsFailed      bool    `json:"isFailed"`
+	IsFinalized   bool    `json:"isFinalized"`
+	DoneProgress  float64 `json:"doneProgress"`
+	EventCount    int     `json:"eventCount"`
+	ResultCount   int     `json:"resultCount"`
+	RunDuration   float64 `json:"runDuration"`
+	Messages      []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"messages"`
+}
+
+// SearchJob is a search being executed by splunkd
+type SearchJob struct {
+	splunkd *Client
+	sid     string
+	// path of the job within the search/jobs endpoint
+	path string
+	// status of the job, as retrieved by the most recent Refresh()
+	status SearchJobResource
+}
+
+// NewSearchJob starts a new asynchronous search job for search.
+// If search does not begin with a generating command ("|") or the "search" command, "search " is prepended to it.
+// params can provide further settings of the job, such as earliest_time, latest_time, max_count.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs
+func (ss *Client) NewSearchJob(search string, params *url.Values) (*SearchJob, error) {
+	return ss.newSearchJobNS(&ss.nameSpace, search, params)
+}
+
+// newSearchJobNS starts a new search job within namespace ns
+func (ss *Client) newSearchJobNS(ns *Namespace, search string, params *url.Values) (*SearchJob, error) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return nil, utils.NewErrInvalidParam("newSearchJob", nil, "'search' cannot be empty")
+	}
+	if !strings.HasPrefix(search, "|") && !strings.HasPrefix(search, "search ") {
+		search = "search " + search
+	}
+	body := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			body[k] = v
+		}
+	}
+	body.Set("search", search)
+
+	jobsPath := getUrlNS(ns, "search/jobs")
+	resp := struct {
+		Sid string `json:"sid"`
+	}{}
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "POST", jobsPath, nil, []byte(body.Encode()), "", &resp); err != nil {
+		return nil, fmt.Errorf("newSearchJob: %w", err)
+	}
+	if resp.Sid == "" {
+		return nil, fmt.Errorf("newSearchJob: no sid returned by splunkd")
+	}
+	jobPath, _ := url.JoinPath(jobsPath, resp.Sid)
+	return &SearchJob{splunkd: ss, sid: resp.Sid, path: jobPath}, nil
+}
+
+// GetSearchJob returns the search job identified by sid, e.g. to retrieve the results of a job started by a previous process
+func (ss *Client) GetSearchJob(sid string) *SearchJob {
+	jobPath, _ := url.JoinPath(getUrlNS(&ss.nameSpace, "search/jobs"), sid)
+	return &SearchJob{splunkd: ss, sid: sid, path: jobPath}
+}
+
+// OneShot runs search, waits for its completion and returns all of its results.
+// The job is cancelled once its results have been read, or if ctx is cancelled before it completes, so that splunkd can release them.
+func (ss *Client) OneShot(ctx context.Context, search string, params *url.Values) ([]map[string]interface{}, error) {
+	job, err := ss.NewSearchJob(search, params)
+	if err != nil {
+		return nil, fmt.Errorf("oneShot: %w", err)
+	}
+	defer func() {
+		// a fresh context is needed, as ctx might have expired already
+		cancelCtx, cancel := context.WithTimeout(context.Background(), searchJobCancelTimeout)
+		defer cancel()
+		job.CancelContext(cancelCtx)
+	}()
+	if err := job.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("oneShot: %w", err)
+	}
+	res, err := job.Results(nil)
+	if err != nil {
+		return nil, fmt.Errorf("oneShot: %w", err)
+	}
+	return res, nil
+}
+
+// GetSid returns the search id of the job
+func (job *SearchJob) GetSid() string {
+	return job.sid
+}
+
+// Status returns the status of the job as retrieved by the most recent Refresh, IsDone or Wait
+func (job *SearchJob) Status() SearchJobResource {
+	return job.status
+}
+
+// Refresh retrieves the current status of the job from splunkd
+func (job *SearchJob) Refresh() error {
+	tmpCol := collection[SearchJobResource]{}
+	if err := doSplunkdHttpRequest(job.splunkd.getContext(), job.splunkd, "GET", job.path, nil, nil, "", &tmpCol); err != nil {
+		return fmt.Errorf("searchJob refresh '%s': %w", job.sid, err)
+	}
+	if len(tmpCol.Entries) == 0 {
+		return fmt.Errorf("searchJob refresh '%s': no status returned by splunkd", job.sid)
+	}
+	job.status = tmpCol.Entries[0].Content
+	return nil
+}
+
+// IsDone refreshes the status of the job and reports whether it has completed
+func (job *SearchJob) IsDone() (bool, error) {
+	if err := job.Refresh(); err != nil {
+		return false, err
+	}
+	return job.status.IsDone, nil
+}
+
+// Wait polls the status of the job until it completes, or ctx is cancelled.
+// An error is returned if the job failed.
+func (job *SearchJob) Wait(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ticker := time.NewTicker(searchJobPollInterval)
+	defer ticker.Stop()
+	for {
+		done, err := job.IsDone()
+		if err != nil {
+			return err
+		}
+		if job.status.IsFailed || job.status.DispatchState == "FAILED" {
+			msgs := make([]string, len(job.status.Messages))
+			for i, m := range job.status.Messages {
+				msgs[i] = m.Text
+			}
+			return fmt.Errorf("searchJob '%s' failed: %s", job.sid, strings.Join(msgs, "; "))
+		}
+		if done {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("searchJob '%s' wait: %w", job.sid, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Cancel stops the job and deletes its results
+func (job *SearchJob) Cancel() error {
+	return job.CancelContext(job.splunkd.getContext())
+}
+
+// CancelContext is the same as Cancel, performing the request with ctx instead of the context of the client.
+// This allows cancelling a job after the context of the client has been cancelled, e.g. once a timeout expired.
+func (job *SearchJob) CancelContext(ctx context.Context) error {
+	body := url.Values{}
+	body.Set("action", "cancel")
+	if err := doSplunkdHttpRequest(ctx, job.splunkd, "POST", job.path+"/control", nil, []byte(body.Encode()), "", &discardBody{}); err != nil {
+		return fmt.Errorf("searchJob cancel '%s': %w", job.sid, err)
+	}
+	return nil
+}
+
+// Results returns the results of the job. All results are retrieved, page by page,
+// unless params provide 'offset' and/or 'count' to restrict them. Other params, such as 'search', are passed as-is to splunkd.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D.2Fresults
+func (job *SearchJob) Results(params *url.Values) ([]map[string]interface{}, error) {
+	return job.fetchRows("results", params)
+}
+
+// Events returns the events of the job, with the same pagination behavior of Results.
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTsearch#search.2Fjobs.2F.7Bsearch_id.7D.2Fevents
+func (job *SearchJob) Events(params *url.Values) ([]map[string]interface{}, error) {
+	return job.fetchRows("events", params)
+}
+
+// fetchRows retrieves the rows of the results or events endpoint, decoding them as untyped maps
+func (job *SearchJob) fetchRows(endpoint string, params *url.Values) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	err := job.forEachRow(endpoint, params, func(raw json.RawMessage) error {
+		row := map[string]interface{}{}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	return rows, err
+}
+
+// forEachRow retrieves the rows of the results or events endpoint page by page, invoking fn on each of them.
+// 'offset' and 'count' within params define the window of rows to be retrieved; count=0 or no count means all rows.
+func (job *SearchJob) forEachRow(endpoint string, params *url.Values, fn func(json.RawMessage) error) error {
+	reqParams := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			reqParams[k] = v
+		}
+	}
+	offset, _ := strconv.Atoi(reqParams.Get("offset"))
+	limit, _ := strconv.Atoi(reqParams.Get("count"))
+
+	fetched := 0
+	for limit <= 0 || fetched < limit {
+		pageSize := searchJobPageSize
+		if limit > 0 && limit-fetched < pageSize {
+			pageSize = limit - fetched
+		}
+		reqParams.Set("offset", strconv.Itoa(offset+fetched))
+		reqParams.Set("count", strconv.Itoa(pageSize))
+		page := struct {
+			Results []json.RawMessage `json:"results"`
+		}{}
+		if err := doSplunkdHttpRequest(job.splunkd.getContext(), job.splunkd, "GET", job.path+"/"+endpoint, &reqParams, nil, "", &page); err != nil {
+			return fmt.Errorf("searchJob %s '%s': %w", endpoint, job.sid, err)
+		}
+		for _, raw := range page.Results {
+			if err := fn(raw); err != nil {
+				return fmt.Errorf("searchJob %s '%s': %w", endpoint, job.sid, err)
+			}
+		}
+		fetched += len(page.Results)
+		if len(page.Results) < pageSize {
+			break
+		}
+	}
+	return nil
+}
+
+// GetResultsTyped returns count results of job starting at offset, unmarshalling each of them into T.
+// Use count=0 to retrieve all the results. T is generally a struct with `json:"fieldname"` tags matching the splunk field names:
+//
+//	type Row struct {
+//		Time  time.Time `json:"_time"`
+//		Raw   string    `json:"_raw"`
+//		Host  string    `json:"host"`
+//		Count int       `json:"count,string"`
+//		Si    []string  `json:"_si"`
+//	}
+//
+// Splunk returns multi-value fields, such as _si and _bkt, as arrays, and single values as strings.
+// Arrays are joined by newlines when the corresponding field of T is a string, and single values are wrapped into
+// a one-element array when the field of T is a slice.
+func GetResultsTyped[T any](job *SearchJob, offset, count int) ([]T, error) {
+	if job == nil {
+		return nil, utils.NewErrInvalidParam("getResultsTyped", nil, "'job' cannot be nil")
+	}
+	if offset < 0 || count < 0 {
+		return nil, utils.NewErrInvalidParam("getResultsTyped", nil, "'offset' and 'count' cannot be negative")
+	}
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("count", strconv.Itoa(count))
+
+	kinds := jsonFieldKinds(reflect.TypeOf((*T)(nil)).Elem())
+	var rows []T
+	err := job.forEachRow("results", &params, func(raw json.RawMessage) error {
+		if len(kinds) > 0 {
+			var err error
+			if raw, err = normalizeRow(raw, kinds); err != nil {
+				return err
+			}
+		}
+		var row T
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getResultsTyped: %w", err)
+	}
+	return rows, nil
+}
+
+// jsonFieldKinds maps the JSON names of the fields of struct type t to their kind. Returns nil if t is not a struct.
+func jsonFieldKinds(t reflect.Type) map[string]reflect.Kind {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	kinds := make(map[string]reflect.Kind, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n, _, _ := strings.Cut(tag, ","); n != "" {
+				name = n
+			}
+		}
+		kind := f.Type.Kind()
+		if kind == reflect.Slice && f.Type.Elem().Kind() == reflect.Uint8 {
+			// []byte is unmarshalled from a base64 string
+			kind = reflect.String
+		}
+		kinds[name] = kind
+	}
+	return kinds
+}
+
+// normalizeRow converts the multi-value and single-value fields of raw to match the kinds of the corresponding target fields
+func normalizeRow(raw json.RawMessage, kinds map[string]reflect.Kind) (json.RawMessage, error) {
+	row := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &row); err != nil {
+		return nil, err
+	}
+	changed := false
+	for k, v := range row {
+		kind, found := kinds[k]
+		if !found || len(v) == 0 {
+			continue
+		}
+		switch {
+		case kind == reflect.String && v[0] == '[':
+			var values []string
+			if err := json.Unmarshal(v, &values); err != nil {
+				return nil, fmt.Errorf("field '%s': %w", k, err)
+			}
+			row[k], _ = json.Marshal(strings.Join(values, "\n"))
+			changed = true
+		case kind == reflect.Slice && v[0] != '[' && string(v) != "null":
+			row[k] = append(append(json.RawMessage{'['}, v...), ']')
+			changed = true
+		}
+	}
+	if !changed {
+		return raw, nil
+	}
+	return json.Marshal(row)
+}
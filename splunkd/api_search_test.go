@@ -0,0 +1,184 @@
+package splunkd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSearchServer emulates the search/jobs endpoints for a job returning numResults results
+func newTestSearchServer(t *testing.T, numResults int, pollsBeforeDone int) (*httptest.Server, *[]string) {
+	var requests []string
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/search/jobs"):
+			if !strings.HasPrefix(form.Get("search"), "search ") && !strings.HasPrefix(form.Get("search"), "|") {
+				t.Errorf("search job created with a search without command: %s", form.Get("search"))
+			}
+			fmt.Fprint(w, `{"sid":"1700000000.1"}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/control"):
+			fmt.Fprint(w, `{}`)
+		case strings.HasSuffix(r.URL.Path, "/search/jobs/1700000000.1"):
+			polls++
+			done := polls > pollsBeforeDone
+			fmt.Fprintf(w, `{"entry":[{"name":"1700000000.1","content":{"sid":"1700000000.1","isDone":%t,"dispatchState":"RUNNING","resultCount":%d}}]}`, done, numResults)
+		case strings.HasSuffix(r.URL.Path, "/results") || strings.HasSuffix(r.URL.Path, "/events"):
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+			results := []map[string]interface{}{}
+			for i := offset; i < numResults && i < offset+count; i++ {
+				results = append(results, map[string]interface{}{
+					"_time": "2023-11-14T22:13:20.000+00:00",
+					"_raw":  "event " + strconv.Itoa(i),
+					"_si":   []string{"idx-1", "main"},
+					"_bkt":  "main~1~ABC",
+					"count": strconv.Itoa(i),
+					"host":  []string{"h1", "h2"},
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, &requests
+}
+
+func TestSearchJobResultsPagination(t *testing.T) {
+	srv, requests := newTestSearchServer(t, searchJobPageSize+5, 1)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+
+	if _, err := ss.NewSearchJob("  ", nil); err == nil {
+		t.Error("NewSearchJob accepted an empty search")
+	}
+	job, err := ss.NewSearchJob("index=main", nil)
+	if err != nil {
+		t.Fatalf("NewSearchJob returned an error: %s", err.Error())
+	}
+	if job.GetSid() != "1700000000.1" {
+		t.Errorf("SearchJob.GetSid returned a wrong sid: %s", job.GetSid())
+	}
+	if err := job.Wait(context.Background()); err != nil {
+		t.Fatalf("SearchJob.Wait returned an error: %s", err.Error())
+	}
+	results, err := job.Results(nil)
+	if err != nil {
+		t.Fatalf("SearchJob.Results returned an error: %s", err.Error())
+	}
+	if len(results) != searchJobPageSize+5 {
+		t.Errorf("SearchJob.Results did not paginate. expected=%d got=%d", searchJobPageSize+5, len(results))
+	}
+	events, err := job.Events(&url.Values{"offset": {"3"}, "count": {"4"}})
+	if err != nil || len(events) != 4 || events[0]["_raw"] != "event 3" {
+		t.Errorf("SearchJob.Events did not respect offset and count. events=%d err=%v", len(events), err)
+	}
+	if err := job.Cancel(); err != nil {
+		t.Errorf("SearchJob.Cancel returned an error: %s", err.Error())
+	}
+	if last := (*requests)[len(*requests)-1]; !strings.HasSuffix(last, "/search/jobs/1700000000.1/control") {
+		t.Errorf("SearchJob.Cancel sent a wrong request: %s", last)
+	}
+}
+
+func TestSearchJobOneShotCancelled(t *testing.T) {
+	srv, requests := newTestSearchServer(t, 1, 1000)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := ss.OneShot(ctx, "| makeresults", nil); err == nil {
+		t.Fatal("OneShot did not return an error when the context expired")
+	}
+	if last := (*requests)[len(*requests)-1]; !strings.HasSuffix(last, "/control") {
+		t.Errorf("OneShot did not cancel the job when the context expired. last request: %s", last)
+	}
+}
+
+func TestSearchJobOneShotCancelsJob(t *testing.T) {
+	srv, requests := newTestSearchServer(t, 3, 0)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+
+	results, err := ss.OneShot(context.Background(), "| makeresults count=3", nil)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("OneShot returned wrong results. results=%d err=%v", len(results), err)
+	}
+	if last := (*requests)[len(*requests)-1]; !strings.HasSuffix(last, "/control") || !strings.HasSuffix((*requests)[len(*requests)-2], "/results") {
+		t.Errorf("OneShot did not cancel the job once its results had been read. requests: %v", *requests)
+	}
+}
+
+func TestGetResultsTyped(t *testing.T) {
+	srv, _ := newTestSearchServer(t, 3, 0)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+
+	type row struct {
+		Time  time.Time `json:"_time"`
+		Raw   string    `json:"_raw"`
+		Si    []string  `json:"_si"`
+		Bkt   []string  `json:"_bkt"`
+		Count int       `json:"count,string"`
+		Host  string    `json:"host"`
+	}
+	rows, err := GetResultsTyped[row](ss.GetSearchJob("1700000000.1"), 1, 0)
+	if err != nil {
+		t.Fatalf("GetResultsTyped returned an error: %s", err.Error())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("GetResultsTyped returned a wrong number of rows. expected=2 got=%d", len(rows))
+	}
+	r := rows[0]
+	if r.Raw != "event 1" || r.Count != 1 || r.Time.Unix() != 1700000000 {
+		t.Errorf("GetResultsTyped returned a wrong row: %+v", r)
+	}
+	if len(r.Si) != 2 || r.Si[1] != "main" || len(r.Bkt) != 1 || r.Bkt[0] != "main~1~ABC" {
+		t.Errorf("GetResultsTyped did not manage the _si and _bkt fields: %+v", r)
+	}
+	if r.Host != "h1\nh2" {
+		t.Errorf("GetResultsTyped did not join a multi-value field into a string field: %q", r.Host)
+	}
+
+	maps, err := GetResultsTyped[map[string]interface{}](ss.GetSearchJob("1700000000.1"), 0, 1)
+	if err != nil || len(maps) != 1 || maps[0]["_raw"] != "event 0" {
+		t.Errorf("GetResultsTyped into maps returned wrong results. rows=%v err=%v", maps, err)
+	}
+}
+
+func TestSearchJobOneShot(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+
+	results, err := ss.OneShot(context.Background(), "| makeresults count=5 | streamstats count", nil)
+	if err != nil {
+		t.Fatalf("OneShot returned an error: %s", err.Error())
+	}
+	if len(results) != 5 {
+		t.Errorf("OneShot returned a wrong number of results. expected=5 got=%d", len(results))
+	}
+
+	job, err := ss.NewSearchJob("| makeresults count=2500", nil)
+	if err != nil {
+		t.Fatalf("NewSearchJob returned an error: %s", err.Error())
+	}
+	if err := job.Wait(context.Background()); err != nil {
+		t.Fatalf("SearchJob.Wait returned an error: %s", err.Error())
+	}
+	if results, err = job.Results(nil); err != nil || len(results) != 2500 {
+		t.Errorf("SearchJob.Results returned wrong results. expected=2500 got=%d err=%v", len(results), err)
+	}
+	job.Cancel()
+}
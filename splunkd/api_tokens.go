@@ -0,0 +1,161 @@
+package splunkd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// This file provides structs used to parse the JSON-formatted output of the Splunk REST API for the authentication tokens
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTaccess#authorization.2Ftokens
+
+// TokenResource represents an authentication token, to be used with Client.LoginWithToken
+type TokenResource struct {
+	// Id identifies the token within splunkd
+	Id string `json:"id"`
+	// Token is the cleartext value of the token. splunkd only provides it when creating the token
+	Token string `json:"token"`
+	// Name is the audience of the token, describing what it is used for
+	Name string `json:"-"`
+	// Username is the user the token authenticates as
+	Username string `json:"-"`
+	// CreatedBy is the issuer of the token, in form '<user> from <host>'
+	CreatedBy string    `json:"-"`
+	NotBefore time.Time `json:"-"`
+	// ExpiresOn is zero for tokens which do not expire
+	ExpiresOn time.Time `json:"-"`
+	// Status is either 'enabled' or 'disabled'
+	Status string `json:"status"`
+	Claims struct {
+		Audience  string `json:"aud"`
+		Subject   string `json:"sub"`
+		Issuer    string `json:"iss"`
+		ExpiresOn int64  `json:"exp"`
+		NotBefore int64  `json:"nbf"`
+		IssuedAt  int64  `json:"iat"`
+	} `json:"claims"`
+}
+
+// UnmarshalJSON populates the fields of the token from the claims returned by splunkd
+func (tr *TokenResource) UnmarshalJSON(data []byte) error {
+	type tokenResource TokenResource
+	var tmp tokenResource
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	*tr = TokenResource(tmp)
+	tr.Name = tr.Claims.Audience
+	tr.Username = tr.Claims.Subject
+	tr.CreatedBy = tr.Claims.Issuer
+	if tr.Claims.NotBefore > 0 {
+		tr.NotBefore = time.Unix(tr.Claims.NotBefore, 0)
+	}
+	if tr.Claims.ExpiresOn > 0 {
+		tr.ExpiresOn = time.Unix(tr.Claims.ExpiresOn, 0)
+	}
+	return nil
+}
+
+type TokensCollection struct {
+	collection[TokenResource]
+}
+
+func NewTokensCollection(ss *Client) *TokensCollection {
+	var col = &TokensCollection{}
+	col.name = "tokens"
+	col.path = "authorization/tokens"
+	col.splunkd = ss
+	return col
+}
+
+// Create creates a token for the logged-in user. name is the audience of the token, describing what it is used for.
+// expiration is either relative, such as '+30d', or an epoch timestamp. If empty, the token does not expire.
+// The cleartext value of the token is available within Content.Token of the returned entry: splunkd does not provide it anymore afterwards.
+func (col *TokensCollection) Create(name, expiration string) (*entry[TokenResource], error) {
+	if name == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'name' cannot be empty")
+	}
+	username, err := col.splunkd.Username()
+	if err != nil {
+		return nil, fmt.Errorf("%s create: %w", col.name, err)
+	}
+	params := url.Values{}
+	params.Set("name", username)
+	params.Set("audience", name)
+	if expiration != "" {
+		params.Set("expires_on", expiration)
+	}
+	e, err := col.collection.Create(name, &params)
+	if err != nil {
+		return nil, err
+	}
+	// upon creation, splunkd only returns the id and the value of the token
+	e.Content.Name = name
+	e.Content.Username = username
+	return e, nil
+}
+
+// List returns the tokens visible to the logged-in user
+func (col *TokensCollection) List() ([]entry[TokenResource], error) {
+	tokens, err := col.collection.List()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		// the id of listed tokens is the name of the entry
+		if tokens[i].Content.Id == "" {
+			tokens[i].Content.Id = tokens[i].Name
+		}
+	}
+	return tokens, nil
+}
+
+// Get returns the token identified by id.
+// splunkd does not provide an endpoint returning a single token, so this searches the tokens returned by List.
+func (col *TokensCollection) Get(id string) (*entry[TokenResource], error) {
+	if id == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" get", nil, "'id' cannot be empty")
+	}
+	tokens, err := col.List()
+	if err != nil {
+		return nil, fmt.Errorf("%s get: %w", col.name, err)
+	}
+	for i := range tokens {
+		if tokens[i].Content.Id == id {
+			return &tokens[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s get: token not found. id=\"%s\"", col.name, id)
+}
+
+// Delete revokes the token identified by id
+func (col *TokensCollection) Delete(id string) error {
+	token, err := col.Get(id)
+	if err != nil {
+		return fmt.Errorf("%s delete: %w", col.name, err)
+	}
+	params := url.Values{}
+	params.Set("id", id)
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "DELETE", getUrl(col.path, token.Content.Username), &params, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s delete: %w", col.name, err)
+	}
+	return nil
+}
+
+// Disable disables the token identified by id, without revoking it
+func (col *TokensCollection) Disable(id string) error {
+	token, err := col.Get(id)
+	if err != nil {
+		return fmt.Errorf("%s disable: %w", col.name, err)
+	}
+	params := url.Values{}
+	params.Set("id", id)
+	params.Set("status", "disabled")
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", getUrl(col.path, token.Content.Username), nil, []byte(params.Encode()), "", &discardBody{}); err != nil {
+		return fmt.Errorf("%s disable: %w", col.name, err)
+	}
+	return nil
+}
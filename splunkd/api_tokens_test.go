@@ -0,0 +1,155 @@
+package splunkd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTokenStore emulates the authorization/tokens endpoint of splunkd, as well as token-based authentication
+type fakeTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]*fakeToken
+}
+
+type fakeToken struct {
+	id, value, audience, status string
+	exp                         int64
+}
+
+func (fs *fakeTokenStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	auth := r.Header.Get("Authorization")
+	if strings.HasPrefix(auth, "Bearer ") {
+		authenticated := false
+		for _, tok := range fs.tokens {
+			if "Bearer "+tok.value == auth && tok.status == "enabled" {
+				authenticated = true
+			}
+		}
+		if !authenticated {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`)
+			return
+		}
+	}
+	// the SDK does not set a content-type, so the form must be parsed explicitly
+	body, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+	switch {
+	case r.URL.Path == "/services/authentication/current-context":
+		fmt.Fprint(w, `{"entry":[{"name":"svc","content":{"username":"svc"}}]}`)
+	case r.URL.Path == "/services/authorization/tokens" && r.Method == "POST":
+		tok := &fakeToken{id: fmt.Sprintf("id%d", len(fs.tokens)+1), audience: form.Get("audience"), status: "enabled"}
+		tok.value = "secret-" + tok.id
+		if form.Get("expires_on") == "+30d" {
+			tok.exp = time.Now().Add(30 * 24 * time.Hour).Unix()
+		}
+		if form.Get("name") != "svc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fs.tokens[tok.id] = tok
+		fmt.Fprintf(w, `{"entry":[{"name":"tokens","content":{"id":"%s","token":"%s"}}]}`, tok.id, tok.value)
+	case r.URL.Path == "/services/authorization/tokens" && r.Method == "GET":
+		entries := make([]map[string]interface{}, 0)
+		for _, tok := range fs.tokens {
+			entries = append(entries, map[string]interface{}{
+				"name": tok.id,
+				"content": map[string]interface{}{
+					"status": tok.status,
+					"claims": map[string]interface{}{"aud": tok.audience, "sub": "svc", "iss": "svc from sh1", "exp": tok.exp, "nbf": 1700000000},
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"paging": map[string]int{"total": len(entries)}, "entry": entries})
+	case r.URL.Path == "/services/authorization/tokens/svc":
+		id := r.URL.Query().Get("id")
+		if r.Method == "POST" {
+			id = form.Get("id")
+		}
+		tok, found := fs.tokens[id]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "DELETE" {
+			delete(fs.tokens, id)
+		} else if form.Get("status") != "" {
+			tok.status = form.Get("status")
+		}
+		fmt.Fprint(w, `{"entry":[]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	srv := httptest.NewServer(&fakeTokenStore{tokens: map[string]*fakeToken{}})
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	tokens := ss.GetTokens()
+	if tokens != ss.GetTokens() {
+		t.Error("GetTokens did not return the cached collection")
+	}
+
+	if _, err := tokens.Create("", "+30d"); err == nil {
+		t.Error("Create accepted an empty name")
+	}
+	created, err := tokens.Create("ci-pipeline", "+30d")
+	if err != nil {
+		t.Fatalf("Create returned an error: %s", err.Error())
+	}
+	if created.Content.Token == "" || created.Content.Id == "" || created.Content.Username != "svc" || created.Content.Name != "ci-pipeline" {
+		t.Fatalf("Create did not return the token: %+v", created.Content)
+	}
+
+	list, err := tokens.List()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List did not return the created token. tokens=%v err=%v", list, err)
+	}
+	tok := list[0].Content
+	if tok.Id != created.Content.Id || tok.Name != "ci-pipeline" || tok.Username != "svc" || tok.CreatedBy != "svc from sh1" || tok.Status != "enabled" {
+		t.Errorf("wrong token listed: %+v", tok)
+	}
+	if tok.NotBefore.Unix() != 1700000000 || time.Until(tok.ExpiresOn) < 29*24*time.Hour {
+		t.Errorf("wrong validity of the listed token. notBefore=%s expiresOn=%s", tok.NotBefore, tok.ExpiresOn)
+	}
+	if _, err := tokens.Get("missing"); err == nil {
+		t.Error("Get did not fail for a missing token")
+	}
+
+	// the token can be used for authentication
+	tokenClient := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	if err := tokenClient.LoginWithToken(created.Content.Token); err != nil {
+		t.Fatalf("LoginWithToken failed with the created token: %s", err.Error())
+	}
+	if u, err := tokenClient.Username(); err != nil || u != "svc" {
+		t.Errorf("token-authenticated client has the wrong user. username=%s err=%v", u, err)
+	}
+
+	if err := tokens.Disable(created.Content.Id); err != nil {
+		t.Fatalf("Disable returned an error: %s", err.Error())
+	}
+	if e, _ := tokens.Get(created.Content.Id); e == nil || e.Content.Status != "disabled" {
+		t.Errorf("token was not disabled: %+v", e)
+	}
+	if err := (&Client{baseUrl: srv.URL, httpClient: srv.Client()}).LoginWithToken(created.Content.Token); err == nil {
+		t.Error("LoginWithToken succeeded with a disabled token")
+	}
+
+	if err := tokens.Delete(created.Content.Id); err != nil {
+		t.Fatalf("Delete returned an error: %s", err.Error())
+	}
+	if list, err := tokens.List(); err != nil || len(list) != 0 {
+		t.Errorf("token was not revoked. tokens=%v err=%v", list, err)
+	}
+}
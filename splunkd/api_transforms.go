@@ -0,0 +1,151 @@
+package splunkd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+// FieldTransformResource represents a field transformation defined within transforms.conf, to be referenced by REPORT- settings of props.conf
+// See: https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTknowledge#data.2Ftransforms.2Fextractions
+type FieldTransformResource struct {
+	Regex     string
+	SourceKey string
+	Format    string
+	// Delims and Fields are used by delimiter-based extractions, instead of Regex
+	Delims string
+	Fields string
+	// Type is either 'regex-based' or 'delim-based'
+	Type          string
+	MVAdd         bool
+	CleanKeys     bool
+	KeepEmptyVals bool
+	Disabled      bool
+}
+
+// UnmarshalJSON implements the JSON custom unmarshaller interface, as splunkd provides booleans either as strings or as JSON booleans
+func (ftr *FieldTransformResource) UnmarshalJSON(data []byte) error {
+	var tmp map[string]interface{}
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+	str := func(key string) string {
+		s, _ := tmp[key].(string)
+		return s
+	}
+	ftr.Regex = str("REGEX")
+	ftr.SourceKey = str("SOURCE_KEY")
+	ftr.Format = str("FORMAT")
+	ftr.Delims = str("DELIMS")
+	ftr.Fields = str("FIELDS")
+	ftr.Type = str("type")
+	ftr.MVAdd = interfaceToBool(tmp["MV_ADD"])
+	ftr.CleanKeys = interfaceToBool(tmp["CLEAN_KEYS"])
+	ftr.KeepEmptyVals = interfaceToBool(tmp["KEEP_EMPTY_VALS"])
+	ftr.Disabled = interfaceToBool(tmp["disabled"])
+	return nil
+}
+
+// FieldTransformsCollection manages the field transformations, as configured within transforms.conf
+type FieldTransformsCollection struct {
+	collection[FieldTransformResource]
+}
+
+func NewFieldTransformsCollection(ss *Client) *FieldTransformsCollection {
+	var col = &FieldTransformsCollection{}
+	col.name = "transforms-extractions"
+	col.path = "data/transforms/extractions"
+	col.splunkd = ss
+	return col
+}
+
+// Create defines a new field transformation called name within the namespace ns, or within the namespace of the client if ns is nil.
+// params must contain either REGEX or DELIMS, along with further settings such as FORMAT or SOURCE_KEY.
+func (col *FieldTransformsCollection) Create(ns *Namespace, name string, params *url.Values) (*entry[FieldTransformResource], error) {
+	if name == "" {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'name' cannot be empty")
+	}
+	if params == nil || (params.Get("REGEX") == "" && params.Get("DELIMS") == "") {
+		return nil, utils.NewErrInvalidParam(col.name+" create", nil, "'params' for '%s' must define either REGEX or DELIMS", name)
+	}
+	tmpParams := url.Values{}
+	for k, v := range *params {
+		tmpParams[k] = v
+	}
+	tmpParams.Set("name", name)
+	if ns == nil {
+		return col.collection.Create(name, &tmpParams)
+	}
+	return col.CreateNS(ns, name, &tmpParams)
+}
+
+// PropsTransformCollection manages the REPORT- settings of props.conf, which apply field transformations to sourcetypes
+type PropsTransformCollection struct {
+	ConfigsCollection
+}
+
+func NewPropsTransformCollection(ss *Client) *PropsTransformCollection {
+	var col = &PropsTransformCollection{}
+	col.name = "props-transforms"
+	col.path = "configs/conf-props"
+	col.splunkd = ss
+	return col
+}
+
+// AddReport sets 'REPORT-<name> = <transforms>' within the stanza of sourcetype, which must already exist.
+// The field transformations are applied in the provided order.
+func (col *PropsTransformCollection) AddReport(sourcetype, name string, transforms ...string) error {
+	if sourcetype == "" {
+		return utils.NewErrInvalidParam(col.name+" addReport", nil, "'sourcetype' cannot be empty")
+	}
+	if name == "" {
+		return utils.NewErrInvalidParam(col.name+" addReport", nil, "'name' cannot be empty")
+	}
+	if len(transforms) == 0 {
+		return utils.NewErrInvalidParam(col.name+" addReport", nil, "at least one transform must be provided")
+	}
+	params := url.Values{}
+	params.Set("REPORT-"+name, strings.Join(transforms, ", "))
+	if err := col.Update(sourcetype, &params); err != nil {
+		return fmt.Errorf("%s addReport: %w", col.name, err)
+	}
+	return nil
+}
+
+// RemoveReport empties the 'REPORT-<name>' setting within the stanza of sourcetype.
+// The REST API does not allow removing settings: an empty value disables the report.
+func (col *PropsTransformCollection) RemoveReport(sourcetype, name string) error {
+	if sourcetype == "" || name == "" {
+		return utils.NewErrInvalidParam(col.name+" removeReport", nil, "'sourcetype' and 'name' cannot be empty")
+	}
+	params := url.Values{}
+	params.Set("REPORT-"+name, "")
+	if err := col.Update(sourcetype, &params); err != nil {
+		return fmt.Errorf("%s removeReport: %w", col.name, err)
+	}
+	return nil
+}
+
+// GetReports returns the field transformations applied to sourcetype, keyed by the name of their REPORT- setting.
+// Empty REPORT- settings are not returned.
+func (col *PropsTransformCollection) GetReports(sourcetype string) (map[string][]string, error) {
+	stanza, err := col.GetStanza(sourcetype)
+	if err != nil {
+		return nil, fmt.Errorf("%s getReports: %w", col.name, err)
+	}
+	reports := make(map[string][]string)
+	for k, v := range stanza.properties() {
+		if !strings.HasPrefix(k, "REPORT-") || strings.TrimSpace(v) == "" {
+			continue
+		}
+		transforms := strings.Split(v, ",")
+		for i := range transforms {
+			transforms[i] = strings.TrimSpace(transforms[i])
+		}
+		reports[strings.TrimPrefix(k, "REPORT-")] = transforms
+	}
+	return reports, nil
+}
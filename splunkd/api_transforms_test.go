@@ -0,0 +1,144 @@
+package splunkd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeConfStore emulates endpoints of splunkd managing stanzas, keyed by path of the collection
+type fakeConfStore struct {
+	mu      sync.Mutex
+	stanzas map[string]map[string]map[string]interface{}
+}
+
+func (fs *fakeConfStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	// the SDK does not set a content-type, so the form must be parsed explicitly
+	body, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+
+	var colPath, name string
+	for p := range fs.stanzas {
+		if r.URL.Path == p {
+			colPath = p
+		} else if strings.HasPrefix(r.URL.Path, p+"/") {
+			colPath, name = p, strings.TrimPrefix(r.URL.Path, p+"/")
+		}
+	}
+	if colPath == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	stanzas := fs.stanzas[colPath]
+	if name == "" && r.Method == "POST" {
+		name = form.Get("name")
+		stanzas[name] = map[string]interface{}{}
+	} else if _, found := stanzas[name]; name != "" && !found {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"messages":[{"type":"ERROR","text":"Could not find object id=%s"}]}`, name)
+		return
+	}
+	switch r.Method {
+	case "POST":
+		for k := range form {
+			if k != "name" {
+				stanzas[name][k] = form.Get(k)
+			}
+		}
+	case "DELETE":
+		delete(stanzas, name)
+		fmt.Fprint(w, `{"entry":[]}`)
+		return
+	}
+	entries := make([]map[string]interface{}, 0)
+	for n, content := range stanzas {
+		if name == "" || n == name {
+			entries = append(entries, map[string]interface{}{"name": n, "content": content})
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"paging": map[string]int{"total": len(entries)}, "entry": entries})
+}
+
+func TestFieldTransforms(t *testing.T) {
+	fs := &fakeConfStore{stanzas: map[string]map[string]map[string]interface{}{
+		"/services/data/transforms/extractions":                {},
+		"/servicesNS/nobody/myapp/data/transforms/extractions": {},
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewFieldTransformsCollection(ss)
+
+	if _, err := col.Create(nil, "extract_user", &url.Values{"FORMAT": {"user::$1"}}); err == nil {
+		t.Error("Create accepted an extraction without REGEX nor DELIMS")
+	}
+	e, err := col.Create(nil, "extract_user", &url.Values{"REGEX": {`user=(\w+)`}, "FORMAT": {"user::$1"}, "MV_ADD": {"true"}})
+	if err != nil {
+		t.Fatalf("Create returned an error: %s", err.Error())
+	}
+	if e.Name != "extract_user" || e.Content.Regex != `user=(\w+)` || e.Content.Format != "user::$1" || !e.Content.MVAdd || e.Content.CleanKeys {
+		t.Errorf("Create returned a wrong entry: %+v", e)
+	}
+	ns, _ := NewNamespace("nobody", "myapp", SplunkSharingApp)
+	if _, err := col.Create(ns, "extract_kv", &url.Values{"DELIMS": {`" ", "="`}}); err != nil {
+		t.Fatalf("Create within a namespace returned an error: %s", err.Error())
+	}
+	if _, found := fs.stanzas["/servicesNS/nobody/myapp/data/transforms/extractions"]["extract_kv"]; !found {
+		t.Error("Create did not use the provided namespace")
+	}
+
+	list, err := col.List()
+	if err != nil || len(list) != 1 || list[0].Name != "extract_user" {
+		t.Fatalf("List did not return the created extraction. entries=%v err=%v", list, err)
+	}
+	if err := col.Update("extract_user", &url.Values{"SOURCE_KEY": {"_raw"}}); err != nil {
+		t.Errorf("Update returned an error: %s", err.Error())
+	}
+	if e, err := col.Get("extract_user"); err != nil || e.Content.SourceKey != "_raw" {
+		t.Errorf("Get returned wrong results. entry=%+v err=%v", e, err)
+	}
+	if err := col.Delete("extract_user"); err != nil {
+		t.Fatalf("Delete returned an error: %s", err.Error())
+	}
+	if list, err := col.List(); err != nil || len(list) != 0 {
+		t.Errorf("extraction was not deleted. entries=%v err=%v", list, err)
+	}
+}
+
+func TestPropsTransforms(t *testing.T) {
+	fs := &fakeConfStore{stanzas: map[string]map[string]map[string]interface{}{
+		"/services/configs/conf-props": {"myapp:events": {"SHOULD_LINEMERGE": "false", "REPORT-old": ""}},
+	}}
+	srv := httptest.NewServer(fs)
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := NewPropsTransformCollection(ss)
+
+	if err := col.AddReport("myapp:events", "fields"); err == nil {
+		t.Error("AddReport accepted a report without transforms")
+	}
+	if err := col.AddReport("missing", "fields", "extract_user"); err == nil {
+		t.Error("AddReport did not fail for a missing sourcetype")
+	}
+	if err := col.AddReport("myapp:events", "fields", "extract_user", "extract_kv"); err != nil {
+		t.Fatalf("AddReport returned an error: %s", err.Error())
+	}
+	reports, err := col.GetReports("myapp:events")
+	if err != nil || len(reports) != 1 || strings.Join(reports["fields"], "|") != "extract_user|extract_kv" {
+		t.Errorf("GetReports returned wrong results. reports=%v err=%v", reports, err)
+	}
+	if err := col.RemoveReport("myapp:events", "fields"); err != nil {
+		t.Fatalf("RemoveReport returned an error: %s", err.Error())
+	}
+	if reports, err := col.GetReports("myapp:events"); err != nil || len(reports) != 0 {
+		t.Errorf("report was not removed. reports=%v err=%v", reports, err)
+	}
+}
@@ -1,6 +1,9 @@
 package splunkd
 
 import (
+	"fmt"
+	"net/url"
+
 	"github.com/google/go-querystring/query"
 	"github.com/prigio/splunk-go-sdk/utils"
 )
@@ -28,6 +31,16 @@ type UserResource struct {
 	UserType            string   `json:"type" url:"-"`
 }
 
+// FullName returns the full name of the user, which splunkd calls 'realname'
+func (ur UserResource) FullName() string {
+	return ur.Realname
+}
+
+// Timezone returns the timezone of the user, which splunkd calls 'tz'
+func (ur UserResource) Timezone() string {
+	return ur.Tz
+}
+
 type UsersCollection struct {
 	collection[UserResource]
 }
@@ -50,3 +63,69 @@ func (col *UsersCollection) CreateUser(name string, details UserResource) (*entr
 	}
 	return col.Create(name, &urlValues)
 }
+
+// ResetPassword sets the password of username to newPassword, as an administrator would do.
+// Changing the password of the currently authenticated user requires providing the old one too: use Update with 'oldpassword' for this.
+func (col *UsersCollection) ResetPassword(username, newPassword string) error {
+	if newPassword == "" {
+		return utils.NewErrInvalidParam(col.name+" resetPassword", nil, "'newPassword' cannot be empty")
+	}
+	if err := col.Update(username, &url.Values{"password": {newPassword}}); err != nil {
+		return fmt.Errorf("%s resetPassword: %w", col.name, err)
+	}
+	return nil
+}
+
+// AddRole assigns role to username, keeping the roles the user already has. Nothing is done if the user already has the role.
+// Only the roles of the user are modified, its other settings are left untouched.
+func (col *UsersCollection) AddRole(username, role string) error {
+	if role == "" {
+		return utils.NewErrInvalidParam(col.name+" addRole", nil, "'role' cannot be empty")
+	}
+	u, err := col.Get(username)
+	if err != nil {
+		return fmt.Errorf("%s addRole: %w", col.name, err)
+	}
+	for _, r := range u.Content.Roles {
+		if r == role {
+			return nil
+		}
+	}
+	if err := col.setRoles(username, append(u.Content.Roles, role)); err != nil {
+		return fmt.Errorf("%s addRole: %w", col.name, err)
+	}
+	return nil
+}
+
+// RemoveRole removes role from the roles of username. Nothing is done if the user does not have the role.
+// Splunk requires users to have at least one role, therefore the last role of a user cannot be removed.
+func (col *UsersCollection) RemoveRole(username, role string) error {
+	if role == "" {
+		return utils.NewErrInvalidParam(col.name+" removeRole", nil, "'role' cannot be empty")
+	}
+	u, err := col.Get(username)
+	if err != nil {
+		return fmt.Errorf("%s removeRole: %w", col.name, err)
+	}
+	roles := make([]string, 0, len(u.Content.Roles))
+	for _, r := range u.Content.Roles {
+		if r != role {
+			roles = append(roles, r)
+		}
+	}
+	if len(roles) == len(u.Content.Roles) {
+		return nil
+	}
+	if len(roles) == 0 {
+		return utils.NewErrInvalidParam(col.name+" removeRole", nil, "cannot remove '%s', the only role of user '%s'", role, username)
+	}
+	if err := col.setRoles(username, roles); err != nil {
+		return fmt.Errorf("%s removeRole: %w", col.name, err)
+	}
+	return nil
+}
+
+// setRoles replaces the roles of username. splunkd only modifies the settings which are posted, so the other settings are kept.
+func (col *UsersCollection) setRoles(username string, roles []string) error {
+	return col.Update(username, &url.Values{"roles": roles})
+}
@@ -1,6 +1,14 @@
 package splunkd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-querystring/query"
@@ -144,3 +152,133 @@ func TestUsersDelete(t *testing.T) {
 		t.Errorf("user '%v' was not deleted. It is within the list of existing users '%v'", newUser, uNames)
 	}
 }
+
+// fakeAccessStore emulates the users and roles endpoints of splunkd, where some settings are multi-valued
+type fakeAccessStore struct {
+	mu       sync.Mutex
+	entries  map[string]map[string]url.Values
+	listKeys map[string]bool
+}
+
+func newFakeAccessStore() *fakeAccessStore {
+	return &fakeAccessStore{
+		entries: map[string]map[string]url.Values{
+			"/services/authentication/users": {},
+			"/services/authorization/roles":  {"user": {"capabilities": {"search"}}},
+		},
+		listKeys: map[string]bool{"roles": true, "capabilities": true, "imported_roles": true, "srchIndexesAllowed": true},
+	}
+}
+
+func (fs *fakeAccessStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(body))
+
+	var colPath, name string
+	for p := range fs.entries {
+		if r.URL.Path == p {
+			colPath = p
+		} else if strings.HasPrefix(r.URL.Path, p+"/") {
+			colPath, name = p, strings.TrimPrefix(r.URL.Path, p+"/")
+		}
+	}
+	if colPath == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	entries := fs.entries[colPath]
+	if name == "" && r.Method == "POST" {
+		name = form.Get("name")
+		entries[name] = url.Values{}
+	} else if _, found := entries[name]; name != "" && !found {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"messages":[{"type":"ERROR","text":"Could not find object id=%s"}]}`, name)
+		return
+	}
+	switch r.Method {
+	case "POST":
+		for k, v := range form {
+			if k != "name" {
+				entries[name][k] = v
+			}
+		}
+	case "DELETE":
+		delete(entries, name)
+		fmt.Fprint(w, `{"entry":[]}`)
+		return
+	}
+	out := make([]map[string]interface{}, 0)
+	for n, settings := range entries {
+		if name != "" && n != name {
+			continue
+		}
+		content := map[string]interface{}{}
+		for k, v := range settings {
+			if fs.listKeys[k] {
+				content[k] = v
+			} else {
+				content[k] = v[0]
+			}
+		}
+		out = append(out, map[string]interface{}{"name": n, "content": content})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"paging": map[string]int{"total": len(out)}, "entry": out})
+}
+
+func TestUsersRoles(t *testing.T) {
+	srv := httptest.NewServer(newFakeAccessStore())
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	users := ss.GetUsers()
+
+	if _, err := users.CreateUser("jdoe", UserResource{Password: "changeme1", Roles: []string{"user"}, Realname: "John Doe", Email: "jdoe@test.com", Tz: "Europe/Zurich"}); err != nil {
+		t.Fatalf("CreateUser returned an error: %s", err.Error())
+	}
+	defer users.Delete("jdoe")
+
+	if err := users.AddRole("jdoe", "power"); err != nil {
+		t.Fatalf("AddRole returned an error: %s", err.Error())
+	}
+	if err := users.AddRole("jdoe", "power"); err != nil {
+		t.Errorf("AddRole of an already assigned role returned an error: %s", err.Error())
+	}
+	u, err := users.Get("jdoe")
+	if err != nil {
+		t.Fatalf("Get returned an error: %s", err.Error())
+	}
+	if strings.Join(u.Content.Roles, ",") != "user,power" {
+		t.Errorf("AddRole did not add the role. roles=%v", u.Content.Roles)
+	}
+	if u.Content.FullName() != "John Doe" || u.Content.Email != "jdoe@test.com" || u.Content.Timezone() != "Europe/Zurich" {
+		t.Errorf("AddRole modified other settings of the user: %+v", u.Content)
+	}
+
+	if err := users.RemoveRole("jdoe", "user"); err != nil {
+		t.Fatalf("RemoveRole returned an error: %s", err.Error())
+	}
+	if u, err := users.Get("jdoe"); err != nil || strings.Join(u.Content.Roles, ",") != "power" {
+		t.Errorf("RemoveRole did not remove the role. entry=%+v err=%v", u, err)
+	}
+	if err := users.RemoveRole("jdoe", "power"); err == nil {
+		t.Error("RemoveRole accepted removing the last role of the user")
+	}
+	if err := users.AddRole("missing", "power"); err == nil {
+		t.Error("AddRole did not fail for a missing user")
+	}
+
+	if err := users.ResetPassword("jdoe", ""); err == nil {
+		t.Error("ResetPassword accepted an empty password")
+	}
+	if err := users.ResetPassword("jdoe", "changeme2"); err != nil {
+		t.Errorf("ResetPassword returned an error: %s", err.Error())
+	}
+
+	if err := users.Delete("jdoe"); err != nil {
+		t.Fatalf("Delete returned an error: %s", err.Error())
+	}
+	if users.Exists("jdoe") {
+		t.Error("user was not deleted")
+	}
+}
@@ -1,13 +1,20 @@
 package splunkd
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prigio/splunk-go-sdk/utils"
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -15,6 +22,8 @@ const (
 	defaultHost   = "localhost"
 	defaultPort   = 8089
 	httpTimeout   = 10 * time.Second
+	// default duration for which the results of Info() are cached
+	defaultInfoCacheTTL = 60 * time.Second
 )
 
 type Client struct {
@@ -23,17 +32,36 @@ type Client struct {
 	// used for token-based authentication
 	authToken string
 	// session key is provided by the login method, or when splunk executes a modular input/alert action
-	sessionKey  string
+	sessionKey string
+	// username provided to Login. Empty when the session was established with a token or session key
+	username string
+	// authMu protects sessionKey, authToken, username and authContext, which are replaced when authenticating again. See RefreshSession
+	authMu sync.RWMutex
+	// refreshMu serializes the executions of RefreshSession
+	refreshMu   sync.Mutex
 	nameSpace   Namespace
 	httpClient  *http.Client
 	credentials *CredentialsCollection
 	users       *UsersCollection
 	kvstore     *KVStoreCollCollection
-	// context of the current authenticated session. Provides info about the logged-in username, roles, etc
+	// context of the current authenticated session. Provides info about the logged-in username, roles, etc. Protected by authMu
 	authContext *ContextResource
 	//configs     map[string]*ConfigsCollection
 	// information about the splunk version, server where splunk is deployed, ...
 	info *InfoResource
+	// when info was retrieved and for how long it is considered valid
+	infoCachedAt time.Time
+	infoCacheTTL time.Duration
+	// collections caches the collections created through GetCollection, indexed by name
+	collections   map[string]interface{}
+	collectionsMu sync.RWMutex
+	// loggerWriter, if set, receives the output of the loggers created by NewLogger instead of splunkd
+	loggerWriter io.Writer
+	// ctx is used for all the API calls performed by the client. Cancelling it aborts in-flight requests.
+	ctx   context.Context
+	ctxMu sync.RWMutex
+	// retryPolicy configures how failed requests are retried. If nil, DefaultRetryPolicy is used
+	retryPolicy *RetryPolicy
 }
 
 func New(splunkdUrl string, insecureSkipVerify bool, proxy string) (*Client, error) {
@@ -59,12 +87,23 @@ func New(splunkdUrl string, insecureSkipVerify bool, proxy string) (*Client, err
 	}
 
 	ss := &Client{
-		nameSpace:  *ns,
-		baseUrl:    strings.TrimRight(splunkdUrl, "/"),
-		httpClient: httpClient,
+		nameSpace:    *ns,
+		baseUrl:      strings.TrimRight(splunkdUrl, "/"),
+		httpClient:   httpClient,
+		infoCacheTTL: defaultInfoCacheTTL,
 	}
 
 	if proxy != "" {
+		// SOCKS5 proxies are additionally checked by dialing the splunkd server through them
+		if proxyUrl, err := url.Parse(proxy); err == nil && utils.IsSOCKS5Proxy(*proxyUrl) {
+			splunkdUrl, err := url.Parse(ss.baseUrl)
+			if err != nil {
+				return nil, &utils.ErrInvalidParam{Context: "splunk service new", Msg: "splunkdUrl", Err: err}
+			}
+			if err := utils.IsReachableViaSOCKS5(*splunkdUrl, *proxyUrl); err != nil {
+				return nil, fmt.Errorf("splunk service new: unreachable splunkd URL '%s' via SOCKS5 proxy '%s'. %w", ss.baseUrl, proxyUrl.Redacted(), err)
+			}
+		}
 		// test whether the proxy can connect to the splunk server.
 		// this is done here, as we need the httpClient to have been prepared already
 		req, err := http.NewRequest(http.MethodHead, ss.baseUrl, nil)
@@ -92,6 +131,18 @@ func NewFromDefaults() (*Client, error) {
 func NewInteractive() (*Client, error) {
 	// first, need to get splunk endpoint, username and password to be able to login into the service if necessary.
 	uri := utils.AskForInput("Splunkd URL", "https://localhost:8089", false)
+	useToken := utils.AskForInput("Do you want to use a bearer token? (y/n)", "n", false)
+	if strings.HasPrefix(strings.ToLower(useToken), "y") {
+		token := utils.AskForInput("Splunk bearer token", "", true)
+		ss, err := New(uri, true, "")
+		if err != nil {
+			return nil, fmt.Errorf("connection failed to splunkd on '%s'. %w", uri, err)
+		}
+		if err = ss.LoginWithToken(token); err != nil {
+			return nil, fmt.Errorf("login failed to splunkd with bearer token: %w", err)
+		}
+		return ss, nil
+	}
 	username := utils.AskForInput("Splunk username", "admin", false)
 	password := utils.AskForInput("Splunk password", "", true)
 	ss, err := New(uri, true, "")
@@ -104,7 +155,182 @@ func NewInteractive() (*Client, error) {
 	return ss, nil
 }
 
+// WithHTTP2 enables or disables HTTP/2 for the connections to splunkd.
+// Enabling it also enables keep-alives, so that connections are reused: this significantly reduces the latency
+// of clients performing many API calls, such as KVStore operations.
+// HTTP/2 is only negotiated over HTTPS connections.
+func (ss *Client) WithHTTP2(enable bool) error {
+	if ss.httpClient == nil {
+		return fmt.Errorf("withHTTP2: no http client available")
+	}
+	t, ok := ss.httpClient.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("withHTTP2: unsupported http transport type %T", ss.httpClient.Transport)
+	}
+	t = t.Clone()
+	t.DisableKeepAlives = !enable
+	t.ForceAttemptHTTP2 = enable
+	if enable {
+		if _, configured := t.TLSNextProto["h2"]; !configured {
+			if err := http2.ConfigureTransport(t); err != nil {
+				return fmt.Errorf("withHTTP2: %w", err)
+			}
+		}
+	} else {
+		// a non-nil, empty map disables HTTP/2
+		t.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+		if t.TLSClientConfig != nil {
+			t.TLSClientConfig.NextProtos = nil
+		}
+	}
+	// copying the client retains its other settings, such as Jar and CheckRedirect
+	c := *ss.httpClient
+	c.Transport = t
+	ss.httpClient = &c
+	return nil
+}
+
+// DoRawRequest performs an authenticated request to an arbitrary splunkd endpoint, such as the endpoints not covered by the SDK.
+// path can be relative to the splunkd URL (e.g. "/services/datamodel/model") and can contain a query string.
+// Contrary to the other methods, 'output_mode=json' is not added automatically and the HTTP status is not checked:
+// the raw response is returned, and the caller is responsible for closing its body.
+func (ss *Client) DoRawRequest(method, path string, body io.Reader, contentType string) (*http.Response, error) {
+	if method == "" {
+		return nil, utils.NewErrInvalidParam("doRawRequest", nil, "'method' cannot be empty")
+	}
+	if path == "" {
+		return nil, utils.NewErrInvalidParam("doRawRequest", nil, "'path' cannot be empty")
+	}
+	if ss.httpClient == nil {
+		return nil, fmt.Errorf("doRawRequest: no http client available")
+	}
+	// the query string must not be escaped when joining the path to the splunkd URL
+	path, query, hasQuery := strings.Cut(path, "?")
+	fullUrl := ss.getFullUrl(path)
+	if hasQuery {
+		fullUrl = fullUrl + "?" + query
+	}
+	req, err := ss.newRequest(ss.getContext(), strings.ToUpper(method), fullUrl, body, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("doRawRequest: %w", err)
+	}
+	resp, err := ss.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("doRawRequest: %w", err)
+	}
+	return resp, nil
+}
+
+// DoRaw performs an authenticated request to an arbitrary splunkd endpoint, such as the endpoints not covered by the SDK,
+// and returns the HTTP status code along with the whole body of the response.
+// urlPath is joined to the splunkd URL unless it is an absolute URL starting with 'http', and can contain a query string, to which params are added.
+// Contrary to the other methods, 'output_mode=json' is not added automatically and the HTTP status is not checked:
+// err is only returned if the request could not be performed. Requests are retried according to the retry policy, see SetRetryPolicy.
+func (ss *Client) DoRaw(method, urlPath string, params *url.Values, body []byte, contentType string) (statusCode int, responseBody []byte, err error) {
+	if method == "" {
+		return 0, nil, utils.NewErrInvalidParam("doRaw", nil, "'method' cannot be empty")
+	}
+	if urlPath == "" {
+		return 0, nil, utils.NewErrInvalidParam("doRaw", nil, "'urlPath' cannot be empty")
+	}
+	resp, responseBody, err := ss.doRaw(ss.getContext(), strings.ToUpper(method), ss.getRawUrl(urlPath, params), body, contentType)
+	if err != nil {
+		return 0, nil, fmt.Errorf("doRaw: %w", err)
+	}
+	return resp.StatusCode, responseBody, nil
+}
+
+// getRawUrl returns the full URL of urlPath, which can contain a query string, adding params to it
+func (ss *Client) getRawUrl(urlPath string, params *url.Values) string {
+	// the query string must not be escaped when joining the path to the splunkd URL
+	urlPath, query, _ := strings.Cut(urlPath, "?")
+	if params != nil && len(*params) > 0 {
+		if query != "" {
+			query += "&"
+		}
+		query += params.Encode()
+	}
+	fullUrl := ss.getFullUrl(urlPath)
+	if query != "" {
+		fullUrl += "?" + query
+	}
+	return fullUrl
+}
+
+// DoRawJSON behaves as DoRaw, sending bodyStruct encoded as JSON and decoding the JSON response into resultStruct.
+// 'output_mode=json' is added to params. Either bodyStruct or resultStruct can be nil, to send no body or to ignore the response.
+// If splunkd replies with an HTTP status >= 400, a *SplunkAPIError is returned.
+func (ss *Client) DoRawJSON(method, urlPath string, params *url.Values, bodyStruct interface{}, resultStruct interface{}) error {
+	if method == "" {
+		return utils.NewErrInvalidParam("doRawJSON", nil, "'method' cannot be empty")
+	}
+	if urlPath == "" {
+		return utils.NewErrInvalidParam("doRawJSON", nil, "'urlPath' cannot be empty")
+	}
+	var body []byte
+	var contentType string
+	if bodyStruct != nil {
+		var err error
+		if body, err = json.Marshal(bodyStruct); err != nil {
+			return fmt.Errorf("doRawJSON: cannot encode body. %w", err)
+		}
+		contentType = "application/json"
+	}
+	jsonParams := url.Values{}
+	if params != nil {
+		for k, v := range *params {
+			jsonParams[k] = v
+		}
+	}
+	jsonParams.Set("output_mode", "json")
+	method = strings.ToUpper(method)
+	fullUrl := ss.getRawUrl(urlPath, &jsonParams)
+	resp, respBody, err := ss.doRaw(ss.getContext(), method, fullUrl, body, contentType)
+	if err != nil {
+		return fmt.Errorf("doRawJSON: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return newSplunkAPIError(method, fullUrl, resp, respBody)
+	}
+	if resultStruct != nil && len(bytes.TrimSpace(respBody)) > 0 {
+		if err = json.Unmarshal(respBody, resultStruct); err != nil {
+			return fmt.Errorf("doRawJSON: cannot decode response. %w", err)
+		}
+	}
+	return nil
+}
+
+// SetContext configures the context used by all the API calls performed by the client.
+// Cancelling ctx aborts in-flight requests, e.g. when the alert action or modular input is being shut down.
+// It is safe to call SetContext while API calls are being performed concurrently.
+func (ss *Client) SetContext(ctx context.Context) {
+	ss.ctxMu.Lock()
+	defer ss.ctxMu.Unlock()
+	ss.ctx = ctx
+}
+
+// GetContext returns the context configured with SetContext, or context.Background() if none was set.
+// This allows restoring it after having temporarily configured a more restrictive one.
+func (ss *Client) GetContext() context.Context {
+	return ss.getContext()
+}
+
+// getContext returns the context configured with SetContext, or context.Background() if none was set
+func (ss *Client) getContext() context.Context {
+	if ss == nil {
+		return context.Background()
+	}
+	ss.ctxMu.RLock()
+	defer ss.ctxMu.RUnlock()
+	if ss.ctx == nil {
+		return context.Background()
+	}
+	return ss.ctx
+}
+
 func (ss *Client) GetSessionKey() string {
+	ss.authMu.RLock()
+	defer ss.authMu.RUnlock()
 	return ss.sessionKey
 }
 
@@ -129,6 +355,42 @@ func (ss *Client) SetNamespaceFromNS(ns Namespace) {
 	ss.nameSpace = ns
 }
 
+// Clone returns a copy of the client with the same splunkd url, authentication, namespace, context and retry policy.
+// The clone shares the *http.Client, and thus the pool of connections, of the original client,
+// but the namespace, authentication and cached resources of both clients are independent:
+// collections retrieved from the clone operate within the namespace of the clone.
+// This allows working concurrently within multiple namespaces, which is not possible by calling SetNamespace on a shared client.
+func (ss *Client) Clone() *Client {
+	ss.authMu.RLock()
+	defer ss.authMu.RUnlock()
+	clone := &Client{
+		baseUrl:      ss.baseUrl,
+		authToken:    ss.authToken,
+		sessionKey:   ss.sessionKey,
+		username:     ss.username,
+		nameSpace:    ss.nameSpace,
+		httpClient:   ss.httpClient,
+		authContext:  ss.authContext,
+		infoCacheTTL: ss.infoCacheTTL,
+		loggerWriter: ss.loggerWriter,
+		ctx:          ss.getContext(),
+	}
+	if ss.retryPolicy != nil {
+		policy := *ss.retryPolicy
+		clone.retryPolicy = &policy
+	}
+	return clone
+}
+
+// CloneWithNamespace returns a clone of the client, as provided by Clone, operating within the specified namespace
+func (ss *Client) CloneWithNamespace(owner, app string, sharing SplunkSharing) (*Client, error) {
+	clone := ss.Clone()
+	if err := clone.SetNamespace(owner, app, sharing); err != nil {
+		return nil, fmt.Errorf("splunk service cloneWithNamespace: %w", err)
+	}
+	return clone, nil
+}
+
 func (ss *Client) GetCredentials() *CredentialsCollection {
 	if ss.credentials == nil {
 		ss.credentials = NewCredentialsCollection(ss)
@@ -143,6 +405,35 @@ func (ss *Client) GetUsers() *UsersCollection {
 	return ss.users
 }
 
+// GetSavedSearches returns the collection of the saved searches available within the namespace of the client.
+// Collections are cached by namespace: after SetNamespace, the collection of the new namespace is returned.
+func (ss *Client) GetSavedSearches() *SavedSearchesCollection {
+	ns := ss.nameSpace
+	return ss.GetCollection("saved-searches:"+ns.owner+"/"+ns.app+"/"+string(ns.sharing), func() interface{} {
+		return newSavedSearchesCollectionNS(ss, &ns)
+	}).(*SavedSearchesCollection)
+}
+
+// GetRoles returns the collection of the roles defined within splunkd
+func (ss *Client) GetRoles() *RolesCollection {
+	return ss.GetCollection("roles", func() interface{} { return NewRolesCollection(ss) }).(*RolesCollection)
+}
+
+// GetApps returns the collection of the apps installed on splunkd
+func (ss *Client) GetApps() *AppsCollection {
+	return ss.GetCollection("apps", func() interface{} { return NewAppsCollection(ss) }).(*AppsCollection)
+}
+
+// GetIndexes returns the collection of the indexes available on splunkd
+func (ss *Client) GetIndexes() *IndexesCollection {
+	return ss.GetCollection("indexes", func() interface{} { return NewIndexesCollection(ss) }).(*IndexesCollection)
+}
+
+// GetTokens returns the collection of the authentication tokens
+func (ss *Client) GetTokens() *TokensCollection {
+	return ss.GetCollection("tokens", func() interface{} { return NewTokensCollection(ss) }).(*TokensCollection)
+}
+
 func (ss *Client) GetKVStore() *KVStoreCollCollection {
 	if ss.kvstore == nil {
 		ss.kvstore = NewKVStoreCollCollection(ss)
@@ -150,9 +441,46 @@ func (ss *Client) GetKVStore() *KVStoreCollCollection {
 	return ss.kvstore
 }
 
-//func (ss *Client) GetConfigs(filename string) *ConfigsCollection {
-//	return NewConfigsCollection(ss, filename)
-//}
+// GetCollection returns the collection cached with the provided name, creating it with factory if not available yet.
+// This avoids creating multiple instances for the same collection, e.g.
+//
+//	props := ss.GetCollection("conf-props", func() interface{} { return NewConfigsCollection(ss, "props") }).(*ConfigsCollection)
+func (ss *Client) GetCollection(name string, factory func() interface{}) interface{} {
+	ss.collectionsMu.RLock()
+	col, ok := ss.collections[name]
+	ss.collectionsMu.RUnlock()
+	if ok {
+		return col
+	}
+
+	ss.collectionsMu.Lock()
+	defer ss.collectionsMu.Unlock()
+	// the collection might have been created meanwhile by another goroutine
+	if col, ok = ss.collections[name]; ok {
+		return col
+	}
+	if ss.collections == nil {
+		ss.collections = make(map[string]interface{})
+	}
+	col = factory()
+	ss.collections[name] = col
+	return col
+}
+
+// ClearCollectionCache removes all the collections cached by GetCollection
+func (ss *Client) ClearCollectionCache() {
+	ss.collectionsMu.Lock()
+	defer ss.collectionsMu.Unlock()
+	ss.collections = nil
+}
+
+// GetConfigs returns the cached collection managing configuration file filename
+func (ss *Client) GetConfigs(filename string) *ConfigsCollection {
+	return ss.GetCollection("conf-"+strings.TrimSuffix(strings.ToLower(filename), ".conf"), func() interface{} {
+		return NewConfigsCollection(ss, filename)
+	}).(*ConfigsCollection)
+}
+
 /*
 func (ss *Client) GetConfigsNS(filename, owner, app string) *ConfigsCollection {
 	if ss.configs == nil {
@@ -1,7 +1,15 @@
 package splunkd
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"sync"
 	"testing"
 )
 
@@ -144,3 +152,186 @@ func TestSetCredential(t *testing.T) {
 
 }
 */
+
+func TestGetCollectionCache(t *testing.T) {
+	c := &Client{}
+	props1 := c.GetConfigs("props.conf")
+	props2 := c.GetConfigs("props")
+	if props1 != props2 {
+		t.Errorf("GetConfigs did not return the cached collection")
+	}
+	c.ClearCollectionCache()
+	if props3 := c.GetConfigs("props"); props3 == props1 {
+		t.Errorf("ClearCollectionCache did not clear the cached collections")
+	}
+}
+
+func TestClone(t *testing.T) {
+	var mu sync.Mutex
+	paths := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+		fmt.Fprint(w, `{"paging":{"total":0},"entry":[]}`)
+	}))
+	defer srv.Close()
+	original := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc", retryPolicy: &RetryPolicy{MaxAttempts: 2}}
+	if err := original.SetNamespace("nobody", "search", SplunkSharingApp); err != nil {
+		t.Fatal(err)
+	}
+	originalSearches := original.GetSavedSearches()
+
+	if _, err := original.CloneWithNamespace("nobody", "myapp", "everyone"); err == nil {
+		t.Error("CloneWithNamespace accepted an invalid namespace")
+	}
+	clone, err := original.CloneWithNamespace("nobody", "myapp", SplunkSharingApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.httpClient != original.httpClient || clone.sessionKey != "abc" || clone.retryPolicy == original.retryPolicy || clone.retryPolicy.MaxAttempts != 2 {
+		t.Errorf("wrong clone: %+v", clone)
+	}
+	if original.nameSpace.app != "search" || clone.nameSpace.app != "myapp" {
+		t.Errorf("namespace of the original client was modified. original=%s clone=%s", original.nameSpace.app, clone.nameSpace.app)
+	}
+	if clone.GetSavedSearches() == originalSearches {
+		t.Error("clone shares the cached collections of the original client")
+	}
+
+	var wg sync.WaitGroup
+	for _, c := range []*Client{original, clone, original, clone} {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			if _, err := c.GetSavedSearches().List(); err != nil {
+				t.Errorf("concurrent request failed: %s", err.Error())
+			}
+		}(c)
+	}
+	wg.Wait()
+	if paths["/servicesNS/nobody/search/saved/searches"] != 2 || paths["/servicesNS/nobody/myapp/saved/searches"] != 2 {
+		t.Errorf("requests not performed within the namespace of each client: %v", paths)
+	}
+}
+
+func TestWithHTTP2(t *testing.T) {
+	var protoMajor int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protoMajor = r.ProtoMajor
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	jar, _ := cookiejar.New(nil)
+	ss.httpClient.Jar = jar
+	for _, enable := range []bool{false, true} {
+		if err := ss.WithHTTP2(enable); err != nil {
+			t.Fatal(err)
+		}
+		if ss.httpClient.Jar != jar {
+			t.Errorf("WithHTTP2(%v) did not retain the settings of the http client", enable)
+		}
+		if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", "/services/server/info", nil, nil, "", &discardBody{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := map[bool]int{false: 1, true: 2}[enable]; protoMajor != expected {
+			t.Errorf("WithHTTP2(%v) used a wrong protocol. expected=HTTP/%d got=HTTP/%d", enable, expected, protoMajor)
+		}
+	}
+}
+
+func TestDoRawRequest(t *testing.T) {
+	var gotAuth, gotQuery, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotQuery, gotPath = r.Header.Get("Authorization"), r.URL.RawQuery, r.URL.Path
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	resp, err := ss.DoRawRequest("get", "/services/datamodel/model?count=0", nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot {
+		t.Errorf("DoRawRequest did not return the raw response. status=%d", resp.StatusCode)
+	}
+	if gotAuth != "Splunk abc" || gotPath != "/services/datamodel/model" || gotQuery != "count=0" {
+		t.Errorf("DoRawRequest sent a wrong request. auth=%s path=%s query=%s", gotAuth, gotPath, gotQuery)
+	}
+}
+
+func TestDoRaw(t *testing.T) {
+	var gotAuth, gotMethod, gotPath, gotQuery, gotBody, gotContentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth, gotMethod, gotPath, gotQuery, gotBody, gotContentType = r.Header.Get("Authorization"), r.Method, r.URL.Path, r.URL.RawQuery, string(body), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "<raw/>")
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), authToken: "tkn"}
+	params := url.Values{"count": []string{"0"}}
+	status, body, err := ss.DoRaw("post", "/servicesNS/nobody/search/data/ui/views?search=a%20b", &params, []byte("name=view"), "application/x-www-form-urlencoded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusCreated || string(body) != "<raw/>" {
+		t.Errorf("DoRaw returned a wrong response. status=%d body=%s", status, body)
+	}
+	if gotAuth != "Bearer tkn" || gotMethod != "POST" || gotPath != "/servicesNS/nobody/search/data/ui/views" || gotQuery != "search=a%20b&count=0" ||
+		gotBody != "name=view" || gotContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("DoRaw sent a wrong request. auth=%s method=%s path=%s query=%s body=%s content-type=%s", gotAuth, gotMethod, gotPath, gotQuery, gotBody, gotContentType)
+	}
+
+	// absolute URLs are used as-is, and error statuses are not converted into errors
+	status, _, err = ss.DoRaw("GET", srv.URL+"/services/server/info", nil, nil, "")
+	if err != nil || status != http.StatusCreated || gotPath != "/services/server/info" || gotQuery != "" {
+		t.Errorf("DoRaw did not use the absolute URL. status=%d err=%v path=%s query=%s", status, err, gotPath, gotQuery)
+	}
+	if _, _, err = ss.DoRaw("", "/services", nil, nil, ""); err == nil {
+		t.Error("DoRaw accepted an empty method")
+	}
+}
+
+func TestDoRawJSON(t *testing.T) {
+	var gotQuery, gotBody, gotContentType string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotQuery, gotBody, gotContentType = r.URL.RawQuery, string(body), r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+		if status >= 400 {
+			fmt.Fprint(w, `{"messages":[{"type":"ERROR","text":"unknown collection"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"_key":"k1"}`)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	result := struct {
+		Key string `json:"_key"`
+	}{}
+	err := ss.DoRawJSON("POST", "/servicesNS/nobody/search/storage/collections/data/items", nil, map[string]string{"name": "n1"}, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Key != "k1" || gotBody != `{"name":"n1"}` || gotContentType != "application/json" || gotQuery != "output_mode=json" {
+		t.Errorf("DoRawJSON failed. result=%+v body=%s content-type=%s query=%s", result, gotBody, gotContentType, gotQuery)
+	}
+
+	status = http.StatusNotFound
+	var apiErr *SplunkAPIError
+	if err = ss.DoRawJSON("GET", "/services/missing", nil, nil, &result); !errors.As(err, &apiErr) || apiErr.MessagesText() != "unknown collection" {
+		t.Errorf("DoRawJSON did not return a SplunkAPIError: %v", err)
+	}
+	if gotContentType != "" || gotBody != "" {
+		t.Errorf("DoRawJSON sent a body without bodyStruct. body=%s content-type=%s", gotBody, gotContentType)
+	}
+}
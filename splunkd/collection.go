@@ -53,7 +53,7 @@ func (col *collection[T]) Create(entryName string, params *url.Values) (*entry[T
 		params.Set("name", entryName)
 	}
 	tmpCol := collection[T]{}
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &tmpCol); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &tmpCol); err != nil {
 		return nil, fmt.Errorf("%s create: %w", col.name, err)
 	}
 	return &tmpCol.Entries[0], nil
@@ -87,10 +87,12 @@ func (col *collection[T]) list(searchParams url.Values) ([]entry[T], error) {
 	// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTprolog#Pagination_and_filtering_parameters
 	searchParams.Set("count", "50")
 	searchParams.Set("offset", "0")
+	// entries of previous listings are replaced, not accumulated
+	col.Entries = nil
 	firstRound := true
 	for firstRound || tmpCol.Paging.Offset+len(tmpCol.Entries) < tmpCol.Paging.Total {
 		firstRound = false
-		if err := doSplunkdHttpRequest(col.splunkd, "GET", fullUrl, &searchParams, nil, "", &tmpCol); err != nil {
+		if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "GET", fullUrl, &searchParams, nil, "", &tmpCol); err != nil {
 			return nil, fmt.Errorf("%s list: %w", col.name, err)
 		}
 		if col.Entries == nil {
@@ -105,12 +107,52 @@ func (col *collection[T]) list(searchParams url.Values) ([]entry[T], error) {
 	return col.Entries, nil
 }
 
+// TotalCount returns the total number of entries of the collection, as reported by splunkd during the most recent List() or Search().
+// It returns 0 if no entries have been retrieved yet.
+func (col *collection[T]) TotalCount() int {
+	col.mu.RLock()
+	defer col.mu.RUnlock()
+	return col.Paging.Total
+}
+
+// forEach retrieves the entries of the collection page by page, invoking fn on each entry as soon as its page has been retrieved.
+// Contrary to list(), entries are not accumulated in memory. Iteration stops at the first error returned by fn.
+func (col *collection[T]) forEach(searchParams url.Values, fn func(*entry[T]) error) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("forEach: %w", err)
+	}
+	if fn == nil {
+		return utils.NewErrInvalidParam(col.name+" forEach", nil, "'fn' cannot be nil")
+	}
+	fullUrl := getUrl(col.path, "")
+
+	// https://docs.splunk.com/Documentation/Splunk/9.1.0/RESTREF/RESTprolog#Pagination_and_filtering_parameters
+	searchParams.Set("count", "50")
+	searchParams.Set("offset", "0")
+	for {
+		page := collection[T]{}
+		if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "GET", fullUrl, &searchParams, nil, "", &page); err != nil {
+			return fmt.Errorf("%s forEach: %w", col.name, err)
+		}
+		for i := range page.Entries {
+			if err := fn(&page.Entries[i]); err != nil {
+				return err
+			}
+		}
+		next := page.Paging.Offset + len(page.Entries)
+		if len(page.Entries) == 0 || next >= page.Paging.Total {
+			return nil
+		}
+		searchParams.Set("offset", fmt.Sprint(next))
+	}
+}
+
 func (col *collection[T]) Exists(entryName string) bool {
 	if err := col.isInitialized(); err != nil {
 		return false
 	}
 	fullUrl := getUrl(col.path, entryName)
-	if err := doSplunkdHttpRequest(col.splunkd, "GET", fullUrl, nil, nil, "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "GET", fullUrl, nil, nil, "", &discardBody{}); err != nil {
 		return false
 	}
 	return true
@@ -123,7 +165,7 @@ func (col *collection[T]) Get(entryName string) (*entry[T], error) {
 
 	fullUrl := getUrl(col.path, entryName)
 	tmpCol := collection[T]{}
-	if err := doSplunkdHttpRequest(col.splunkd, "GET", fullUrl, nil, nil, "", &tmpCol); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "GET", fullUrl, nil, nil, "", &tmpCol); err != nil {
 		return nil, fmt.Errorf("%s get '%s': %w", col.name, fullUrl, err)
 	}
 	return &tmpCol.Entries[0], nil
@@ -143,18 +185,10 @@ func (col *collection[T]) CreateNS(ns *Namespace, entryName string, params *url.
 		return nil, utils.NewErrInvalidParam(col.name+" createNS", nil, "namespace for '%s' cannot be nil", entryName)
 	}
 
-	var fullUrl string
-	if strings.HasPrefix(col.path, "/servicesNS/") {
-		//col.path is like  "/servicesNS/user/app/some/other/stuff"
-		//i want to have a result like: "" servicesNS, user, app, some/other/stuff
-		path := strings.SplitAfterN(col.path, "/", 5)[4]
-		fullUrl, _ = url.JoinPath(ns.GetServicesNSUrl(), path)
-	} else {
-		fullUrl, _ = url.JoinPath(ns.GetServicesNSUrl(), col.path)
-	}
+	fullUrl := getUrlNS(ns, col.path)
 	tmpCol := collection[T]{}
 
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &tmpCol); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &tmpCol); err != nil {
 		return nil, fmt.Errorf("%s createNS: %w", col.name, err)
 	}
 
@@ -174,12 +208,33 @@ func (col *collection[T]) Update(entryName string, params *url.Values) error {
 
 	fullUrl := getUrl(col.path, entryName)
 
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, []byte(params.Encode()), "", &discardBody{}); err != nil {
 		return fmt.Errorf("%s update: %w", col.name, err)
 	}
 	return nil
 }
 
+// PartialUpdate modifies only the settings of entryName specified within params, using HTTP PATCH.
+// Only use this with endpoints which support PATCH: for the others, splunkd returns an error.
+func (col *collection[T]) PartialUpdate(entryName string, params *url.Values) error {
+	if err := col.isInitialized(); err != nil {
+		return fmt.Errorf("partialUpdate: %w", err)
+	}
+	if entryName == "" {
+		return utils.NewErrInvalidParam(col.name+" partialUpdate", nil, "entryName cannot be empty")
+	}
+	if params == nil || len(*params) == 0 {
+		return utils.NewErrInvalidParam(col.name+" partialUpdate", nil, "params for '%s' cannot be empty", entryName)
+	}
+
+	fullUrl := getUrl(col.path, entryName)
+
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "PATCH", fullUrl, nil, []byte(params.Encode()), "application/x-www-form-urlencoded", &discardBody{}); err != nil {
+		return fmt.Errorf("%s partialUpdate: %w", col.name, err)
+	}
+	return nil
+}
+
 func (col *collection[T]) Delete(entryName string) error {
 	if err := col.isInitialized(); err != nil {
 		return fmt.Errorf("delete: %w", err)
@@ -189,7 +244,7 @@ func (col *collection[T]) Delete(entryName string) error {
 	}
 
 	fullUrl := getUrl(col.path, entryName)
-	if err := doSplunkdHttpRequest(col.splunkd, "DELETE", fullUrl, nil, nil, "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "DELETE", fullUrl, nil, nil, "", &discardBody{}); err != nil {
 		return fmt.Errorf("%s delete: %w", col.name, err)
 	}
 
@@ -200,7 +255,7 @@ func (col *collection[T]) DeleteEntry(e *entry[T]) error {
 	if e.Links.Remove == "" {
 		return fmt.Errorf("%T DeleteEntry: '%s' cannot be deleted", e, e.Name)
 	}
-	if err := doSplunkdHttpRequest(col.splunkd, "DELETE", e.Links.Remove, nil, nil, "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "DELETE", e.Links.Remove, nil, nil, "", &discardBody{}); err != nil {
 		return fmt.Errorf("%T DeleteEntry: '%s' cannot be deleted: %w", e, e.Name, err)
 	}
 	return nil
@@ -262,12 +317,30 @@ func (col *collection[T]) UpdateACL(entryName string, acl AccessControlList) err
 		aclParams.Set("perms.write", strings.Join((*aclParams)["perms.write"], ", "))
 	}
 
-	if err := doSplunkdHttpRequest(col.splunkd, "POST", fullUrl, nil, []byte(aclParams.Encode()), "", &discardBody{}); err != nil {
+	if err := doSplunkdHttpRequest(col.splunkd.getContext(), col.splunkd, "POST", fullUrl, nil, []byte(aclParams.Encode()), "", &discardBody{}); err != nil {
 		return fmt.Errorf("%s updateACL: %w", col.name, err)
 	}
 	return nil
 }
 
+// getUrlNS returns the path of the collection within namespace ns.
+// If collectionPath already has a /servicesNS/user/app/ prefix, that is replaced by the one of ns instead of being doubled.
+func getUrlNS(ns *Namespace, collectionPath string) string {
+	var fullUrl string
+	if strings.HasPrefix(collectionPath, "/servicesNS/") {
+		//collectionPath is like  "/servicesNS/user/app/some/other/stuff"
+		//i want to have a result like: "" servicesNS, user, app, some/other/stuff
+		path := ""
+		if parts := strings.SplitAfterN(collectionPath, "/", 5); len(parts) == 5 {
+			path = parts[4]
+		}
+		fullUrl, _ = url.JoinPath(ns.GetServicesNSUrl(), path)
+	} else {
+		fullUrl, _ = url.JoinPath(ns.GetServicesNSUrl(), collectionPath)
+	}
+	return fullUrl
+}
+
 func getUrl(collectionPath, entry string) string {
 	var fullUrl string
 
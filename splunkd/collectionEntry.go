@@ -1,6 +1,7 @@
 package splunkd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 )
@@ -15,6 +16,8 @@ type entry[T any] struct {
 		//    https://docs.splunk.com/Documentation/Splunk/9.0.5/RESTUM/RESTusing#Atom_Feed_response
 		List   string `json:"list"`
 		Remove string `json:"remove"`
+		// Reload is only provided by resources supporting the '_reload' endpoint, such as inputs
+		Reload string `json:"reload"`
 	} `json:"links"`
 	ACL     AccessControlList `json:"acl"`
 	Content T                 `json:"content"`
@@ -24,7 +27,7 @@ func (e *entry[T]) setSharing(ss *Client, sharing SplunkSharing) error {
 	fullUrl, _ := url.JoinPath(e.Id, "acl")
 	e.ACL.Sharing = string(sharing)
 	tmp := entry[T]{}
-	if err := doSplunkdHttpRequest(ss, "POST", fullUrl, e.ACL.ToURL(), nil, "", &tmp); err != nil {
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "POST", fullUrl, e.ACL.ToURL(), nil, "", &tmp); err != nil {
 		return fmt.Errorf("setSharing: cannot share '%s' to '%s'. %w", e.Name, sharing, err)
 	}
 	e.ACL = tmp.ACL
@@ -37,3 +40,32 @@ func (e *entry[T]) SetSharingGlobal(ss *Client) error {
 	}
 	return e.setSharing(ss, SplunkSharingGlobal)
 }
+
+// Reload makes splunkd re-read the configurations of the entry, applying changes without a full restart.
+// ErrOperationNotSupported is returned if the entry does not provide a 'reload' link.
+func (e *entry[T]) Reload(ss *Client) error {
+	if e.Links.Reload == "" {
+		return fmt.Errorf("reload: cannot reload '%s'. %w", e.Name, ErrOperationNotSupported)
+	}
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "POST", e.Links.Reload, nil, nil, "", &discardBody{}); err != nil {
+		return fmt.Errorf("reload: cannot reload '%s'. %w", e.Name, err)
+	}
+	return nil
+}
+
+// GetContent returns the typed content of the entry.
+// This is useful when the entry is only available through an interface, e.g. when retrieved from an interface{}-typed cache.
+func (e *entry[T]) GetContent() T {
+	return e.Content
+}
+
+// ToJSON marshals the whole entry, including its ACL, links and content, into JSON.
+// This is useful to produce debug output or to store the entry within a file-based cache.
+func (e *entry[T]) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// ToJSONIndent marshals the whole entry into indented, human-readable JSON.
+func (e *entry[T]) ToJSONIndent() ([]byte, error) {
+	return json.MarshalIndent(e, "", "  ")
+}
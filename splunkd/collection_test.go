@@ -1,6 +1,13 @@
 package splunkd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/prigio/splunk-go-sdk/utils"
@@ -39,3 +46,114 @@ func TestCollectionSearch(t *testing.T) {
 		t.Errorf("collection.List did not return all configurations for props: %v", propsNames)
 	}
 }
+
+func TestEntryToJSON(t *testing.T) {
+	e := entry[CredentialResource]{Name: "user", Author: "admin"}
+	e.Content.Username = "user"
+	e.Content.Realm = "realm"
+
+	j, err := e.ToJSON()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	back := entry[CredentialResource]{}
+	if err = json.Unmarshal(j, &back); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if back.Name != e.Name || back.Author != e.Author || back.Content.Realm != e.Content.Realm {
+		t.Errorf("entry.ToJSON did not produce a round-trippable result: %s", j)
+	}
+
+	if ji, err := e.ToJSONIndent(); err != nil {
+		t.Error(err)
+	} else if !strings.Contains(string(ji), "\n  ") {
+		t.Errorf("entry.ToJSONIndent did not produce indented JSON: %s", ji)
+	}
+}
+
+func TestEntryReloadNotSupported(t *testing.T) {
+	e := entry[ConfigResource]{Name: "noreload"}
+	if err := e.Reload(nil); !errors.Is(err, ErrOperationNotSupported) {
+		t.Errorf("entry.Reload did not return ErrOperationNotSupported for an entry without 'reload' link. got=%v", err)
+	}
+}
+
+func TestCollectionTotalCount(t *testing.T) {
+	ss := mustLoginToSplunk(t)
+	propsCol := NewConfigsCollection(ss, "props")
+	if propsCol.TotalCount() != 0 {
+		t.Errorf("collection.TotalCount did not return 0 before List(). got=%d", propsCol.TotalCount())
+	}
+	allProps, err := propsCol.List()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if propsCol.TotalCount() != len(allProps) {
+		t.Errorf("collection.TotalCount returned a wrong value. expected=%d got=%d", len(allProps), propsCol.TotalCount())
+	}
+}
+
+func TestCollectionListRepeated(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"paging":{"total":2,"offset":0},"entry":[{"name":"first"},{"name":"second"}]}`))
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client(), sessionKey: "abc"}
+	col := NewConfigsCollection(ss, "test")
+	for i := 1; i <= 3; i++ {
+		list, err := col.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 || col.TotalCount() != 2 {
+			t.Errorf("List invocation %d on the same collection returned duplicated entries. got=%d", i, len(list))
+		}
+	}
+}
+
+func TestCollectionPartialUpdate(t *testing.T) {
+	var gotMethod, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		r.ParseForm()
+		gotBody = r.PostForm.Encode()
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	col := collection[ConfigResource]{name: "test", path: "test", splunkd: ss}
+	params := url.Values{}
+	params.Set("key", "value")
+	if err := col.PartialUpdate("entry", &params); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if gotMethod != http.MethodPatch || gotBody != "key=value" {
+		t.Errorf("collection.PartialUpdate sent a wrong request. method=%s body=%s", gotMethod, gotBody)
+	}
+}
+
+func TestRequestContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	ss.SetContext(ctx)
+	if err := doSplunkdHttpRequest(ss.getContext(), ss, "GET", "/services/server/info", nil, nil, "", &discardBody{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("doSplunkdHttpRequest did not abort with a cancelled context. got=%v", err)
+	}
+}
+
+func TestEntryGetContent(t *testing.T) {
+	var cached interface{} = &entry[CredentialResource]{Name: "user", Content: CredentialResource{Username: "user"}}
+	// the content is accessible through an interface without asserting the type of Content itself
+	c := cached.(interface{ GetContent() CredentialResource }).GetContent()
+	if c.Username != "user" {
+		t.Errorf("entry.GetContent returned wrong content: %+v", c)
+	}
+}
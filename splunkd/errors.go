@@ -0,0 +1,77 @@
+package splunkd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrOperationNotSupported is returned when an operation is invoked on an entry which does not provide the necessary link
+var ErrOperationNotSupported = errors.New("operation not supported by this entry")
+
+// SplunkAPIMessage is one of the messages returned by splunkd within an error response
+type SplunkAPIMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SplunkAPIError is returned when splunkd replies with an HTTP status code >= 400.
+// If the response body has splunk's error format, such as
+//
+//	{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}
+//
+// the contained messages are available within Messages.
+type SplunkAPIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Messages   []SplunkAPIMessage
+	// Body is the raw body of the response
+	Body string
+}
+
+// newSplunkAPIError builds a SplunkAPIError, parsing the messages out of the body if possible
+func newSplunkAPIError(method, url string, resp *http.Response, body []byte) *SplunkAPIError {
+	apiErr := &SplunkAPIError{
+		Method:     method,
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       string(body),
+	}
+	tmp := struct {
+		Messages []SplunkAPIMessage `json:"messages"`
+	}{}
+	if err := json.Unmarshal(body, &tmp); err == nil {
+		apiErr.Messages = tmp.Messages
+	}
+	return apiErr
+}
+
+func (e *SplunkAPIError) Error() string {
+	return fmt.Sprintf("HTTP %s '%s':  %s %s - %s", e.Method, e.URL, e.Status, http.StatusText(e.StatusCode), e.Body)
+}
+
+// MessagesText returns the texts of all the messages returned by splunkd, joined by "; "
+func (e *SplunkAPIError) MessagesText() string {
+	texts := make([]string, len(e.Messages))
+	for i, m := range e.Messages {
+		texts[i] = m.Text
+	}
+	return strings.Join(texts, "; ")
+}
+
+// IsUnauthorized reports whether err has been caused by splunkd replying with HTTP 401, e.g. as the session key expired
+func IsUnauthorized(err error) bool {
+	var apiErr *SplunkAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// isNotFoundError reports whether err has been caused by splunkd replying with HTTP 404
+func isNotFoundError(err error) bool {
+	var apiErr *SplunkAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,30 @@
+package splunkd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSplunkAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`))
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	_, err := ss.Info()
+	apiErr := &SplunkAPIError{}
+	if !errors.As(err, &apiErr) {
+		t.Errorf("Info did not return a SplunkAPIError. got=%T %v", err, err)
+		t.FailNow()
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || len(apiErr.Messages) != 1 || apiErr.Messages[0].Type != "WARN" {
+		t.Errorf("SplunkAPIError has wrong contents: %+v", apiErr)
+	}
+	if apiErr.MessagesText() != "call not properly authenticated" {
+		t.Errorf("SplunkAPIError.MessagesText returned wrong value: '%s'", apiErr.MessagesText())
+	}
+}
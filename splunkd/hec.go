@@ -0,0 +1,363 @@
+package splunkd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prigio/splunk-go-sdk/utils"
+)
+
+const (
+	hecEventPath = "/services/collector/event"
+	hecAckPath   = "/services/collector/ack"
+	// default interval between two polls of the acknowledgement endpoint
+	defaultHECAckPollInterval = 1 * time.Second
+	// default maximum time waited for the acknowledgement of a batch of events
+	defaultHECAckTimeout = 60 * time.Second
+)
+
+// HECEvent is an event sent to splunk through the HTTP Event Collector.
+// See: https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector
+type HECEvent struct {
+	// Time of the event. If zero, splunk assigns the time of reception
+	Time       time.Time
+	Host       string
+	Source     string
+	Sourcetype string
+	Index      string
+	// Event is the payload of the event: a string or any value which can be marshalled to JSON
+	Event interface{}
+}
+
+// MarshalJSON generates the JSON format expected by the HTTP Event Collector, with the time expressed as epoch
+func (e *HECEvent) MarshalJSON() ([]byte, error) {
+	tmp := struct {
+		Time       string      `json:"time,omitempty"`
+		Host       string      `json:"host,omitempty"`
+		Source     string      `json:"source,omitempty"`
+		Sourcetype string      `json:"sourcetype,omitempty"`
+		Index      string      `json:"index,omitempty"`
+		Event      interface{} `json:"event"`
+	}{
+		Host:       e.Host,
+		Source:     e.Source,
+		Sourcetype: e.Sourcetype,
+		Index:      e.Index,
+		Event:      e.Event,
+	}
+	if !e.Time.IsZero() {
+		tmp.Time = strconv.FormatFloat(utils.GetEpoch(e.Time), 'f', 3, 64)
+	}
+	return json.Marshal(tmp)
+}
+
+// HECError is returned when the HTTP Event Collector refuses a request
+type HECError struct {
+	StatusCode int `json:"-"`
+	// Code and Text are the status code and message returned by the HTTP Event Collector
+	Code int    `json:"code"`
+	Text string `json:"text"`
+}
+
+func (e *HECError) Error() string {
+	return fmt.Sprintf("HEC error: HTTP %d code=%d - %s", e.StatusCode, e.Code, e.Text)
+}
+
+// AckConfig configures the usage of indexer acknowledgement.
+// When acknowledgement is enabled, the sending functions return only after splunk has confirmed the indexing of the events.
+// See: https://docs.splunk.com/Documentation/Splunk/latest/Data/AboutHECIDXAck
+type AckConfig struct {
+	// Channel identifier sent with every request. If empty, a random UUID is generated
+	Channel string
+	// PollInterval between two queries of the acknowledgement endpoint. Defaults to 1 second
+	PollInterval time.Duration
+	// Timeout after which the acknowledgement of a batch is considered failed. Defaults to 60 seconds
+	Timeout time.Duration
+}
+
+// HECClient sends events to splunk through the HTTP Event Collector.
+// The client can be used concurrently by multiple goroutines.
+type HECClient struct {
+	// url of the HTTP Event Collector. Generally https://localhost:8088
+	baseUrl    string
+	token      string
+	httpClient *http.Client
+	ctx        context.Context
+	// if not nil, indexer acknowledgement is used
+	ack *AckConfig
+
+	// batching configurations. Set with SetBatchConfig
+	mu           sync.Mutex
+	batch        []*HECEvent
+	batchMaxSize int
+	batchMaxAge  time.Duration
+	stopFlusher  chan struct{}
+	flusherDone  chan struct{}
+	// lastFlushErr stores the error of the last flush performed by the background flusher
+	lastFlushErr error
+}
+
+// NewHECClient returns a client sending events to the HTTP Event Collector available at hecURL, authenticating with token.
+func NewHECClient(hecURL, token string, insecureSkipVerify bool) (*HECClient, error) {
+	if hecURL == "" || (!strings.HasPrefix(hecURL, "https://") && !strings.HasPrefix(hecURL, "http://")) {
+		return nil, utils.NewErrInvalidParam("newHECClient", nil, "hecURL must have format http(s)://host:port")
+	}
+	if token == "" {
+		return nil, utils.NewErrInvalidParam("newHECClient", nil, "'token' cannot be empty")
+	}
+	httpClient, err := utils.NewHTTPClient(httpTimeout, insecureSkipVerify, "", "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("newHECClient: cannot create http client. %w", err)
+	}
+	return &HECClient{
+		baseUrl:    strings.TrimSuffix(hecURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}, nil
+}
+
+// SetContext configures the context used by Send, SendBatch and the background flusher.
+// Cancelling ctx aborts in-flight requests.
+func (c *HECClient) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// getContext returns the context set with SetContext, or context.Background()
+func (c *HECClient) getContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// SetAckConfig enables indexer acknowledgement. Pass nil to disable it.
+func (c *HECClient) SetAckConfig(cfg *AckConfig) error {
+	if cfg == nil {
+		c.ack = nil
+		return nil
+	}
+	if cfg.PollInterval < 0 || cfg.Timeout < 0 {
+		return utils.NewErrInvalidParam("hecClient.SetAckConfig", nil, "PollInterval and Timeout cannot be negative")
+	}
+	ack := *cfg
+	if ack.Channel == "" {
+		ack.Channel = uuid.New().String()
+	}
+	if ack.PollInterval == 0 {
+		ack.PollInterval = defaultHECAckPollInterval
+	}
+	if ack.Timeout == 0 {
+		ack.Timeout = defaultHECAckTimeout
+	}
+	c.ack = &ack
+	return nil
+}
+
+// SetBatchConfig enables automatic batching: events provided to Send are buffered and sent
+// as soon as maxSize events have been accumulated, and in any case every maxAge by a background goroutine.
+// Use 0 for maxAge to disable the background flusher. Close must be called to send the remaining events.
+func (c *HECClient) SetBatchConfig(maxSize int, maxAge time.Duration) error {
+	if maxSize < 1 {
+		return utils.NewErrInvalidParam("hecClient.SetBatchConfig", nil, "'maxSize' must be at least 1")
+	}
+	if maxAge < 0 {
+		return utils.NewErrInvalidParam("hecClient.SetBatchConfig", nil, "'maxAge' cannot be negative")
+	}
+	c.stopBackgroundFlusher()
+
+	c.mu.Lock()
+	c.batchMaxSize = maxSize
+	c.batchMaxAge = maxAge
+	c.mu.Unlock()
+
+	if maxAge > 0 {
+		c.stopFlusher = make(chan struct{})
+		c.flusherDone = make(chan struct{})
+		go c.runBackgroundFlusher(maxAge, c.stopFlusher, c.flusherDone)
+	}
+	return nil
+}
+
+// runBackgroundFlusher flushes the buffered events every interval, until stop is closed
+func (c *HECClient) runBackgroundFlusher(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-c.getContext().Done():
+			return
+		case <-ticker.C:
+			if err := c.Flush(c.getContext()); err != nil {
+				c.mu.Lock()
+				c.lastFlushErr = err
+				c.mu.Unlock()
+			}
+		}
+	}
+}
+
+// stopBackgroundFlusher stops the background flusher, if running, and waits for it to terminate
+func (c *HECClient) stopBackgroundFlusher() {
+	if c.stopFlusher != nil {
+		close(c.stopFlusher)
+		<-c.flusherDone
+		c.stopFlusher = nil
+		c.flusherDone = nil
+	}
+}
+
+// LastFlushError returns the error returned by the last failed flush of the background flusher, if any, and resets it
+func (c *HECClient) LastFlushError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.lastFlushErr
+	c.lastFlushErr = nil
+	return err
+}
+
+// Send sends a single event. If batching has been enabled with SetBatchConfig, the event is buffered instead.
+func (c *HECClient) Send(event *HECEvent) error {
+	if event == nil {
+		return utils.NewErrInvalidParam("hecClient.Send", nil, "'event' cannot be nil")
+	}
+	c.mu.Lock()
+	if c.batchMaxSize == 0 {
+		c.mu.Unlock()
+		return c.SendBatch([]*HECEvent{event})
+	}
+	c.batch = append(c.batch, event)
+	if len(c.batch) < c.batchMaxSize {
+		c.mu.Unlock()
+		return nil
+	}
+	events := c.batch
+	c.batch = nil
+	c.mu.Unlock()
+	return c.sendBatch(c.getContext(), events)
+}
+
+// SendBatch sends events as newline-delimited JSON within a single request
+func (c *HECClient) SendBatch(events []*HECEvent) error {
+	return c.sendBatch(c.getContext(), events)
+}
+
+// Flush sends all the events buffered because of SetBatchConfig
+func (c *HECClient) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	events := c.batch
+	c.batch = nil
+	c.mu.Unlock()
+	return c.sendBatch(ctx, events)
+}
+
+// Close stops the background flusher and sends the events still buffered
+func (c *HECClient) Close(ctx context.Context) error {
+	c.stopBackgroundFlusher()
+	return c.Flush(ctx)
+}
+
+// sendBatch posts events to the event endpoint and, if configured, waits for their acknowledgement
+func (c *HECClient) sendBatch(ctx context.Context, events []*HECEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for i, e := range events {
+		if e == nil {
+			return utils.NewErrInvalidParam("hecClient.SendBatch", nil, "event at position %d is nil", i)
+		}
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("hecClient.SendBatch: cannot encode event at position %d: %w", i, err)
+		}
+	}
+	resp := struct {
+		Text  string `json:"text"`
+		Code  int    `json:"code"`
+		AckID *int64 `json:"ackId"`
+	}{}
+	if err := c.doRequest(ctx, hecEventPath, &body, &resp); err != nil {
+		return fmt.Errorf("hecClient.SendBatch: %w", err)
+	}
+	if c.ack != nil {
+		if resp.AckID == nil {
+			return fmt.Errorf("hecClient.SendBatch: acknowledgement is enabled but no ackId was returned. Is indexer acknowledgement enabled for the token?")
+		}
+		if err := c.waitForAck(ctx, *resp.AckID); err != nil {
+			return fmt.Errorf("hecClient.SendBatch: %w", err)
+		}
+	}
+	return nil
+}
+
+// waitForAck polls the acknowledgement endpoint until ackID is confirmed, or the timeout of the AckConfig expires
+func (c *HECClient) waitForAck(ctx context.Context, ackID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.ack.Timeout)
+	defer cancel()
+	ticker := time.NewTicker(c.ack.PollInterval)
+	defer ticker.Stop()
+	for {
+		reqBody, _ := json.Marshal(map[string][]int64{"acks": {ackID}})
+		resp := struct {
+			Acks map[string]bool `json:"acks"`
+		}{}
+		if err := c.doRequest(ctx, hecAckPath, bytes.NewReader(reqBody), &resp); err != nil {
+			return fmt.Errorf("acknowledgement of ackId=%d: %w", ackID, err)
+		}
+		if resp.Acks[strconv.FormatInt(ackID, 10)] {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("acknowledgement of ackId=%d not received: %w", ackID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// doRequest POSTs body to urlPath and unmarshals the JSON response into out
+func (c *HECClient) doRequest(ctx context.Context, urlPath string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+urlPath, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	if c.ack != nil {
+		req.Header.Set("X-Splunk-Request-Channel", c.ack.Channel)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		hecErr := &HECError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(respBody, hecErr); err != nil {
+			hecErr.Text = string(respBody)
+		}
+		return hecErr
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("cannot parse response: %w", err)
+		}
+	}
+	return nil
+}
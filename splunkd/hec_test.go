@@ -0,0 +1,158 @@
+package splunkd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestHECServer returns a server emulating the HTTP Event Collector, recording the received events
+func newTestHECServer(t *testing.T, ackAfterPolls int) (*httptest.Server, func() []map[string]interface{}) {
+	var mu sync.Mutex
+	var received []map[string]interface{}
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Splunk testtoken" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"text":"Invalid token","code":4}`))
+			return
+		}
+		switch r.URL.Path {
+		case hecEventPath:
+			scanner := bufio.NewScanner(r.Body)
+			mu.Lock()
+			for scanner.Scan() {
+				ev := map[string]interface{}{}
+				if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+					t.Errorf("HEC server received an invalid line: %s", scanner.Text())
+				}
+				received = append(received, ev)
+			}
+			mu.Unlock()
+			w.Write([]byte(`{"text":"Success","code":0,"ackId":7}`))
+		case hecAckPath:
+			if r.Header.Get("X-Splunk-Request-Channel") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"text":"Data channel is missing","code":10}`))
+				return
+			}
+			mu.Lock()
+			polls++
+			done := polls >= ackAfterPolls
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]interface{}{"acks": map[string]bool{"7": done}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]interface{}{}, received...)
+	}
+}
+
+func TestHECClientSend(t *testing.T) {
+	srv, received := newTestHECServer(t, 1)
+	defer srv.Close()
+
+	if _, err := NewHECClient("localhost:8088", "testtoken", false); err == nil {
+		t.Error("NewHECClient accepted a URL without scheme")
+	}
+	c, err := NewHECClient(srv.URL, "testtoken", false)
+	if err != nil {
+		t.Fatalf("NewHECClient returned an error: %s", err.Error())
+	}
+
+	ts := time.Unix(1700000000, 500000000)
+	if err := c.Send(&HECEvent{Time: ts, Index: "main", Sourcetype: "test", Event: "hello"}); err != nil {
+		t.Fatalf("HECClient.Send returned an error: %s", err.Error())
+	}
+	if err := c.SendBatch([]*HECEvent{{Event: map[string]int{"a": 1}}, {Event: "second"}}); err != nil {
+		t.Fatalf("HECClient.SendBatch returned an error: %s", err.Error())
+	}
+	evs := received()
+	if len(evs) != 3 {
+		t.Fatalf("HEC server received the wrong number of events. expected=3 got=%d", len(evs))
+	}
+	if evs[0]["time"] != "1700000000.500" || evs[0]["index"] != "main" || evs[0]["event"] != "hello" {
+		t.Errorf("HEC server received a wrong event: %v", evs[0])
+	}
+	if _, ok := evs[1]["time"]; ok {
+		t.Errorf("HECEvent without Time was sent with a time attribute: %v", evs[1])
+	}
+
+	bad, _ := NewHECClient(srv.URL, "wrongtoken", false)
+	err = bad.Send(&HECEvent{Event: "x"})
+	var hecErr *HECError
+	if !errors.As(err, &hecErr) || hecErr.Code != 4 || hecErr.StatusCode != http.StatusForbidden {
+		t.Errorf("HECClient.Send did not return a HECError for an invalid token. got=%v", err)
+	}
+}
+
+func TestHECClientBatching(t *testing.T) {
+	srv, received := newTestHECServer(t, 1)
+	defer srv.Close()
+	c, _ := NewHECClient(srv.URL, "testtoken", false)
+
+	if err := c.SetBatchConfig(3, 0); err != nil {
+		t.Fatalf("HECClient.SetBatchConfig returned an error: %s", err.Error())
+	}
+	for i := 0; i < 4; i++ {
+		c.Send(&HECEvent{Event: i})
+	}
+	if n := len(received()); n != 3 {
+		t.Errorf("HECClient did not send the batch upon reaching maxSize. expected=3 got=%d", n)
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("HECClient.Close returned an error: %s", err.Error())
+	}
+	if n := len(received()); n != 4 {
+		t.Errorf("HECClient.Close did not flush the remaining events. expected=4 got=%d", n)
+	}
+
+	// background flusher
+	c.SetBatchConfig(100, 10*time.Millisecond)
+	c.Send(&HECEvent{Event: "background"})
+	time.Sleep(50 * time.Millisecond)
+	if n := len(received()); n != 5 {
+		t.Errorf("HECClient background flusher did not send the buffered event. expected=5 got=%d", n)
+	}
+	c.Close(context.Background())
+
+	// cancelled context on flush
+	c.SetBatchConfig(100, 0)
+	c.Send(&HECEvent{Event: "cancelled"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Flush(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("HECClient.Flush did not respect the cancelled context. got=%v", err)
+	}
+}
+
+func TestHECClientAck(t *testing.T) {
+	srv, _ := newTestHECServer(t, 2)
+	defer srv.Close()
+	c, _ := NewHECClient(srv.URL, "testtoken", false)
+
+	if err := c.SetAckConfig(&AckConfig{PollInterval: 5 * time.Millisecond, Timeout: time.Second}); err != nil {
+		t.Fatalf("HECClient.SetAckConfig returned an error: %s", err.Error())
+	}
+	if err := c.Send(&HECEvent{Event: "acked"}); err != nil {
+		t.Errorf("HECClient.Send with acknowledgement returned an error: %s", err.Error())
+	}
+
+	srvNoAck, _ := newTestHECServer(t, 1000)
+	defer srvNoAck.Close()
+	c, _ = NewHECClient(srvNoAck.URL, "testtoken", false)
+	c.SetAckConfig(&AckConfig{PollInterval: 5 * time.Millisecond, Timeout: 30 * time.Millisecond})
+	if err := c.Send(&HECEvent{Event: "never acked"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HECClient.Send did not time out waiting for the acknowledgement. got=%v", err)
+	}
+}
@@ -53,3 +53,24 @@ func (ns *Namespace) GetServicesNSUrl() string {
 	}
 	return "/servicesNS/" + o + "/" + a + "/"
 }
+
+// Clone returns a full copy of the namespace
+func (ns *Namespace) Clone() Namespace {
+	return *ns
+}
+
+// With returns a new namespace derived from ns, overriding the fields specified as parameters.
+// Empty values mean that the current value is kept. The sharing is not validated: use NewNamespace for this.
+func (ns *Namespace) With(owner, app string, sharing SplunkSharing) Namespace {
+	derived := ns.Clone()
+	if owner != "" {
+		derived.owner = owner
+	}
+	if app != "" {
+		derived.app = app
+	}
+	if sharing != "" {
+		derived.sharing = sharing
+	}
+	return derived
+}
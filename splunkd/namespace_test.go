@@ -0,0 +1,21 @@
+package splunkd
+
+import "testing"
+
+func TestNamespaceWith(t *testing.T) {
+	ns, _ := NewNamespace("admin", "search", SplunkSharingApp)
+	clone := ns.Clone()
+	if clone != *ns {
+		t.Errorf("Clone returned a different namespace: %+v", clone)
+	}
+	derived := ns.With("", "myapp", "")
+	if derived.GetServicesNSUrl() != "/servicesNS/admin/myapp/" || derived.sharing != SplunkSharingApp {
+		t.Errorf("With returned a wrong namespace: %+v", derived)
+	}
+	if ns.app != "search" {
+		t.Errorf("With modified the original namespace: %+v", ns)
+	}
+	if derived = ns.With("nobody", "", SplunkSharingGlobal); derived.owner != "nobody" || derived.app != "search" || derived.sharing != SplunkSharingGlobal {
+		t.Errorf("With returned a wrong namespace: %+v", derived)
+	}
+}
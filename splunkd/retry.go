@@ -0,0 +1,130 @@
+package splunkd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how the requests to splunkd are retried on transient failures,
+// such as throttling (HTTP 429) or a temporarily unavailable server (HTTP 503).
+// Retries use an exponential backoff: attempt n waits BaseDelay * 2^(n-1) plus a random jitter of up to BaseDelay, capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values <= 1 disable retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	// MaxDelay caps the waiting time between two attempts. If <= 0, the waiting time is not capped
+	MaxDelay time.Duration
+	// RetryOn reports whether a reply with statusCode must be retried. If nil, HTTP 429 and 503 are retried.
+	RetryOn func(statusCode int) bool
+	// Connection resets are only retried for the idempotent methods GET, HEAD, PUT and DELETE,
+	// as a POST request might have already been applied by splunkd when the connection got reset.
+	// RetryConnResetNonIdempotent also enables retrying them for the other methods.
+	RetryConnResetNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used by clients for which no policy has been set with SetRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	RetryOn:     isThrottlingStatus,
+}
+
+// isThrottlingStatus returns true for HTTP 429 and 503
+func isThrottlingStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// isIdempotentMethod returns true for the HTTP methods which can be safely repeated
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// SetRetryPolicy configures how the requests of the client are retried on transient failures.
+// Use a policy with MaxAttempts=1 to disable retries.
+func (ss *Client) SetRetryPolicy(p RetryPolicy) {
+	ss.retryPolicy = &p
+}
+
+// getRetryPolicy returns the policy configured with SetRetryPolicy, or DefaultRetryPolicy
+func (ss *Client) getRetryPolicy() RetryPolicy {
+	if ss.retryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+	return *ss.retryPolicy
+}
+
+// shouldRetry reports whether the request using method which received resp, or failed with err, must be attempted again
+func (p RetryPolicy) shouldRetry(attempt int, method string, resp *http.Response, err error) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) && (p.RetryConnResetNonIdempotent || isIdempotentMethod(method))
+	}
+	retryOn := p.RetryOn
+	if retryOn == nil {
+		retryOn = isThrottlingStatus
+	}
+	return retryOn(resp.StatusCode)
+}
+
+// delay returns how long to wait after the failed attempt number attempt (starting from 1).
+// The Retry-After header of a HTTP 429 reply is used when it does not exceed MaxDelay.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && (p.MaxDelay <= 0 || d <= p.MaxDelay) {
+			return d
+		}
+	}
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay<<(attempt-1) + time.Duration(rand.Int63n(int64(p.BaseDelay)))
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// parseRetryAfter parses the value of a Retry-After header, expressed either in seconds or as a HTTP date
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d, returning early with the error of ctx if it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package splunkd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 16)
+		n, _ := r.Body.Read(buf)
+		bodies = append(bodies, string(buf[:n]))
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"messages":[{"type":"ERROR","text":"server is busy"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":"ok"}`)
+	}))
+	defer srv.Close()
+
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+	ss.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond})
+	out := struct {
+		Value string `json:"value"`
+	}{}
+	if err := doSplunkdHttpRequest(nil, ss, "POST", "services/test", nil, []byte("a=b"), "", &out); err != nil {
+		t.Fatalf("request failed after retries: %s", err.Error())
+	}
+	if attempts != 3 || out.Value != "ok" {
+		t.Errorf("wrong result. attempts=%d value=%s", attempts, out.Value)
+	}
+	for i, b := range bodies {
+		if b != "a=b" {
+			t.Errorf("body of attempt %d was not resent. got='%s'", i+1, b)
+		}
+	}
+
+	// attempts are exhausted: the last error is returned
+	var apiErr *SplunkAPIError
+	atomic.StoreInt32(&attempts, 0)
+	ss.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+	if err := doSplunkdHttpRequest(nil, ss, "GET", "services/test", nil, nil, "", &out); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected a SplunkAPIError after exhausting attempts, got: %v", err)
+	} else if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryPolicyStatusCodes(t *testing.T) {
+	var attempts int32
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+	ss := &Client{baseUrl: srv.URL, httpClient: srv.Client()}
+
+	// not retried by the default policy
+	if err := doSplunkdHttpRequest(nil, ss, "GET", "services/test", nil, nil, "", &discardBody{}); err == nil || attempts != 1 {
+		t.Errorf("HTTP 404 must not be retried. attempts=%d err=%v", attempts, err)
+	}
+
+	// Retry-After is used instead of the much longer backoff
+	atomic.StoreInt32(&attempts, 0)
+	status = http.StatusTooManyRequests
+	ss.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour})
+	start := time.Now()
+	if err := doSplunkdHttpRequest(nil, ss, "GET", "services/test", nil, nil, "", &discardBody{}); err != nil || attempts != 2 {
+		t.Errorf("HTTP 429 was not retried. attempts=%d err=%v", attempts, err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("Retry-After header was not respected")
+	}
+
+	// custom RetryOn
+	atomic.StoreInt32(&attempts, 0)
+	status = http.StatusBadGateway
+	ss.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, RetryOn: func(code int) bool { return code == http.StatusBadGateway }})
+	if err := doSplunkdHttpRequest(nil, ss, "GET", "services/test", nil, nil, "", &discardBody{}); err != nil || attempts != 2 {
+		t.Errorf("HTTP 502 was not retried by custom policy. attempts=%d err=%v", attempts, err)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, min := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond} {
+		d := p.delay(attempt+1, nil)
+		if d < min || d > p.MaxDelay || (d >= min+p.BaseDelay && d != p.MaxDelay) {
+			t.Errorf("wrong delay for attempt %d. min=%s got=%s", attempt+1, min, d)
+		}
+	}
+	if d := p.delay(10, nil); d != p.MaxDelay {
+		t.Errorf("delay was not capped by MaxDelay. got=%s", d)
+	}
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"5"}}}
+	if d := p.delay(1, resp); d > p.MaxDelay {
+		t.Errorf("Retry-After greater than MaxDelay must be ignored. got=%s", d)
+	}
+	resp.Header.Set("Retry-After", "0")
+	if d := p.delay(1, resp); d != 0 {
+		t.Errorf("Retry-After was not respected. got=%s", d)
+	}
+}
+
+func TestRetryPolicyConnReset(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3}
+	reset := fmt.Errorf("read tcp: %w", syscall.ECONNRESET)
+	for method, expected := range map[string]bool{"GET": true, "HEAD": true, "PUT": true, "DELETE": true, "POST": false, "PATCH": false} {
+		if got := p.shouldRetry(1, method, nil, reset); got != expected {
+			t.Errorf("wrong retry decision for a connection reset of %s. expected=%t got=%t", method, expected, got)
+		}
+	}
+	if p.shouldRetry(1, "GET", nil, errors.New("connection refused")) {
+		t.Error("errors other than connection resets must not be retried")
+	}
+	p.RetryConnResetNonIdempotent = true
+	if !p.shouldRetry(1, "POST", nil, reset) {
+		t.Error("RetryConnResetNonIdempotent did not enable retrying a POST")
+	}
+	// status-based retries do not depend on the method
+	if !p.shouldRetry(1, "POST", &http.Response{StatusCode: http.StatusServiceUnavailable}, nil) {
+		t.Error("HTTP 503 of a POST request was not retried")
+	}
+}
@@ -2,6 +2,7 @@ package splunkd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -18,22 +19,21 @@ import (
 type discardBody struct{}
 
 // doSplunkdHttpRequest executes the specified request and returns http code, the body contents and possibly an error
-func doSplunkdHttpRequest[T any](ss *Client, method, urlPath string, urlParams *url.Values, body []byte, contentType string, parseJSONResultInto *T) (err error) {
+// The request is cancelled if ctx gets cancelled before it completes.
+func doSplunkdHttpRequest[T any](ctx context.Context, ss *Client, method, urlPath string, urlParams *url.Values, body []byte, contentType string, parseJSONResultInto *T) (err error) {
 	if ss == nil {
 		return utils.NewErrInvalidParam("doSplunkdHttpRequest", nil, "'splunkService' cannot be nil")
 	}
 	method = strings.ToUpper(method)
-	if method != "GET" && method != "POST" && method != "DELETE" && method != "PUT" && method != "HEAD" {
-		return utils.NewErrInvalidParam("doSplunkdHttpRequest", nil, "'method' must be one of GET, POST, DELETE, PUT, HEAD. provided:'%s'", method)
+	if method != "GET" && method != "POST" && method != "DELETE" && method != "PUT" && method != "PATCH" && method != "HEAD" {
+		return utils.NewErrInvalidParam("doSplunkdHttpRequest", nil, "'method' must be one of GET, POST, DELETE, PUT, PATCH, HEAD. provided:'%s'", method)
 	}
 	if urlPath == "" {
 		return utils.NewErrInvalidParam("doSplunkdHttpRequest", nil, "'urlPath' cannot be empty")
 	}
-
-	var fullUrl string
-	var req *http.Request
-	var resp *http.Response
-	var bodyReader *bytes.Reader
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	if !strings.HasPrefix(ss.baseUrl, "http") {
 		ss.baseUrl = "https://" + ss.baseUrl
@@ -44,56 +44,100 @@ func doSplunkdHttpRequest[T any](ss *Client, method, urlPath string, urlParams *
 	}
 	urlParams.Set("output_mode", "json")
 
-	fullUrl, _ = url.JoinPath(ss.baseUrl, urlPath)
-	// in some cases, the SDK sends absolute URLs to this function
-	if strings.HasPrefix(urlPath, "http") {
-		fullUrl = urlPath
-	}
-	fullUrl = fullUrl + "?" + urlParams.Encode()
-
-	// this also manages case where body is nil or has len=0
-	bodyReader = bytes.NewReader(body)
-
-	if req, err = http.NewRequest(method, fullUrl, bodyReader); err != nil {
-		return fmt.Errorf("doSplunkdHttpRequest: %w", err)
-	}
-	if contentType != "" {
-		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Type
-		req.Header.Set("content-type", contentType)
-	}
+	fullUrl := ss.getFullUrl(urlPath) + "?" + urlParams.Encode()
 
-	// type Header map[string][]string
-	// https://docs.splunk.com/Documentation/Splunk/8.1.3/Security/UseAuthTokens
-	if ss.sessionKey != "" {
-		req.Header.Set("Authorization", "Splunk "+ss.sessionKey)
-	} else if ss.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+ss.authToken)
-	}
-
-	//log.Printf("DEBUG [splunk service]: performing HTTP %s %s %s\n", req.Method, req.URL.Path, string(body))
-	if resp, err = ss.httpClient.Do(req); err != nil {
-		//log.Debug("splunk service: HTTP %s %s: %s", req.Method, req.URL.Path, err.Error())
+	resp, respBody, err := ss.doRaw(ctx, method, fullUrl, body, contentType)
+	if err != nil {
 		return err
 	}
 	if resp.StatusCode >= 400 {
 		// HTTP 401
 		// {"messages":[{"type":"WARN","text":"call not properly authenticated"}]}%
-		defer resp.Body.Close()
-		respBody, _ := io.ReadAll(resp.Body)
 		//log.Printf("DEBUG [splunk service]: reply %s %s", resp.Status, respBody)
-		return fmt.Errorf("HTTP %s '%s':  %s %s - %s", method, fullUrl, resp.Status, http.StatusText(resp.StatusCode), string(respBody))
+		return newSplunkAPIError(method, fullUrl, resp, respBody)
 	}
 	//log.Printf("DBODY: %T\n", parseJSONResultInto)
 	if parseJSONResultInto != nil && fmt.Sprintf("%T", parseJSONResultInto) != "*splunkd.discardBody" {
-		defer resp.Body.Close()
-		respBody, _ := io.ReadAll(resp.Body)
 		//log.Printf("DEBUG [splunk service]: reply %s %s", resp.Status, respBody)
 		return json.Unmarshal(respBody, parseJSONResultInto)
 	}
-
 	return nil
 }
 
+// doRaw performs an authenticated request to fullUrl, retrying it according to the retry policy of the client.
+// The returned response has already been read into respBody and closed. The HTTP status is not checked.
+func (ss *Client) doRaw(ctx context.Context, method, fullUrl string, body []byte, contentType string) (resp *http.Response, respBody []byte, err error) {
+	if ss.httpClient == nil {
+		return nil, nil, fmt.Errorf("no http client available")
+	}
+	policy := ss.getRetryPolicy()
+	for attempt := 1; ; attempt++ {
+		// this also manages case where body is nil or has len=0
+		// a new reader is needed for each attempt, as the previous one has been consumed
+		var req *http.Request
+		if req, err = ss.newRequest(ctx, method, fullUrl, bytes.NewReader(body), contentType); err != nil {
+			return nil, nil, err
+		}
+
+		//log.Printf("DEBUG [splunk service]: performing HTTP %s %s %s\n", req.Method, req.URL.Path, string(body))
+		resp, err = ss.httpClient.Do(req)
+		if !policy.shouldRetry(attempt, method, resp, err) {
+			break
+		}
+		wait := policy.delay(attempt, resp)
+		if resp != nil {
+			// the body must be drained to be able to reuse the connection
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err = sleepContext(ctx, wait); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err != nil {
+		//log.Debug("splunk service: HTTP %s %s: %s", req.Method, req.URL.Path, err.Error())
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if respBody, err = io.ReadAll(resp.Body); err != nil {
+		return resp, nil, err
+	}
+	return resp, respBody, nil
+}
+
+// getFullUrl returns the URL of urlPath on splunkd. Absolute URLs are returned as-is.
+func (ss *Client) getFullUrl(urlPath string) string {
+	// in some cases, the SDK sends absolute URLs
+	if strings.HasPrefix(urlPath, "http") {
+		return urlPath
+	}
+	baseUrl := ss.baseUrl
+	if !strings.HasPrefix(baseUrl, "http") {
+		baseUrl = "https://" + baseUrl
+	}
+	fullUrl, _ := url.JoinPath(baseUrl, urlPath)
+	return fullUrl
+}
+
+// newRequest prepares a HTTP request to splunkd, authenticated with the session key or the auth token of the client
+func (ss *Client) newRequest(ctx context.Context, method, fullUrl string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, fullUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Content-Type
+		req.Header.Set("content-type", contentType)
+	}
+
+	// type Header map[string][]string
+	// https://docs.splunk.com/Documentation/Splunk/8.1.3/Security/UseAuthTokens
+	if auth := ss.authorizationHeader(); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return req, nil
+}
+
 func interfaceToBool(v interface{}) bool {
 	switch val := v.(type) {
 	case bool:
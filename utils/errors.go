@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 )
 
 type ErrInvalidParam struct {
@@ -30,3 +31,38 @@ func NewErrInvalidParam(context string, err error, msg string, a ...interface{})
 		Msg:     fmt.Sprintf(msg, a...),
 	}
 }
+
+// MultiError collects several errors which happened while performing a batch of independent operations.
+// errors.Is and errors.As inspect all the collected errors.
+type MultiError struct {
+	Context string  // function where errors happened
+	Errors  []error // collected errors
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%s: %d errors: %s", e.Context, len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns all the collected errors. See https://pkg.go.dev/errors#pkg-overview
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Add appends err to the collected errors, if it is not nil
+func (e *MultiError) Add(err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, err)
+	}
+}
+
+// ErrorOrNil returns nil if no errors have been collected, e itself otherwise.
+func (e *MultiError) ErrorOrNil() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -5,14 +5,17 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"syscall"
 	"time"
 
+	netproxy "golang.org/x/net/proxy"
 	"golang.org/x/term"
 )
 
@@ -70,13 +73,67 @@ func NewHTTPTransport(timeout time.Duration, retries int, backoff time.Duration,
 		if err != nil {
 			return nil, fmt.Errorf("unreachable proxy URL '%s'. %w", proxy, err)
 		}
-		httpTransport.Proxy = http.ProxyURL(proxyUrl)
+		if IsSOCKS5Proxy(*proxyUrl) {
+			// SOCKS5 proxies are not managed by http.Transport.Proxy: connections must be dialed through the proxy itself
+			dialer, err := netproxy.FromURL(proxyUrl, &net.Dialer{Timeout: timeout})
+			if err != nil {
+				return nil, fmt.Errorf("invalid SOCKS5 proxy URL '%s'. %w", proxyUrl.Redacted(), err)
+			}
+			if ctxDialer, ok := dialer.(netproxy.ContextDialer); ok {
+				httpTransport.DialContext = ctxDialer.DialContext
+			} else {
+				httpTransport.Dial = dialer.Dial
+			}
+		} else {
+			httpTransport.Proxy = http.ProxyURL(proxyUrl)
+		}
 	}
 	return httpTransport, nil
 }
 
+// IsSOCKS5Proxy returns true if the proxy URL uses the socks5:// or socks5h:// scheme
+func IsSOCKS5Proxy(proxyUrl url.URL) bool {
+	scheme := strings.ToLower(proxyUrl.Scheme)
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// IsReachableViaSOCKS5 tries to connect to the target URL through the provided SOCKS5 proxy and returns an error if this is not possible
+func IsReachableViaSOCKS5(target url.URL, proxyUrl url.URL) error {
+	if !IsSOCKS5Proxy(proxyUrl) {
+		return fmt.Errorf("isReachableViaSOCKS5: not a SOCKS5 proxy URL. URL=\"%s\"", proxyUrl.Redacted())
+	}
+	port, err := getDefaultPort(target)
+	if err != nil {
+		return err
+	}
+	dialer, err := netproxy.FromURL(&proxyUrl, &net.Dialer{Timeout: 500 * time.Millisecond})
+	if err != nil {
+		return fmt.Errorf("isReachableViaSOCKS5: %w", err)
+	}
+	if conn, err := dialer.Dial("tcp", target.Hostname()+":"+port); err != nil {
+		return err
+	} else {
+		conn.Close()
+		return nil
+	}
+}
+
 // IsReachable tries to connect to the target URL and returns an error if this is not possible
 func IsReachable(target url.URL) error {
+	port, err := getDefaultPort(target)
+	if err != nil {
+		return err
+	}
+	if conn, err := net.DialTimeout("tcp", target.Hostname()+":"+port, 500*time.Millisecond); err != nil {
+		return err
+	} else {
+		conn.Close()
+		return nil
+	}
+}
+
+// getDefaultPort returns the port of the target URL, or the default port for the URL scheme if none is specified
+func getDefaultPort(target url.URL) (string, error) {
 	var port = target.Port()
 	if target.Port() == "" {
 		switch strings.ToLower(target.Scheme) {
@@ -102,20 +159,15 @@ func IsReachable(target url.URL) error {
 			port = "995"
 		case "sftp":
 			port = "115"
-		case "sockes":
+		case "sockes", "socks5", "socks5h":
 			port = "1080"
 		case "imaps":
 			port = "993"
 		default:
-			return fmt.Errorf("isReachable: invalid URL - cannot determine correct port. URL=\"%s\"", target.String())
+			return "", fmt.Errorf("isReachable: invalid URL - cannot determine correct port. URL=\"%s\"", target.String())
 		}
 	}
-	if conn, err := net.DialTimeout("tcp", target.Hostname()+":"+port, 500*time.Millisecond); err != nil {
-		return err
-	} else {
-		conn.Close()
-		return nil
-	}
+	return port, nil
 }
 
 /*
@@ -150,6 +202,30 @@ func AskForInput(prompt string, defaultVal string, isPassword bool) string {
 	}
 }
 
+// maxAskForInputAttempts is the number of times AskForInputWithValidation prompts the user before giving up
+const maxAskForInputAttempts = 5
+
+/*
+	 AskForInputWithValidation promts the user to provide a value via StdIn, as AskForInput does.
+		The provided value is checked using validate: if this returns an error, the error is shown and the user is prompted again.
+		After 5 failed attempts, the last validation error is returned.
+		If validate is nil, this behaves as AskForInput.
+*/
+func AskForInputWithValidation(prompt string, defaultVal string, isPassword bool, validate func(string) error) (string, error) {
+	var err error
+	for i := 0; i < maxAskForInputAttempts; i++ {
+		val := AskForInput(prompt, defaultVal, isPassword)
+		if validate == nil {
+			return val, nil
+		}
+		if err = validate(val); err == nil {
+			return val, nil
+		}
+		fmt.Printf("Invalid value: %s\n", err.Error())
+	}
+	return "", fmt.Errorf("askForInput: no valid value provided for '%s' after %d attempts. %w", strings.Trim(prompt, ": "), maxAskForInputAttempts, err)
+}
+
 // GetEpochNow returns an the current time as Epoch, expressed in seconds with a decimal part
 func GetEpochNow() float64 {
 	return float64(time.Now().UnixNano()) / 1000000000.0
@@ -160,6 +236,12 @@ func GetEpoch(t time.Time) float64 {
 	return float64(t.UnixNano()) / 1000000000.0
 }
 
+// GetTimeFromEpoch is the reverse of GetEpoch: it returns the time represented by Epoch timestamp epoch, expressed in seconds with a decimal part.
+// The result is rounded to millisecond precision.
+func GetTimeFromEpoch(epoch float64) time.Time {
+	return time.UnixMilli(int64(math.Round(epoch * 1000.0)))
+}
+
 // In checks presence of 'elemet' within slice 'set'
 func In[T comparable](element T, set []T) bool {
 	for _, value := range set {
@@ -208,6 +290,30 @@ func ListOfVals[T any, PT any](source []T, accessor func(*T) PT) []PT {
 	return res
 }
 
+// GenerateTransformsConf returns a stanza of splunk's transforms.conf named 'extract_<name>', extracting the field name from key="value" pairs found within the events.
+// The name of the extracted field is name with the characters which are invalid within PCRE group names, such as '-' and '.', replaced by '_'.
+func GenerateTransformsConf(name, title, description string) string {
+	return fmt.Sprintf(`# %s: %s
+[extract_%s]
+REGEX = (?:^|\s)%s="(?<%s>(?:[^"\\]|\\.)*)"
+`, title, strings.ReplaceAll(description, "\n", " "), name, regexp.QuoteMeta(name), regexGroupName(name))
+}
+
+// regexGroupName returns name with the characters which are invalid within PCRE group names replaced by '_'.
+// Group names can only contain letters, digits and underscores, and cannot start with a digit.
+func regexGroupName(name string) string {
+	group := strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	if group == "" || (group[0] >= '0' && group[0] <= '9') {
+		group = "_" + group
+	}
+	return group
+}
+
 /*
 func InteractivelyConfigureGlobalParams(params []alertactions.Param) error {
 	if len(params) == 0 {